@@ -0,0 +1,365 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.9
+// source: api/i18n/v1/i18n.proto
+
+package i18nv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// I18NServiceClient is the client API for I18NService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type I18NServiceClient interface {
+	// GetMessage return the value of a language and scopes, or NOT_FOUND.
+	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error)
+	// BatchGetMessages return the values of many languages and scopes, the missing values are returned in 'missing'.
+	BatchGetMessages(ctx context.Context, in *BatchGetMessagesRequest, opts ...grpc.CallOption) (*BatchGetMessagesResponse, error)
+	// UpsertMessage create or update a value.
+	UpsertMessage(ctx context.Context, in *UpsertMessageRequest, opts ...grpc.CallOption) (*UpsertMessageResponse, error)
+	// DeleteMessage delete a value, or NOT_FOUND.
+	DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error)
+	// ListLanguages return all languages present in the catalog.
+	ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error)
+	// ExportBundle return all values of a language under the scopes in one JSON document, like the REST bundle api.
+	ExportBundle(ctx context.Context, in *ExportBundleRequest, opts ...grpc.CallOption) (*ExportBundleResponse, error)
+	// Watch stream the changes of the catalog. The changes after 'since' are sent first, then a READY event. If they are
+	// not kept anymore, a RESET event is sent instead of them, the client should reload the catalog.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (I18NService_WatchClient, error)
+}
+
+type i18NServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewI18NServiceClient(cc grpc.ClientConnInterface) I18NServiceClient {
+	return &i18NServiceClient{cc}
+}
+
+func (c *i18NServiceClient) GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/GetMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) BatchGetMessages(ctx context.Context, in *BatchGetMessagesRequest, opts ...grpc.CallOption) (*BatchGetMessagesResponse, error) {
+	out := new(BatchGetMessagesResponse)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/BatchGetMessages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) UpsertMessage(ctx context.Context, in *UpsertMessageRequest, opts ...grpc.CallOption) (*UpsertMessageResponse, error) {
+	out := new(UpsertMessageResponse)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/UpsertMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) DeleteMessage(ctx context.Context, in *DeleteMessageRequest, opts ...grpc.CallOption) (*DeleteMessageResponse, error) {
+	out := new(DeleteMessageResponse)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/DeleteMessage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) ListLanguages(ctx context.Context, in *ListLanguagesRequest, opts ...grpc.CallOption) (*ListLanguagesResponse, error) {
+	out := new(ListLanguagesResponse)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/ListLanguages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) ExportBundle(ctx context.Context, in *ExportBundleRequest, opts ...grpc.CallOption) (*ExportBundleResponse, error) {
+	out := new(ExportBundleResponse)
+	err := c.cc.Invoke(ctx, "/i18n.v1.I18nService/ExportBundle", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *i18NServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (I18NService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &I18NService_ServiceDesc.Streams[0], "/i18n.v1.I18nService/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &i18NServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type I18NService_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type i18NServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *i18NServiceWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// I18NServiceServer is the server API for I18NService service.
+// All implementations must embed UnimplementedI18NServiceServer
+// for forward compatibility
+type I18NServiceServer interface {
+	// GetMessage return the value of a language and scopes, or NOT_FOUND.
+	GetMessage(context.Context, *GetMessageRequest) (*Message, error)
+	// BatchGetMessages return the values of many languages and scopes, the missing values are returned in 'missing'.
+	BatchGetMessages(context.Context, *BatchGetMessagesRequest) (*BatchGetMessagesResponse, error)
+	// UpsertMessage create or update a value.
+	UpsertMessage(context.Context, *UpsertMessageRequest) (*UpsertMessageResponse, error)
+	// DeleteMessage delete a value, or NOT_FOUND.
+	DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error)
+	// ListLanguages return all languages present in the catalog.
+	ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error)
+	// ExportBundle return all values of a language under the scopes in one JSON document, like the REST bundle api.
+	ExportBundle(context.Context, *ExportBundleRequest) (*ExportBundleResponse, error)
+	// Watch stream the changes of the catalog. The changes after 'since' are sent first, then a READY event. If they are
+	// not kept anymore, a RESET event is sent instead of them, the client should reload the catalog.
+	Watch(*WatchRequest, I18NService_WatchServer) error
+	mustEmbedUnimplementedI18NServiceServer()
+}
+
+// UnimplementedI18NServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedI18NServiceServer struct {
+}
+
+func (UnimplementedI18NServiceServer) GetMessage(context.Context, *GetMessageRequest) (*Message, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessage not implemented")
+}
+func (UnimplementedI18NServiceServer) BatchGetMessages(context.Context, *BatchGetMessagesRequest) (*BatchGetMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetMessages not implemented")
+}
+func (UnimplementedI18NServiceServer) UpsertMessage(context.Context, *UpsertMessageRequest) (*UpsertMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertMessage not implemented")
+}
+func (UnimplementedI18NServiceServer) DeleteMessage(context.Context, *DeleteMessageRequest) (*DeleteMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessage not implemented")
+}
+func (UnimplementedI18NServiceServer) ListLanguages(context.Context, *ListLanguagesRequest) (*ListLanguagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLanguages not implemented")
+}
+func (UnimplementedI18NServiceServer) ExportBundle(context.Context, *ExportBundleRequest) (*ExportBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportBundle not implemented")
+}
+func (UnimplementedI18NServiceServer) Watch(*WatchRequest, I18NService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedI18NServiceServer) mustEmbedUnimplementedI18NServiceServer() {}
+
+// UnsafeI18NServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to I18NServiceServer will
+// result in compilation errors.
+type UnsafeI18NServiceServer interface {
+	mustEmbedUnimplementedI18NServiceServer()
+}
+
+func RegisterI18NServiceServer(s grpc.ServiceRegistrar, srv I18NServiceServer) {
+	s.RegisterService(&I18NService_ServiceDesc, srv)
+}
+
+func _I18NService_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).GetMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/GetMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).GetMessage(ctx, req.(*GetMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_BatchGetMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).BatchGetMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/BatchGetMessages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).BatchGetMessages(ctx, req.(*BatchGetMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_UpsertMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).UpsertMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/UpsertMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).UpsertMessage(ctx, req.(*UpsertMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_DeleteMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).DeleteMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/DeleteMessage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).DeleteMessage(ctx, req.(*DeleteMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_ListLanguages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLanguagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).ListLanguages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/ListLanguages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).ListLanguages(ctx, req.(*ListLanguagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_ExportBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(I18NServiceServer).ExportBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/i18n.v1.I18nService/ExportBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(I18NServiceServer).ExportBundle(ctx, req.(*ExportBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _I18NService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(I18NServiceServer).Watch(m, &i18NServiceWatchServer{stream})
+}
+
+type I18NService_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type i18NServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *i18NServiceWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// I18NService_ServiceDesc is the grpc.ServiceDesc for I18NService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var I18NService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "i18n.v1.I18nService",
+	HandlerType: (*I18NServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetMessage",
+			Handler:    _I18NService_GetMessage_Handler,
+		},
+		{
+			MethodName: "BatchGetMessages",
+			Handler:    _I18NService_BatchGetMessages_Handler,
+		},
+		{
+			MethodName: "UpsertMessage",
+			Handler:    _I18NService_UpsertMessage_Handler,
+		},
+		{
+			MethodName: "DeleteMessage",
+			Handler:    _I18NService_DeleteMessage_Handler,
+		},
+		{
+			MethodName: "ListLanguages",
+			Handler:    _I18NService_ListLanguages_Handler,
+		},
+		{
+			MethodName: "ExportBundle",
+			Handler:    _I18NService_ExportBundle_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _I18NService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/i18n/v1/i18n.proto",
+}
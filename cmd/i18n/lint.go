@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/validator"
+	"os"
+)
+
+// lintCommand validates every language value of the i18n files against the source language. The exit code is 1 when
+// any finding is not lower than the '-fail-on' severity.
+func lintCommand(args []string) int {
+	flagSet := flag.NewFlagSet("lint", flag.ContinueOnError)
+	source := flagSet.String("source", "en", "the source language")
+	format := flagSet.String("format", "text", "output format, one of: text, json")
+	standard := flagSet.String("standard", provider.ISO6391, "the language standard of the files")
+	severity := flagSet.String("severity", string(validator.SeverityInfo), "the lowest severity to output")
+	failOn := flagSet.String("fail-on", string(validator.SeverityError), "the lowest severity to fail")
+	duplicates := flagSet.Bool("duplicates", true, "report the same value used by different scopes")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "Usage: i18n lint [flags] <file or dir>...")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return 2
+	}
+	for _, item := range []struct{ name, value string }{{"severity", *severity}, {"fail-on", *failOn}} {
+		if !validator.Severity(item.value).Valid() {
+			fmt.Fprintf(os.Stderr, "unknown %s: %s, should be one of: error, warning, info\n", item.name, item.value)
+			return 2
+		}
+	}
+
+	instance, err := files.FromFiles(*standard, flagSet.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	v := validator.New(*source)
+	v.Duplicates = *duplicates
+	all := v.Validate(instance)
+	findings := []validator.Finding{}
+	for _, finding := range all {
+		if finding.Severity.AtLeast(validator.Severity(*severity)) {
+			findings = append(findings, finding)
+		}
+	}
+
+	switch *format {
+	case "text":
+		for _, finding := range findings {
+			fmt.Println(finding)
+		}
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(findings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format: %s\n", *format)
+		return 2
+	}
+
+	if validator.HasSeverity(all, validator.Severity(*failOn)) {
+		return 1
+	}
+	return 0
+}
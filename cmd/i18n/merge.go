@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"os"
+	"path"
+)
+
+// mergeCommand does a three-way merge of i18n files, and writes the result to the ours file. It can be used as a git merge
+// driver, add the config to .git/config:
+//
+//	[merge "i18n"]
+//	    name = i18n catalog merge driver
+//	    driver = i18n merge -ext .json %O %A %B
+//
+// And add the line to .gitattributes:
+//
+//	i18n.json merge=i18n
+//
+// The exit code is 1 when any conflict is kept in the result (markers policy) or the merge failed (fail policy), so
+// git will report the conflict to user.
+func mergeCommand(args []string) int {
+	flagSet := flag.NewFlagSet("merge", flag.ContinueOnError)
+	policy := flagSet.String("policy", string(provider.MergeConflictMarkers),
+		"conflict policy, one of: ours, theirs, fail, markers")
+	ext := flagSet.String("ext", "",
+		"file format extension like '.json', default is the extension of ours file")
+	output := flagSet.String("o", "", "output file, default is the ours file")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "Usage: i18n merge [flags] <base> <ours> <theirs>")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() != 3 {
+		flagSet.Usage()
+		return 2
+	}
+	basePath, oursPath, theirsPath := flagSet.Arg(0), flagSet.Arg(1), flagSet.Arg(2)
+	if len(*output) == 0 {
+		*output = oursPath
+	}
+	if len(*ext) == 0 {
+		*ext = path.Ext(oursPath)
+	}
+
+	readFunc, ok := files.Reader(*ext)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unsupported file format: %s\n", *ext)
+		return 2
+	}
+	writeFunc, ok := files.Writer(*ext)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unsupported file format: %s\n", *ext)
+		return 2
+	}
+
+	var instances []*provider.I18n
+	for index, filePath := range []string{basePath, oursPath, theirsPath} {
+		// git passes an empty base when both branches add the file, the base is an empty instance then.
+		if index == 0 && isEmptyFile(filePath) {
+			instances = append(instances, nil)
+			continue
+		}
+		instance, err := readFunc(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read %s: %v\n", filePath, err)
+			return 2
+		}
+		instances = append(instances, instance)
+	}
+
+	res, conflicts, err := provider.Merge(instances[0], instances[1], instances[2], provider.MergePolicy(*policy))
+	for _, conflict := range conflicts {
+		fmt.Fprintf(os.Stderr, "conflict: %v [%s]: ours=%q theirs=%q\n",
+			conflict.Scopes, conflict.Language, conflict.Ours, conflict.Theirs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err = writeFunc(*output, res); err != nil {
+		fmt.Fprintf(os.Stderr, "write %s: %v\n", *output, err)
+		return 2
+	}
+
+	if provider.MergePolicy(*policy) == provider.MergeConflictMarkers && len(conflicts) != 0 {
+		return 1
+	}
+	return 0
+}
+
+// isEmptyFile return true if the file is missing or has no content.
+func isEmptyFile(filePath string) bool {
+	info, err := os.Stat(filePath)
+	return os.IsNotExist(err) || (err == nil && info.Size() == 0)
+}
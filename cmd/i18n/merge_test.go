@@ -0,0 +1,95 @@
+package main
+
+import (
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, file string, values map[string]string) {
+	t.Helper()
+	instance := provider.NewI18n(provider.ISO6391)
+	for ln, value := range values {
+		instance.PushMessageByString(ln, value, "user", "login")
+	}
+	if err := files.WriteFile(file, instance); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMergeCommand(t *testing.T) {
+	dir := t.TempDir()
+	base, ours, theirs := filepath.Join(dir, "base.json"), filepath.Join(dir, "ours.json"), filepath.Join(dir, "theirs.json")
+	writeCatalog(t, base, map[string]string{"en": "Login"})
+	writeCatalog(t, ours, map[string]string{"en": "Sign in"})
+	writeCatalog(t, theirs, map[string]string{"en": "Log in", "ja": "ログイン"})
+
+	cases := []struct {
+		policy string
+		code   int
+		want   string
+	}{
+		{"ours", 0, "Sign in"},
+		{"theirs", 0, "Log in"},
+		{"markers", 1, provider.ConflictMarkerOurs + "\nSign in\n" + provider.ConflictMarkerSplit + "\nLog in\n" +
+			provider.ConflictMarkerTheirs},
+		{"fail", 1, ""},
+	}
+	for _, c := range cases {
+		output := filepath.Join(dir, c.policy+".json")
+		if code := mergeCommand([]string{"-policy", c.policy, "-o", output, base, ours, theirs}); code != c.code {
+			t.Errorf("the merge of %s get exit code: %d, want: %d", c.policy, code, c.code)
+		}
+		res, err := files.ReadFile(output)
+		if len(c.want) == 0 {
+			if err == nil {
+				t.Errorf("the failed merge of %s should not write the output", c.policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("read the merge of %s: %v", c.policy, err)
+		}
+		if value, _ := res.MessageByString("en", "user", "login"); value != c.want {
+			t.Errorf("the merge of %s get: %q, want: %q", c.policy, value, c.want)
+		}
+		if value, _ := res.MessageByString("ja", "user", "login"); value != "ログイン" {
+			t.Errorf("the merge of %s should keep the value added by theirs, get: %q", c.policy, value)
+		}
+	}
+
+	// the base of an add/add conflict is an empty file.
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeCatalog(t, theirs, map[string]string{"ja": "ログイン"})
+	if code := mergeCommand([]string{"-o", filepath.Join(dir, "added.json"), empty, ours, theirs}); code != 0 {
+		t.Errorf("the merge with an empty base get exit code: %d, want: 0", code)
+	}
+	added, err := files.ReadFile(filepath.Join(dir, "added.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	en, _ := added.MessageByString("en", "user", "login")
+	ja, _ := added.MessageByString("ja", "user", "login")
+	if en != "Sign in" || ja != "ログイン" {
+		t.Errorf("the merge with an empty base get: %q, %q, want the values of both", en, ja)
+	}
+	if code := mergeCommand([]string{"-o", filepath.Join(dir, "added.json"), filepath.Join(dir, "missing.json"), ours,
+		theirs}); code != 0 {
+		t.Errorf("the merge with a missing base get exit code: %d, want: 0", code)
+	}
+
+	if code := mergeCommand([]string{base, ours}); code != 2 {
+		t.Errorf("the merge without theirs get exit code: %d, want: 2", code)
+	}
+	if code := mergeCommand([]string{"-ext", ".txt", base, ours, theirs}); code != 2 {
+		t.Errorf("the merge of an unsupported format get exit code: %d, want: 2", code)
+	}
+	if code := mergeCommand([]string{base, filepath.Join(dir, "missing.json"), theirs}); code != 2 {
+		t.Errorf("the merge of a missing ours get exit code: %d, want: 2", code)
+	}
+}
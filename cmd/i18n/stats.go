@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/stats"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// statsCommand print the coverage statistics of the i18n files. With the '-min' flag, it can be used as a CI gate, the exit
+// code is 1 when any language coverage is less than the threshold, like:
+//
+//	i18n stats -min ja=95,zh=90 ./resources
+//	i18n stats -min 95 ./resources
+func statsCommand(args []string) int {
+	flagSet := flag.NewFlagSet("stats", flag.ContinueOnError)
+	format := flagSet.String("format", stats.FormatTable, "output format, one of: table, json, markdown")
+	standard := flagSet.String("standard", provider.ISO6391, "the language standard of the files")
+	languages := flagSet.String("languages", "", "comma separated languages to compute, default is all languages")
+	minimum := flagSet.String("min", "",
+		"minimum coverage percentage, like '95' for all languages or 'ja=95,zh=90' for specify languages")
+	flagSet.Usage = func() {
+		fmt.Fprintln(flagSet.Output(), "Usage: i18n stats [flags] <file or dir>...")
+		flagSet.PrintDefaults()
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return 2
+	}
+	if flagSet.NArg() == 0 {
+		flagSet.Usage()
+		return 2
+	}
+
+	thresholds, err := parseThresholds(*minimum)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	instance, err := files.FromFiles(*standard, flagSet.Args()...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// the languages of thresholds are always computed, a language not in the files is 0% covered.
+	computeLanguages := splitList(*languages)
+	if len(computeLanguages) == 0 {
+		computeLanguages = stats.Compute(instance).Languages
+	}
+	for language := range thresholds {
+		if len(language) != 0 && !contains(computeLanguages, language) {
+			computeLanguages = append(computeLanguages, language)
+		}
+	}
+
+	report := stats.Compute(instance, computeLanguages...)
+	if err = report.Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	res := 0
+	for _, item := range report.Total {
+		threshold, ok := thresholds[item.Language]
+		if !ok {
+			threshold, ok = thresholds[""]
+		}
+		if ok && item.Percentage < threshold {
+			fmt.Fprintf(os.Stderr, "coverage of %s is %.2f%%, less than %.2f%%\n", item.Language, item.Percentage, threshold)
+			res = 1
+		}
+	}
+	return res
+}
+
+// parseThresholds parse the value like '95' or 'ja=95,zh=90', the key of the threshold for all languages is empty.
+func parseThresholds(value string) (map[string]float64, error) {
+	res := map[string]float64{}
+	for _, item := range splitList(value) {
+		language, percentage := "", item
+		if index := strings.Index(item, "="); index >= 0 {
+			language, percentage = strings.TrimSpace(item[:index]), strings.TrimSpace(item[index+1:])
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSuffix(percentage, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold: %s", item)
+		}
+		res[language] = threshold
+	}
+	return res, nil
+}
+
+// splitList split the comma separated value, the empty items are dropped.
+func splitList(value string) []string {
+	var res []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
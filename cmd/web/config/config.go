@@ -13,4 +13,201 @@ type ApplicationConfig struct {
 	Files           []string `json:"files" yaml:"files" mapstructure:"files"`
 	Readonly        bool     `json:"readonly" yaml:"readonly" mapstructure:"readonly"`
 	NotFoundWith404 bool     `json:"not_found_with_404" yaml:"not_found_with_404" mapstructure:"not_found_with_404"`
+
+	// DefaultLanguage is the language used when the Accept-Language header matches nothing, like 'en'.
+	DefaultLanguage string `json:"default_language" yaml:"default_language" mapstructure:"default_language"`
+	// LanguageFallbacks is the fallback chain of languages, like 'zh-tw: [zh-hant, zh]'.
+	LanguageFallbacks map[string][]string `json:"language_fallbacks" yaml:"language_fallbacks" mapstructure:"language_fallbacks"`
+
+	// CacheControl is the Cache-Control header of the read apis, like 'no-cache' or 'max-age=60'. Empty means no header.
+	CacheControl string `json:"cache_control" yaml:"cache_control" mapstructure:"cache_control"`
+
+	// ValidateOpenAPI enable the validation of requests and responses by the OpenAPI document, for development only.
+	ValidateOpenAPI bool `json:"validate_openapi" yaml:"validate_openapi" mapstructure:"validate_openapi"`
+
+	// MissingCollectLimit is the max count of different missed messages which the server records, 0 means no limit.
+	MissingCollectLimit int `json:"missing_collect_limit" yaml:"missing_collect_limit" mapstructure:"missing_collect_limit"`
+
+	// WatchHistory is the count of recent changes kept for the watch clients to resume from a revision.
+	WatchHistory int `json:"watch_history" yaml:"watch_history" mapstructure:"watch_history"`
+
+	// Health is the readiness of the server.
+	Health HealthConfig `json:"health" yaml:"health" mapstructure:"health"`
+
+	// ShutdownTimeout is how long the in-flight requests are drained on SIGTERM like '30s', the connections which are
+	// still active after it are closed. Empty means they are closed at once.
+	ShutdownTimeout string `json:"shutdown_timeout" yaml:"shutdown_timeout" mapstructure:"shutdown_timeout"`
+
+	// TLS is the certificate of the http and gRPC servers, the servers serve plain text when it is not set.
+	TLS TLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"`
+
+	// CORS is the cross-origin requests of the browser clients, it is disabled when no origin is allowed.
+	CORS CORSConfig `json:"cors" yaml:"cors" mapstructure:"cors"`
+
+	// Storage is the persistence of the changes by the apis, the changes are kept in memory only when the file is empty.
+	Storage StorageConfig `json:"storage" yaml:"storage" mapstructure:"storage"`
+
+	// Reload is the reload of the catalog files.
+	Reload ReloadConfig `json:"reload" yaml:"reload" mapstructure:"reload"`
+
+	// GRPC is the gRPC api server, it serves the same catalog as the REST api.
+	GRPC GRPCConfig `json:"grpc" yaml:"grpc" mapstructure:"grpc"`
+
+	// Projects is the catalogs of other apps which the server hosts besides the default catalog of the Files.
+	Projects ProjectsConfig `json:"projects" yaml:"projects" mapstructure:"projects"`
+
+	// Auth is the authentication of the apis, all apis are public when it is disabled.
+	Auth AuthConfig `json:"auth" yaml:"auth" mapstructure:"auth"`
+
+	// Audit is the audit log of the message changes, the audit log is disabled when the file is empty.
+	Audit AuditConfig `json:"audit" yaml:"audit" mapstructure:"audit"`
+
+	// Snapshots is the snapshots of the catalog, the snapshots are disabled when the dir is empty.
+	Snapshots SnapshotConfig `json:"snapshots" yaml:"snapshots" mapstructure:"snapshots"`
+}
+
+// TLSConfig is the certificate and key files in PEM.
+type TLSConfig struct {
+	CertFile string `json:"cert_file" yaml:"cert_file" mapstructure:"cert_file"`
+	KeyFile  string `json:"key_file" yaml:"key_file" mapstructure:"key_file"`
+	// ReloadInterval is the interval to check the rotated files like '1m', empty means the files are read once.
+	ReloadInterval string `json:"reload_interval" yaml:"reload_interval" mapstructure:"reload_interval"`
+}
+
+// CORSConfig is the CORS headers of the responses.
+type CORSConfig struct {
+	// AllowOrigins is the origins like 'https://app.example.com', '*' means all origins.
+	AllowOrigins []string `json:"allow_origins" yaml:"allow_origins" mapstructure:"allow_origins"`
+	// AllowMethods is the methods of the preflight response, empty means the read methods.
+	AllowMethods []string `json:"allow_methods" yaml:"allow_methods" mapstructure:"allow_methods"`
+	// AllowHeaders is the request headers of the preflight response, empty means the headers of the apis.
+	AllowHeaders []string `json:"allow_headers" yaml:"allow_headers" mapstructure:"allow_headers"`
+	// ExposeHeaders is the response headers which the browser clients can read, like 'ETag'.
+	ExposeHeaders    []string `json:"expose_headers" yaml:"expose_headers" mapstructure:"expose_headers"`
+	AllowCredentials bool     `json:"allow_credentials" yaml:"allow_credentials" mapstructure:"allow_credentials"`
+	// MaxAge is how long the preflight response can be cached like '10m', empty means no cache.
+	MaxAge string `json:"max_age" yaml:"max_age" mapstructure:"max_age"`
+}
+
+// StorageConfig is the catalog file which the changes by the apis are written to.
+type StorageConfig struct {
+	// File is the catalog file like './data/i18n.json', it is loaded after the files, so the changes survive restarts.
+	// The removals of the values of the files are kept in '<File>.removed'.
+	File string `json:"file" yaml:"file" mapstructure:"file"`
+	// FlushInterval is the delay of the write like '1s', the changes in it are written once.
+	FlushInterval string `json:"flush_interval" yaml:"flush_interval" mapstructure:"flush_interval"`
+}
+
+// ReloadConfig is when the catalog files are reloaded. The reloaded catalog replaces the values of the instance, and the
+// watchers receive the changes.
+type ReloadConfig struct {
+	// Interval is the interval to check the changes of the files like '10s', empty means never.
+	Interval string `json:"interval" yaml:"interval" mapstructure:"interval"`
+	// Signal enables the reload when the server receives SIGHUP.
+	Signal bool `json:"signal" yaml:"signal" mapstructure:"signal"`
+}
+
+// ProjectsConfig is the projects of the server. Every project has its own catalog, and its apis are served under
+// '/v1/projects/:project' and '/v2/projects/:project' like the apis of the default catalog.
+type ProjectsConfig struct {
+	// Dir keeps the projects, every project is a dir of its id with the storage file of its changes, its audit log and
+	// snapshots. The projects created by the apis are kept in it too. Empty means the projects are kept in memory only.
+	Dir string `json:"dir" yaml:"dir" mapstructure:"dir"`
+	// Items is the projects of the config, they can't be deleted by the apis.
+	Items []ProjectConfig `json:"items" yaml:"items" mapstructure:"items"`
+}
+
+// ProjectConfig is the config of a project, the other configs of the project are the same as the server.
+type ProjectConfig struct {
+	// ID is the project id in the path, like 'shop-web'. It is 1-64 lowercase letters, digits, '-' and '_'.
+	ID string `json:"id" yaml:"id" mapstructure:"id"`
+	// Files is the catalog files of the project, they are reloaded like the Files of the server.
+	Files []string `json:"files" yaml:"files" mapstructure:"files"`
+	// Standard is the language standard of the project like 'ISO 639-1', empty means 'ISO 639-1'.
+	Standard string `json:"standard" yaml:"standard" mapstructure:"standard"`
+	// Readonly disables the apis which change the catalog of the project, they are disabled for all projects when the
+	// server is readonly.
+	Readonly bool `json:"readonly" yaml:"readonly" mapstructure:"readonly"`
+	// Auth is the authentication of the project apis, the auth of the server is used when it is disabled.
+	Auth AuthConfig `json:"auth" yaml:"auth" mapstructure:"auth"`
+}
+
+// HealthConfig is the readiness config, the server is ready after the catalog is loaded.
+type HealthConfig struct {
+	// MinKeys is the min count of the values for the server to be ready, 0 means an empty catalog is ready too.
+	MinKeys int `json:"min_keys" yaml:"min_keys" mapstructure:"min_keys"`
+}
+
+// GRPCConfig is the gRPC server config.
+type GRPCConfig struct {
+	// Addr is the listen address like ':3001', empty means the gRPC server is disabled. Default is empty, the gRPC
+	// server is opt-in.
+	Addr string `json:"addr" yaml:"addr" mapstructure:"addr"`
+}
+
+// SnapshotConfig is the snapshot store config.
+type SnapshotConfig struct {
+	Dir string `json:"dir" yaml:"dir" mapstructure:"dir"`
+	// Format is the file extension of the snapshot catalog file, like '.json'.
+	Format string `json:"format" yaml:"format" mapstructure:"format"`
+	// Every is the count of changes between two automatic snapshots, 0 means no automatic snapshot.
+	Every int `json:"every" yaml:"every" mapstructure:"every"`
+	// KeepAuto is the count of the newest automatic snapshots which are kept, the older ones are removed. 0 means all
+	// automatic snapshots are kept.
+	KeepAuto int `json:"keep_auto" yaml:"keep_auto" mapstructure:"keep_auto"`
+}
+
+// AuditConfig is the audit log config. The log is JSON lines, and it is rotated when larger than MaxSize.
+type AuditConfig struct {
+	File string `json:"file" yaml:"file" mapstructure:"file"`
+	// MaxSize is the max bytes of the file before rotation, 0 means never rotate.
+	MaxSize int64 `json:"max_size" yaml:"max_size" mapstructure:"max_size"`
+	// MaxBackups is the count of the rotated files to keep, 0 means all of them are kept.
+	MaxBackups int `json:"max_backups" yaml:"max_backups" mapstructure:"max_backups"`
+}
+
+// AuthConfig is the authentication config. A request can be authenticated by a static api key, an HMAC-signed token or
+// the basic auth with an htpasswd file. The scopes are 'read', 'write' and 'admin', a higher scope contains the lower.
+type AuthConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+
+	// APIKeys is the static api keys, the key can be sent by 'X-API-Key' header or 'Authorization: Bearer <key>'.
+	APIKeys []APIKeyConfig `json:"api_keys" yaml:"api_keys" mapstructure:"api_keys"`
+
+	// TokenSecret is the HMAC secret of the tokens, empty means tokens are disabled.
+	TokenSecret string `json:"token_secret" yaml:"token_secret" mapstructure:"token_secret" secret:"true"`
+	// TokenTTL is the default lifetime of the issued tokens, like '24h'. Empty means '24h', the tokens always expire.
+	TokenTTL string `json:"token_ttl" yaml:"token_ttl" mapstructure:"token_ttl"`
+
+	// HtpasswdFile is the path of the htpasswd file for the basic auth, empty means basic auth is disabled.
+	HtpasswdFile string `json:"htpasswd_file" yaml:"htpasswd_file" mapstructure:"htpasswd_file"`
+	// HtpasswdScopes is the scopes of the users in htpasswd file, the users which are not listed have 'read' scope.
+	HtpasswdScopes map[string][]string `json:"htpasswd_scopes" yaml:"htpasswd_scopes" mapstructure:"htpasswd_scopes"`
+
+	// AnonymousScopes is the scopes of the requests without any credential, like '[read]'. Empty means 401.
+	AnonymousScopes []string `json:"anonymous_scopes" yaml:"anonymous_scopes" mapstructure:"anonymous_scopes"`
+
+	// ACL is the rules which limit the languages and scopes a principal can change. Without any rule, a principal can
+	// change everything its scopes allow. Once a rule is configured, a principal can only change the values matched by
+	// its rules or the rules of the principal '*', give an unrestricted principal the rule with permission '*'.
+	ACL []ACLRuleConfig `json:"acl" yaml:"acl" mapstructure:"acl"`
+}
+
+// APIKeyConfig is a static api key. The Name is the principal name of the key, it will be recorded as the actor.
+type APIKeyConfig struct {
+	Name   string   `json:"name" yaml:"name" mapstructure:"name"`
+	Key    string   `json:"key" yaml:"key" mapstructure:"key" secret:"true"`
+	Scopes []string `json:"scopes" yaml:"scopes" mapstructure:"scopes"`
+}
+
+// ACLRuleConfig grants a principal the permission of a language and scope prefix.
+type ACLRuleConfig struct {
+	// Principal is the name of the api key, the token subject or the htpasswd user, '*' means every principal.
+	Principal string `json:"principal" yaml:"principal" mapstructure:"principal"`
+	// Language is the language string value like 'ja', '*' or empty means all languages.
+	Language string `json:"language" yaml:"language" mapstructure:"language"`
+	// Scope is the scope prefix joined by '.' like 'user.text', empty means all scopes.
+	Scope string `json:"scope" yaml:"scope" mapstructure:"scope"`
+	// Permission is 'write' (create and update), 'delete' or '*' (both).
+	Permission string `json:"permission" yaml:"permission" mapstructure:"permission"`
 }
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.yaml"), filepath.Join(dir, "second.yaml")
+	writeFile(t, first, "web_config:\n  addr: [':8080']\napplication_config:\n  readonly: true\n  watch_history: 10\n")
+	writeFile(t, second, "application_config:\n  watch_history: 20\n  auth:\n    enabled: true\n"+
+		"    api_keys:\n      - name: ci\n        key: secret-key\n        scopes: [write]\n")
+
+	c, err := Load([]string{"--config", first, "-c", second}, []string{
+		"I18N_APPLICATION_CONFIG_GRPC_ADDR=:9090",
+		"I18N_APPLICATION_CONFIG_FILES=a.json, b.json",
+		"PATH=/bin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.WebConfig.Addr[0] != ":8080" || !c.ApplicationConfig.Readonly {
+		t.Errorf("Load get: %+v, want the values of the first file", c)
+	}
+	if c.ApplicationConfig.WatchHistory != 20 || len(c.ApplicationConfig.Auth.APIKeys) != 1 {
+		t.Errorf("Load get: %+v, want the values of the second file", c.ApplicationConfig)
+	}
+	if c.ApplicationConfig.GRPC.Addr != ":9090" || strings.Join(c.ApplicationConfig.Files, "|") != "a.json|b.json" {
+		t.Errorf("Load get: %+v, want the values of the env", c.ApplicationConfig)
+	}
+	if c.ApplicationConfig.MissingCollectLimit != Default().ApplicationConfig.MissingCollectLimit {
+		t.Error("Load should keep the default of the missing keys")
+	}
+
+	// the config files can be set by the env too.
+	if c, err = Load(nil, []string{ConfigEnv + "=" + first}); err != nil || c.WebConfig.Addr[0] != ":8080" {
+		t.Errorf("Load by %s get: %v, %v", ConfigEnv, c.WebConfig, err)
+	}
+	if len(c.ApplicationConfig.GRPC.Addr) != 0 {
+		t.Errorf("The gRPC server should be disabled by default, get: %s", c.ApplicationConfig.GRPC.Addr)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	dir := t.TempDir()
+	typo, invalid := filepath.Join(dir, "typo.yaml"), filepath.Join(dir, "invalid.yaml")
+	writeFile(t, typo, "application_config:\n  readonly_mode: true\n")
+	writeFile(t, invalid, "web_config:\n  mod: fast\napplication_config:\n  cors:\n    allow_origins: ['*']\n"+
+		"    allow_credentials: true\n  storage:\n    flush_interval: soon\n")
+
+	cases := []struct {
+		args    []string
+		environ []string
+		want    []string
+	}{
+		{args: []string{"-c", filepath.Join(dir, "none.yaml")}, want: []string{"none.yaml"}},
+		{args: []string{"-c", typo}, want: []string{"readonly_mode"}},
+		{environ: []string{"I18N_APPLICATION_CONFIG_READONLY=maybe"}, want: []string{
+			"I18N_APPLICATION_CONFIG_READONLY", "want a bool"}},
+		{environ: []string{"I18N_APPLICATION_CONFIG_READ_ONLY=true"}, want: []string{
+			"unknown env: I18N_APPLICATION_CONFIG_READ_ONLY"}},
+		{args: []string{"-c", invalid}, want: []string{
+			"3 problem(s)",
+			"web_config.mod: should be in [debug, release, test], got 'fast'",
+			"application_config.cors.allow_credentials",
+			"application_config.storage.flush_interval",
+		}},
+	}
+	for _, item := range cases {
+		_, err := Load(item.args, item.environ)
+		if err == nil {
+			t.Errorf("Load %v %v should fail", item.args, item.environ)
+			continue
+		}
+		for _, want := range item.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Load %v %v get: %v, want: %s", item.args, item.environ, err, want)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := Default()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate default get: %v", err)
+	}
+
+	c.WebConfig.Addr = []string{"3000"}
+	c.ApplicationConfig.GRPC.Addr = "3000"
+	c.ApplicationConfig.TLS.CertFile = "cert.pem"
+	c.ApplicationConfig.Auth = AuthConfig{Enabled: true, APIKeys: []APIKeyConfig{
+		{Name: "a", Key: "k", Scopes: []string{"read"}},
+		{Name: "b", Key: "k", Scopes: []string{"owner"}},
+	}, TokenTTL: "0s"}
+	err, ok := c.Validate().(ValidationError)
+	if !ok {
+		t.Fatalf("Validate get: %v, want a ValidationError", err)
+	}
+	fields := map[string]bool{}
+	for _, item := range err {
+		fields[item.Field] = true
+	}
+	for _, field := range []string{
+		"web_config.addr[0]",
+		"application_config.grpc.addr",
+		"application_config.tls.cert_file",
+		"application_config.tls.key_file",
+		"application_config.auth.api_keys[1].key",
+		"application_config.auth.api_keys[1].scopes",
+		"application_config.auth.token_ttl",
+	} {
+		if !fields[field] {
+			t.Errorf("Validate get: %v, want a problem of %s", err, field)
+		}
+	}
+}
+
+func TestDump(t *testing.T) {
+	c := Default()
+	c.ApplicationConfig.Auth.TokenSecret = "token-secret"
+	c.ApplicationConfig.Auth.APIKeys = []APIKeyConfig{{Name: "ci", Key: "api-key", Scopes: []string{"read"}}}
+
+	buffer := &bytes.Buffer{}
+	if err := c.Dump(buffer); err != nil {
+		t.Fatal(err)
+	}
+	dump := buffer.String()
+	if strings.Contains(dump, "token-secret") || strings.Contains(dump, "api-key") {
+		t.Errorf("Dump should mask the secrets, get:\n%s", dump)
+	}
+	if !strings.Contains(dump, "name: ci") || !strings.Contains(dump, "watch_history: 1000") {
+		t.Errorf("Dump get:\n%s", dump)
+	}
+
+	// the dump can be loaded again.
+	file := filepath.Join(t.TempDir(), "dump.yaml")
+	writeFile(t, file, dump)
+	loaded := Default()
+	if err := loaded.ReadFile(file); err != nil {
+		t.Errorf("ReadFile of the dump get: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
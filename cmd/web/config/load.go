@@ -0,0 +1,286 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/uberate/mocker-utils/gins"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables which override the config. The name is the key path joined
+	// by '_' in upper case, like 'I18N_APPLICATION_CONFIG_READONLY=true'. A list is separated by ','.
+	EnvPrefix = "I18N_"
+
+	// ConfigEnv is the environment variable of the config files separated by ',', it is used when no --config flag.
+	ConfigEnv = EnvPrefix + "CONFIG"
+
+	secretMask = "******"
+)
+
+// Default return the default config of the web server.
+func Default() I18nConfig {
+	return I18nConfig{
+		WebConfig: gins.WebConfig{
+			Addr: []string{":3000"},
+			Mod:  gin.TestMode,
+		},
+		ApplicationConfig: ApplicationConfig{
+			CacheControl:        "no-cache",
+			MissingCollectLimit: 10000,
+			WatchHistory:        1000,
+			ShutdownTimeout:     "30s",
+			Storage: StorageConfig{
+				FlushInterval: "1s",
+			},
+			Audit: AuditConfig{
+				MaxSize:    100 << 20,
+				MaxBackups: 5,
+			},
+			Snapshots: SnapshotConfig{
+				Format: ".json",
+			},
+		},
+	}
+}
+
+// Load return the effective config of the command line args (without the program name) and the environment variables
+// like os.Environ(). The Default is overridden by the config files of the --config flags in order, then by the
+// environment variables with EnvPrefix. The result is validated, see Validate.
+func Load(args []string, environ []string) (I18nConfig, error) {
+	var paths pathList
+	flags := flag.NewFlagSet("web", flag.ContinueOnError)
+	flags.Var(&paths, "config", "the config file in yaml or json, it can be repeated, the latter overrides the former")
+	flags.Var(&paths, "c", "the short of --config")
+	if err := flags.Parse(args); err != nil {
+		return I18nConfig{}, err
+	}
+	if flags.NArg() != 0 {
+		return I18nConfig{}, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	env := map[string]string{}
+	for _, item := range environ {
+		if index := strings.Index(item, "="); index > 0 && strings.HasPrefix(item, EnvPrefix) {
+			env[item[:index]] = item[index+1:]
+		}
+	}
+	if len(paths) == 0 && len(env[ConfigEnv]) != 0 {
+		paths = strings.Split(env[ConfigEnv], ",")
+	}
+	delete(env, ConfigEnv)
+
+	c := Default()
+	for _, path := range paths {
+		if err := c.ReadFile(strings.TrimSpace(path)); err != nil {
+			return I18nConfig{}, err
+		}
+	}
+	if err := c.ApplyEnv(env); err != nil {
+		return I18nConfig{}, err
+	}
+	return c, c.Validate()
+}
+
+// ReadFile override the config by the yaml or json file, only the keys in the file are changed. An unknown key is an
+// error, so a typo is never ignored.
+func (c *I18nConfig) ReadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %v", err)
+	}
+	values := map[string]interface{}{}
+	if err = yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           c,
+	})
+	if err != nil {
+		return err
+	}
+	if err = decoder.Decode(values); err != nil {
+		return fmt.Errorf("config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// ApplyEnv override the config by the environment variables with EnvPrefix, the key is the variable name. The strings,
+// bools, integers and lists of strings can be overridden. An unknown variable with EnvPrefix is an error.
+func (c *I18nConfig) ApplyEnv(env map[string]string) error {
+	known := map[string]bool{}
+	err := walkFields(reflect.ValueOf(c).Elem(), nil, func(path []string, field reflect.Value) error {
+		name := EnvName(path...)
+		value, ok := env[name]
+		if !ok {
+			return nil
+		}
+		known[name] = true
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("env %s: %v", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+	for name := range env {
+		if strings.HasPrefix(name, EnvPrefix) && !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown env: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// EnvName return the environment variable of the key path, like 'I18N_WEB_CONFIG_ADDR' of 'web_config.addr'.
+func EnvName(path ...string) string {
+	return EnvPrefix + strings.ToUpper(strings.Join(path, "_"))
+}
+
+// Dump write the config as yaml, the secrets like the api keys and the token secret are masked.
+func (c I18nConfig) Dump(writer io.Writer) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(dumpNode(reflect.ValueOf(c), false)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// walkFields invoke f with the key path of every field which can be set by a string, the key is the mapstructure tag.
+func walkFields(value reflect.Value, path []string, f func(path []string, field reflect.Value) error) error {
+	for index := 0; index < value.NumField(); index++ {
+		field, structField := value.Field(index), value.Type().Field(index)
+		fieldPath := append(append([]string{}, path...), fieldKey(structField))
+		if field.Kind() == reflect.Struct {
+			if err := walkFields(field, fieldPath, f); err != nil {
+				return err
+			}
+			continue
+		}
+		if settable(field.Type()) {
+			if err := f(fieldPath, field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldKey(field reflect.StructField) string {
+	if key := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; len(key) != 0 {
+		return key
+	}
+	return strings.ToLower(field.Name)
+}
+
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("want a bool, got '%s'", value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("want an integer, got '%s'", value)
+		}
+		field.SetInt(parsed)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); len(item) != 0 {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// dumpNode return the yaml node of the value in the field order, the keys are the mapstructure tags.
+func dumpNode(value reflect.Value, secret bool) *yaml.Node {
+	switch value.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for index := 0; index < value.NumField(); index++ {
+			structField := value.Type().Field(index)
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fieldKey(structField)},
+				dumpNode(value.Field(index), structField.Tag.Get("secret") == "true"))
+		}
+		return node
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for index := 0; index < value.Len(); index++ {
+			item := dumpNode(value.Index(index), secret)
+			if item.Kind != yaml.ScalarNode {
+				node.Style = 0
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := value.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return keys[a].String() < keys[b].String()
+		})
+		for _, key := range keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key.String()},
+				dumpNode(value.MapIndex(key), secret))
+		}
+		return node
+	}
+
+	if secret && value.Kind() == reflect.String && value.Len() != 0 {
+		value = reflect.ValueOf(secretMask)
+	}
+	node := &yaml.Node{}
+	_ = node.Encode(value.Interface())
+	return node
+}
+
+// pathList is the value of a repeatable flag.
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var validScopes = map[string]bool{"read": true, "write": true, "admin": true}
+
+var projectIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// FieldError is a problem of a config field, the Field is the key path like 'application_config.tls.cert_file'.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError is all problems of a config.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("invalid config, %d problem(s):", len(e)))
+	for _, item := range e {
+		lines = append(lines, "  - "+item.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate return a ValidationError with all problems of the project config, or nil. The Field of the problems is the
+// key of the ProjectConfig like 'auth.api_keys[0].key'.
+func (p ProjectConfig) Validate() error {
+	v := &validator{}
+	v.project("", p)
+	if len(v.problems) != 0 {
+		return v.problems
+	}
+	return nil
+}
+
+// Validate return a ValidationError with all problems of the config, or nil.
+func (c I18nConfig) Validate() error {
+	v := &validator{}
+
+	if len(c.WebConfig.Addr) != 1 {
+		v.add("web_config.addr", fmt.Sprintf("should be one listen address, got %d", len(c.WebConfig.Addr)))
+	}
+	for index, addr := range c.WebConfig.Addr {
+		v.addr(fmt.Sprintf("web_config.addr[%d]", index), addr)
+	}
+	switch c.WebConfig.Mod {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		v.add("web_config.mod", fmt.Sprintf("should be in [debug, release, test], got '%s'", c.WebConfig.Mod))
+	}
+
+	app := c.ApplicationConfig
+	const prefix = "application_config."
+	v.nonNegative(prefix+"missing_collect_limit", int64(app.MissingCollectLimit))
+	v.nonNegative(prefix+"watch_history", int64(app.WatchHistory))
+	v.nonNegative(prefix+"health.min_keys", int64(app.Health.MinKeys))
+	v.duration(prefix+"shutdown_timeout", app.ShutdownTimeout)
+
+	if len(app.GRPC.Addr) != 0 {
+		v.addr(prefix+"grpc.addr", app.GRPC.Addr)
+		for _, addr := range c.WebConfig.Addr {
+			if addr == app.GRPC.Addr {
+				v.add(prefix+"grpc.addr", fmt.Sprintf("'%s' is used by web_config.addr", addr))
+			}
+		}
+	}
+
+	if len(app.TLS.CertFile) != 0 || len(app.TLS.KeyFile) != 0 {
+		v.file(prefix+"tls.cert_file", app.TLS.CertFile)
+		v.file(prefix+"tls.key_file", app.TLS.KeyFile)
+	}
+	v.duration(prefix+"tls.reload_interval", app.TLS.ReloadInterval)
+
+	for index, origin := range app.CORS.AllowOrigins {
+		v.origin(fmt.Sprintf("%scors.allow_origins[%d]", prefix, index), origin)
+		if origin == "*" && app.CORS.AllowCredentials {
+			v.add(prefix+"cors.allow_credentials", "can't be true when all origins '*' are allowed")
+		}
+	}
+	v.duration(prefix+"cors.max_age", app.CORS.MaxAge)
+
+	if len(app.Storage.File) != 0 {
+		v.format(prefix+"storage.file", filepath.Ext(app.Storage.File))
+	}
+	v.duration(prefix+"storage.flush_interval", app.Storage.FlushInterval)
+	v.duration(prefix+"reload.interval", app.Reload.Interval)
+
+	v.auth(prefix+"auth.", app.Auth)
+
+	ids := map[string]bool{}
+	for index, project := range app.Projects.Items {
+		field := fmt.Sprintf("%sprojects.items[%d]", prefix, index)
+		v.project(field+".", project)
+		if ids[project.ID] {
+			v.add(field+".id", fmt.Sprintf("'%s' is duplicated", project.ID))
+		}
+		ids[project.ID] = true
+	}
+
+	v.nonNegative(prefix+"audit.max_size", app.Audit.MaxSize)
+	v.nonNegative(prefix+"audit.max_backups", int64(app.Audit.MaxBackups))
+	if len(app.Snapshots.Dir) != 0 {
+		v.format(prefix+"snapshots.format", app.Snapshots.Format)
+	}
+	v.nonNegative(prefix+"snapshots.every", int64(app.Snapshots.Every))
+	v.nonNegative(prefix+"snapshots.keep_auto", int64(app.Snapshots.KeepAuto))
+
+	if len(v.problems) != 0 {
+		return v.problems
+	}
+	return nil
+}
+
+type validator struct {
+	problems ValidationError
+}
+
+func (v *validator) add(field, message string) {
+	v.problems = append(v.problems, FieldError{Field: field, Message: message})
+}
+
+func (v *validator) addr(field, addr string) {
+	if _, port, err := net.SplitHostPort(addr); err != nil || len(port) == 0 {
+		v.add(field, fmt.Sprintf("should be 'host:port' or ':port', got '%s'", addr))
+	}
+}
+
+func (v *validator) nonNegative(field string, value int64) {
+	if value < 0 {
+		v.add(field, fmt.Sprintf("should not be negative, got %d", value))
+	}
+}
+
+func (v *validator) duration(field, value string) {
+	if len(value) == 0 {
+		return
+	}
+	if duration, err := time.ParseDuration(value); err != nil || duration < 0 {
+		v.add(field, fmt.Sprintf("should be a duration like '10s', got '%s'", value))
+	}
+}
+
+func (v *validator) file(field, path string) {
+	if len(path) == 0 {
+		v.add(field, "is required")
+		return
+	}
+	if info, err := os.Stat(path); err != nil {
+		v.add(field, err.Error())
+	} else if info.IsDir() {
+		v.add(field, fmt.Sprintf("'%s' is a directory", path))
+	}
+}
+
+func (v *validator) format(field, ext string) {
+	if _, ok := files.Writer(ext); !ok {
+		v.add(field, fmt.Sprintf("unsupported format '%s'", ext))
+	}
+}
+
+func (v *validator) origin(field, origin string) {
+	if origin == "*" {
+		return
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 ||
+		len(strings.Trim(parsed.Path, "/")) != 0 {
+		v.add(field, fmt.Sprintf("should be '*' or an origin like 'https://app.example.com', got '%s'", origin))
+	}
+}
+
+func (v *validator) scopes(field string, scopes []string) {
+	if len(scopes) == 0 {
+		v.add(field, "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			v.add(field, fmt.Sprintf("should be in [read, write, admin], got '%s'", scope))
+		}
+	}
+}
+
+func (v *validator) project(prefix string, project ProjectConfig) {
+	if !projectIDPattern.MatchString(project.ID) {
+		v.add(prefix+"id", fmt.Sprintf("should be 1-64 lowercase letters, digits, '-' and '_', got '%s'", project.ID))
+	}
+	if len(project.Standard) != 0 {
+		known := false
+		for _, standard := range provider.Standards {
+			known = known || standard == project.Standard
+		}
+		if !known {
+			v.add(prefix+"standard", fmt.Sprintf("should be in %v, got '%s'", provider.Standards, project.Standard))
+		}
+	}
+	v.auth(prefix+"auth.", project.Auth)
+}
+
+func (v *validator) auth(prefix string, auth AuthConfig) {
+	if !auth.Enabled {
+		return
+	}
+	if len(auth.APIKeys) == 0 && len(auth.TokenSecret) == 0 && len(auth.HtpasswdFile) == 0 &&
+		len(auth.AnonymousScopes) == 0 {
+		v.add(prefix+"enabled", "no api key, token secret, htpasswd file or anonymous scope, every request is denied")
+	}
+
+	keys := map[string]bool{}
+	for index, item := range auth.APIKeys {
+		field := fmt.Sprintf("%sapi_keys[%d]", prefix, index)
+		if len(item.Name) == 0 {
+			v.add(field+".name", "is required")
+		}
+		if len(item.Key) == 0 {
+			v.add(field+".key", "is required")
+		} else if keys[item.Key] {
+			v.add(field+".key", "is duplicated")
+		}
+		keys[item.Key] = true
+		v.scopes(field+".scopes", item.Scopes)
+	}
+	v.duration(prefix+"token_ttl", auth.TokenTTL)
+	if ttl, err := time.ParseDuration(auth.TokenTTL); err == nil && ttl == 0 {
+		v.add(prefix+"token_ttl", "should be positive, the tokens can't be revoked")
+	}
+	if len(auth.HtpasswdFile) != 0 {
+		v.file(prefix+"htpasswd_file", auth.HtpasswdFile)
+	}
+	for user, scopes := range auth.HtpasswdScopes {
+		v.scopes(prefix+"htpasswd_scopes."+user, scopes)
+	}
+	if len(auth.AnonymousScopes) != 0 {
+		v.scopes(prefix+"anonymous_scopes", auth.AnonymousScopes)
+	}
+	for index, rule := range auth.ACL {
+		field := fmt.Sprintf("%sacl[%d]", prefix, index)
+		if len(rule.Principal) == 0 {
+			v.add(field+".principal", "is required")
+		}
+		switch rule.Permission {
+		case "write", "delete", "*":
+		default:
+			v.add(field+".permission", fmt.Sprintf("should be in [write, delete, *], got '%s'", rule.Permission))
+		}
+	}
+}
+
+// Duration return the duration of a validated duration field like '10s', an empty or invalid value is 0.
+func Duration(value string) time.Duration {
+	duration, _ := time.ParseDuration(value)
+	return duration
+}
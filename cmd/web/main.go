@@ -1,45 +1,118 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/rpc"
 	"github.com/uberate/i18n/internal/web"
-	files2 "github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/internal/web/storage"
 	"github.com/uberate/i18n/pkg/provider"
-	"github.com/uberate/mocker-utils/files"
-	"github.com/uberate/mocker-utils/gins"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-var configInstance = &config.I18nConfig{
-	WebConfig: gins.WebConfig{
-		Addr: []string{":3000"},
-		Mod:  gin.TestMode,
-	},
-	ApplicationConfig: config.ApplicationConfig{},
-}
-
 func main() {
+	// the config is read from the --config files, then overridden by the I18N_ environment variables.
+	configInstance, err := config.Load(os.Args[1:], os.Environ())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Println("effective config:")
+	if err = configInstance.Dump(os.Stdout); err != nil {
+		panic(err)
+	}
+
+	gin.SetMode(configInstance.WebConfig.Mod)
 	engine := gin.Default()
 
-	i, err := files2.FromFiles(provider.ISO6391, configInstance.ApplicationConfig.Files...)
+	storageConfig := configInstance.ApplicationConfig.Storage
+	store, err := storage.Open(provider.ISO6391, configInstance.ApplicationConfig.Files, storageConfig.File,
+		config.Duration(storageConfig.FlushInterval), func(err error) {
+			log.Println(err)
+		})
 	if err != nil {
 		panic(err)
 	}
+	i := store.Instance()
+
+	services := web.RegisterHandler(engine, configInstance, i)
+	services.Loaded(store.Loaded(), nil)
 
-	web.RegisterHandler(engine, *configInstance, i)
+	// reload the catalog files when they are changed, or when SIGHUP is received.
+	reloadConfig := configInstance.ApplicationConfig.Reload
+	var signals chan os.Signal
+	if reloadConfig.Signal {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+	}
+	if signals != nil || config.Duration(reloadConfig.Interval) > 0 {
+		store.Watch(config.Duration(reloadConfig.Interval), signals, services.Loaded)
+	}
 
-	if err := gins.GinStart(engine, configInstance.WebConfig); err != nil {
+	// the REST and gRPC apis serve the same certificate when the tls is set.
+	reloader, stopReload, err := web.Certificates(configInstance)
+	if err != nil {
 		panic(err)
 	}
-}
+	defer stopReload()
 
-func init() {
-	if err := files.ReadConfig("",
-		"",
-		"",
-		true,
-		"",
-		configInstance); err != nil {
+	// the gRPC api serves the same instance on another port.
+	var grpcServer *grpc.Server
+	if addr := configInstance.ApplicationConfig.GRPC.Addr; len(addr) != 0 {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			panic(err)
+		}
+		service := rpc.NewService(configInstance, i, services.Authenticator, services.Audit, services.Watch)
+		var options []grpc.ServerOption
+		if reloader != nil {
+			options = append(options, grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+		}
+		grpcServer = rpc.NewServer(service, options...)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				panic(err)
+			}
+		}()
+	}
+
+	listener, err := net.Listen("tcp", configInstance.WebConfig.Addr[0])
+	if err != nil {
 		panic(err)
 	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	// the watch streams never end by themselves, so they are closed when the shutdown begins.
+	if err = web.Serve(ctx, listener, engine, configInstance, reloader, services.Watch.Close); err != nil {
+		log.Println(err)
+	}
+
+	// the new requests have been refused, stop the gRPC api and write the pending changes of all projects.
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(config.Duration(configInstance.ApplicationConfig.ShutdownTimeout)):
+			grpcServer.Stop()
+		}
+	}
+	if err = store.Close(); err != nil {
+		log.Println(err)
+	}
+	if err = services.Close(); err != nil {
+		log.Println(err)
+	}
 }
@@ -0,0 +1,246 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/uberate/i18n/api/i18n/v1"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/bundle"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"os"
+	"strings"
+)
+
+// GetMessage return the value of the language and scopes, the MissingHandler is not invoked.
+func (s *Service) GetMessage(ctx context.Context, request *i18nv1.GetMessageRequest) (*i18nv1.Message, error) {
+	language := catalog.LanguageValue(s.i18n, request.GetLanguage())
+	if err := validateMessage(request.GetScopes(), language, ""); err != nil {
+		return nil, err
+	}
+	value, ok := s.i18n.Lookup(language, request.GetScopes()...)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "message not found")
+	}
+	return &i18nv1.Message{Scopes: request.GetScopes(), Language: language, Value: value}, nil
+}
+
+// BatchGetMessages return the values of all requests, an invalid request makes the whole batch fail.
+func (s *Service) BatchGetMessages(ctx context.Context, request *i18nv1.BatchGetMessagesRequest) (
+	*i18nv1.BatchGetMessagesResponse, error) {
+	res := &i18nv1.BatchGetMessagesResponse{}
+	for index, item := range request.GetRequests() {
+		language := catalog.LanguageValue(s.i18n, item.GetLanguage())
+		if err := validateMessage(item.GetScopes(), language, fmt.Sprintf("requests[%d].", index)); err != nil {
+			return nil, err
+		}
+		if value, ok := s.i18n.Lookup(language, item.GetScopes()...); ok {
+			res.Messages = append(res.Messages, &i18nv1.Message{Scopes: item.GetScopes(), Language: language, Value: value})
+		} else {
+			res.Missing = append(res.Missing, &i18nv1.GetMessageRequest{Scopes: item.GetScopes(), Language: language})
+		}
+	}
+	return res, nil
+}
+
+// UpsertMessage create or update the value, it is checked by the ACL and recorded in the audit log.
+func (s *Service) UpsertMessage(ctx context.Context, request *i18nv1.UpsertMessageRequest) (
+	*i18nv1.UpsertMessageResponse, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	message := request.GetMessage()
+	language := catalog.LanguageValue(s.i18n, message.GetLanguage())
+	if err := validateMessage(message.GetScopes(), language, "message."); err != nil {
+		return nil, err
+	}
+	if value := message.GetValue(); len(value) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "message.value: value is required")
+	}
+	if err := s.allowed(ctx, auth.PermissionWrite, language, message.GetScopes()); err != nil {
+		return nil, err
+	}
+
+	change, ok := s.pushMessage(ctx, language, message.GetValue(), message.GetScopes()...)
+	return &i18nv1.UpsertMessageResponse{
+		Message: &i18nv1.Message{Scopes: message.GetScopes(), Language: language, Value: message.GetValue()},
+		Created: ok && change.Type == provider.ChangeAdded,
+	}, nil
+}
+
+// DeleteMessage delete the value, it is checked by the ACL and recorded in the audit log.
+func (s *Service) DeleteMessage(ctx context.Context, request *i18nv1.DeleteMessageRequest) (
+	*i18nv1.DeleteMessageResponse, error) {
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+	language := catalog.LanguageValue(s.i18n, request.GetLanguage())
+	if err := validateMessage(request.GetScopes(), language, ""); err != nil {
+		return nil, err
+	}
+	if err := s.allowed(ctx, auth.PermissionDelete, language, request.GetScopes()); err != nil {
+		return nil, err
+	}
+
+	change, ok := s.pushMessage(ctx, language, "", request.GetScopes()...)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "message not found")
+	}
+	return &i18nv1.DeleteMessageResponse{
+		Message: &i18nv1.Message{Scopes: request.GetScopes(), Language: language, Value: change.OldValue},
+	}, nil
+}
+
+// ListLanguages return the language string values present in the instance.
+func (s *Service) ListLanguages(ctx context.Context, request *i18nv1.ListLanguagesRequest) (
+	*i18nv1.ListLanguagesResponse, error) {
+	return &i18nv1.ListLanguagesResponse{Standard: s.i18n.Standard, Languages: s.i18n.Languages()}, nil
+}
+
+// ExportBundle return the bundle of the language like the REST bundle api, the content is the JSON document.
+func (s *Service) ExportBundle(ctx context.Context, request *i18nv1.ExportBundleRequest) (
+	*i18nv1.ExportBundleResponse, error) {
+	language := catalog.LanguageValue(s.i18n, request.GetLanguage())
+	if len(language) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "language: language is required")
+	}
+
+	var fallbacks []string
+	if !request.GetNoFallback() {
+		fallbacks = catalog.FallbackLanguages(s.config, language)
+	}
+	res, ok, err := bundle.Build(s.i18n, request.GetFormat(), language, fallbacks, request.GetScopes()...)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, "scopes not found")
+	}
+	content, err := json.Marshal(res)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &i18nv1.ExportBundleResponse{Language: language, Content: content}, nil
+}
+
+// Watch stream the changes like the REST watch api. The stream ends with ABORTED when the client is too slow, it should
+// watch again from the epoch and the last revision it has received.
+func (s *Service) Watch(request *i18nv1.WatchRequest, stream i18nv1.I18NService_WatchServer) error {
+	filter := watch.Filter{Scopes: request.GetScopes()}
+	if language := request.GetLanguage(); len(language) != 0 {
+		filter.Language = catalog.LanguageValue(s.i18n, language)
+	}
+
+	subscription, replay, err := s.hub.Subscribe(filter, request.GetEpoch(), request.GetSince())
+	reset := err == watch.ErrResumeTooOld
+	if reset {
+		subscription, replay, _ = s.hub.Subscribe(filter, "", 0)
+	}
+	defer subscription.Cancel()
+
+	epoch, revision := s.hub.Epoch(), s.hub.Revision()
+	if reset {
+		event := &i18nv1.WatchEvent{Type: i18nv1.WatchEvent_TYPE_RESET, Revision: revision, Epoch: epoch}
+		if err = stream.Send(event); err != nil {
+			return err
+		}
+	}
+	for _, event := range replay {
+		if err = stream.Send(watchEvent(epoch, event)); err != nil {
+			return err
+		}
+	}
+	ready := &i18nv1.WatchEvent{Type: i18nv1.WatchEvent_TYPE_READY, Revision: revision, Epoch: epoch}
+	if err = stream.Send(ready); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return status.Error(codes.Aborted, "the client is too slow, watch again from the last revision")
+			}
+			if err = stream.Send(watchEvent(epoch, event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func watchEvent(epoch string, event watch.Event) *i18nv1.WatchEvent {
+	res := &i18nv1.WatchEvent{
+		Type:     i18nv1.WatchEvent_TYPE_UPSERT,
+		Scopes:   event.Scopes,
+		Language: event.Language,
+		Value:    event.Value,
+		Revision: event.Revision,
+		Epoch:    epoch,
+	}
+	if event.Type == watch.EventDelete {
+		res.Type = i18nv1.WatchEvent_TYPE_DELETE
+	}
+	return res
+}
+
+// writable return FAILED_PRECONDITION when the server is readonly.
+func (s *Service) writable() error {
+	if s.config.ApplicationConfig.Readonly {
+		return status.Error(codes.FailedPrecondition, "the server is readonly")
+	}
+	return nil
+}
+
+// allowed return PERMISSION_DENIED if the Principal of the context can't do the permission by the ACL.
+func (s *Service) allowed(ctx context.Context, permission auth.Permission, language string, scopes []string) error {
+	if s.authenticator.ACL().Allowed(principalFrom(ctx), permission, language, scopes...) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "no %s permission of %s[%s]",
+		permission, strings.Join(scopes, "."), language)
+}
+
+// pushMessage change the value and write the change to the audit log, the actor is the principal name. It returns
+// the Change and false if the value is not changed.
+func (s *Service) pushMessage(ctx context.Context, language, value string, scopes ...string) (provider.Change, bool) {
+	change, ok := s.i18n.Swap(language, value, scopes...)
+	if !ok {
+		return change, false
+	}
+
+	actor, requestID := "", ""
+	if principal := principalFrom(ctx); principal != nil {
+		actor = principal.Name
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(RequestIDMetadata)) != 0 {
+		requestID = md.Get(RequestIDMetadata)[0]
+	}
+	if err := s.auditLogger.Record(actor, requestID, change); err != nil {
+		fmt.Fprintln(os.Stderr, "audit:", err)
+	}
+	return change, true
+}
+
+// validateMessage return INVALID_ARGUMENT if the scopes or the language is empty, the field is the prefix of the
+// problem field.
+func validateMessage(scopes []string, language, field string) error {
+	if len(scopes) == 0 {
+		return status.Errorf(codes.InvalidArgument, "%sscopes: at least one scope is required", field)
+	}
+	for index, scope := range scopes {
+		if len(scope) == 0 {
+			return status.Errorf(codes.InvalidArgument, "%sscopes[%d]: empty scope", field, index)
+		}
+	}
+	if len(language) == 0 {
+		return status.Errorf(codes.InvalidArgument, "%slanguage: language is required", field)
+	}
+	return nil
+}
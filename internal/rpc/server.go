@@ -0,0 +1,130 @@
+// Package rpc is the gRPC api of the i18n server. It serves the same I18n instance as the REST api, and uses the same
+// authenticator, ACL, audit log and watch hub.
+package rpc
+
+import (
+	"context"
+	"github.com/uberate/i18n/api/i18n/v1"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/audit"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// RequestIDMetadata is the metadata key of the request id, it is recorded in the audit log like the REST api.
+const RequestIDMetadata = "x-request-id"
+
+// methodScopes is the scope which every method requires, the unknown methods require ScopeAdmin.
+var methodScopes = map[string]auth.Scope{
+	"/i18n.v1.I18nService/GetMessage":       auth.ScopeRead,
+	"/i18n.v1.I18nService/BatchGetMessages": auth.ScopeRead,
+	"/i18n.v1.I18nService/ListLanguages":    auth.ScopeRead,
+	"/i18n.v1.I18nService/ExportBundle":     auth.ScopeRead,
+	"/i18n.v1.I18nService/Watch":            auth.ScopeRead,
+	"/i18n.v1.I18nService/UpsertMessage":    auth.ScopeWrite,
+	"/i18n.v1.I18nService/DeleteMessage":    auth.ScopeWrite,
+}
+
+// Service is the implementation of i18nv1.I18NServiceServer.
+type Service struct {
+	i18nv1.UnimplementedI18NServiceServer
+
+	config        config.I18nConfig
+	i18n          *provider.I18n
+	authenticator *auth.Authenticator
+	auditLogger   *audit.Logger
+	hub           *watch.Hub
+}
+
+// NewService return a *Service of the instance. The auditLogger can be nil, it means the audit log is disabled.
+func NewService(config config.I18nConfig, i18n *provider.I18n, authenticator *auth.Authenticator,
+	auditLogger *audit.Logger, hub *watch.Hub) *Service {
+	return &Service{
+		config:        config,
+		i18n:          i18n,
+		authenticator: authenticator,
+		auditLogger:   auditLogger,
+		hub:           hub,
+	}
+}
+
+// NewServer return a *grpc.Server which serves the Service, the methods are authenticated by the interceptors.
+func NewServer(service *Service, options ...grpc.ServerOption) *grpc.Server {
+	options = append(options,
+		grpc.ChainUnaryInterceptor(service.unaryInterceptor),
+		grpc.ChainStreamInterceptor(service.streamInterceptor))
+	server := grpc.NewServer(options...)
+	i18nv1.RegisterI18NServiceServer(server, service)
+	return server
+}
+
+type principalKey struct{}
+
+// principalFrom return the Principal which is set by the interceptors.
+func principalFrom(ctx context.Context) *auth.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*auth.Principal)
+	return principal
+}
+
+func (s *Service) unaryInterceptor(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (s *Service) streamInterceptor(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(server, &principalStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate return the context with the Principal of the metadata, the metadata 'x-api-key' and 'authorization'
+// are the same as the REST headers. It returns UNAUTHENTICATED or PERMISSION_DENIED like 401 and 403 of the REST api.
+func (s *Service) authenticate(ctx context.Context, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = auth.ScopeAdmin
+	}
+
+	request := &http.Request{Header: http.Header{}}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, key := range []string{"x-api-key", "authorization"} {
+		for _, value := range md.Get(key) {
+			request.Header.Add(key, value)
+		}
+	}
+
+	principal, err := s.authenticator.Principal(request)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !principal.Has(scope) {
+		if principal.Method == auth.MethodAnonymous {
+			return nil, status.Errorf(codes.Unauthenticated, "scope '%s' required", scope)
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "scope '%s' required", scope)
+	}
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+// principalStream is the grpc.ServerStream with the context of the Principal.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
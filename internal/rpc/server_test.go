@@ -0,0 +1,275 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/uberate/i18n/api/i18n/v1"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/audit"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestInstance() *provider.I18n {
+	instance := provider.NewI18n(provider.ISO6391)
+	p := instance.Pusher("user", "text", "login")
+	p(provider.EnglishLn, "Login")
+	p(provider.ChineseLn, "登录")
+	instance.Pusher("user", "text", "logout")(provider.EnglishLn, "Logout")
+	return instance
+}
+
+// newTestClient serve the Service by bufconn, and return a client of it.
+func newTestClient(t *testing.T, c config.I18nConfig, instance *provider.I18n) (i18nv1.I18NServiceClient,
+	*audit.Logger) {
+	authenticator, err := auth.New(c.ApplicationConfig.Auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditLogger, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := watch.NewHub(instance, 100, 16)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(NewService(c, instance, authenticator, auditLogger, hub))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+		hub.Close()
+		_ = auditLogger.Close()
+	})
+	return i18nv1.NewI18NServiceClient(conn), auditLogger
+}
+
+func TestService(t *testing.T) {
+	c := config.I18nConfig{}
+	c.ApplicationConfig.DefaultLanguage = "en"
+	instance := newTestInstance()
+	client, auditLogger := newTestClient(t, c, instance)
+	ctx := context.Background()
+
+	message, err := client.GetMessage(ctx, &i18nv1.GetMessageRequest{Scopes: []string{"user", "text", "login"},
+		Language: "chinese"})
+	if err != nil || message.GetValue() != "登录" || message.GetLanguage() != "zh" {
+		t.Errorf("GetMessage get: %v, %v, want: 登录[zh]", message, err)
+	}
+	_, err = client.GetMessage(ctx, &i18nv1.GetMessageRequest{Scopes: []string{"user", "none"}, Language: "en"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetMessage of missing value get: %v, want: NotFound", err)
+	}
+	_, err = client.GetMessage(ctx, &i18nv1.GetMessageRequest{Language: "en"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetMessage without scopes get: %v, want: InvalidArgument", err)
+	}
+
+	batch, err := client.BatchGetMessages(ctx, &i18nv1.BatchGetMessagesRequest{Requests: []*i18nv1.GetMessageRequest{
+		{Scopes: []string{"user", "text", "login"}, Language: "en"},
+		{Scopes: []string{"user", "text", "logout"}, Language: "zh"},
+	}})
+	if err != nil || len(batch.GetMessages()) != 1 || len(batch.GetMissing()) != 1 {
+		t.Errorf("BatchGetMessages get: %v, %v, want 1 message and 1 missing", batch, err)
+	}
+
+	upsert, err := client.UpsertMessage(ctx, &i18nv1.UpsertMessageRequest{Message: &i18nv1.Message{
+		Scopes: []string{"user", "text", "logout"}, Language: "zh", Value: "登出"}})
+	if err != nil || !upsert.GetCreated() {
+		t.Errorf("UpsertMessage get: %v, %v, want created", upsert, err)
+	}
+	if value, _ := instance.Lookup("zh", "user", "text", "logout"); value != "登出" {
+		t.Errorf("UpsertMessage value: %s, want: 登出", value)
+	}
+	upsert, err = client.UpsertMessage(ctx, &i18nv1.UpsertMessageRequest{Message: &i18nv1.Message{
+		Scopes: []string{"user", "text", "logout"}, Language: "zh", Value: "登出"}})
+	if err != nil || upsert.GetCreated() {
+		t.Errorf("UpsertMessage of the same value get: %v, %v, want not created", upsert, err)
+	}
+
+	deleted, err := client.DeleteMessage(ctx, &i18nv1.DeleteMessageRequest{Scopes: []string{"user", "text", "logout"},
+		Language: "en"})
+	if err != nil || deleted.GetMessage().GetValue() != "Logout" {
+		t.Errorf("DeleteMessage get: %v, %v, want the deleted value Logout", deleted, err)
+	}
+	_, err = client.DeleteMessage(ctx, &i18nv1.DeleteMessageRequest{Scopes: []string{"user", "text", "logout"},
+		Language: "en"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteMessage of missing value get: %v, want: NotFound", err)
+	}
+
+	entries, err := auditLogger.Query(audit.Filter{})
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Audit entries get: %v, %v, want 2 entries", entries, err)
+	}
+
+	languages, err := client.ListLanguages(ctx, &i18nv1.ListLanguagesRequest{})
+	if err != nil || !reflect.DeepEqual(languages.GetLanguages(), []string{"en", "zh"}) {
+		t.Errorf("ListLanguages get: %v, %v, want: [en zh]", languages, err)
+	}
+
+	exported, err := client.ExportBundle(ctx, &i18nv1.ExportBundleRequest{Language: "zh", Scopes: []string{"user"},
+		Format: "flat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := map[string]string{}
+	if err = json.Unmarshal(exported.GetContent(), &content); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"text.login": "登录", "text.logout": "登出"}
+	if !reflect.DeepEqual(content, want) {
+		t.Errorf("ExportBundle get: %v, want: %v", content, want)
+	}
+	_, err = client.ExportBundle(ctx, &i18nv1.ExportBundleRequest{Language: "zh", Format: "xml"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ExportBundle of unknown format get: %v, want: InvalidArgument", err)
+	}
+}
+
+func TestServiceAuth(t *testing.T) {
+	c := config.I18nConfig{}
+	c.ApplicationConfig.Auth = config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "reader", Key: "read-key", Scopes: []string{"read"}},
+			{Name: "vendor", Key: "vendor-key", Scopes: []string{"write"}},
+		},
+		ACL: []config.ACLRuleConfig{
+			{Principal: "vendor", Language: "ja", Scope: "user.text", Permission: "write"},
+		},
+	}
+	client, auditLogger := newTestClient(t, c, newTestInstance())
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key, RequestIDMetadata, "test")
+	}
+	upsert := func(ctx context.Context, language string, scopes ...string) error {
+		_, err := client.UpsertMessage(ctx, &i18nv1.UpsertMessageRequest{Message: &i18nv1.Message{
+			Scopes: scopes, Language: language, Value: "value"}})
+		return err
+	}
+
+	cases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"no credential", upsert(context.Background(), "ja", "user", "text", "help"), codes.Unauthenticated},
+		{"wrong key", upsert(withKey("wrong"), "ja", "user", "text", "help"), codes.Unauthenticated},
+		{"read scope", upsert(withKey("read-key"), "ja", "user", "text", "help"), codes.PermissionDenied},
+		{"vendor allowed", upsert(withKey("vendor-key"), "japanese", "user", "text", "help"), codes.OK},
+		{"vendor denied", upsert(withKey("vendor-key"), "en", "user", "text", "help"), codes.PermissionDenied},
+	}
+	for _, item := range cases {
+		if code := status.Code(item.err); code != item.code {
+			t.Errorf("%s get: %v, want: %s", item.name, item.err, item.code)
+		}
+	}
+
+	_, err := client.ListLanguages(withKey("read-key"), &i18nv1.ListLanguagesRequest{})
+	if err != nil {
+		t.Errorf("ListLanguages with read key get: %v", err)
+	}
+
+	entries, err := auditLogger.Query(audit.Filter{})
+	if err != nil || len(entries) != 1 || entries[0].Actor != "vendor" || entries[0].RequestID != "test" {
+		t.Errorf("Audit entries get: %v, %v, want 1 entry of vendor", entries, err)
+	}
+}
+
+func TestServiceReadonly(t *testing.T) {
+	c := config.I18nConfig{}
+	c.ApplicationConfig.Readonly = true
+	client, _ := newTestClient(t, c, newTestInstance())
+
+	_, err := client.DeleteMessage(context.Background(), &i18nv1.DeleteMessageRequest{
+		Scopes: []string{"user", "text", "login"}, Language: "en"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteMessage of readonly server get: %v, want: FailedPrecondition", err)
+	}
+}
+
+func TestServiceWatch(t *testing.T) {
+	instance := newTestInstance()
+	client, _ := newTestClient(t, config.I18nConfig{}, instance)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initial, err := client.Watch(ctx, &i18nv1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready, err := initial.Recv()
+	if err != nil || ready.GetType() != i18nv1.WatchEvent_TYPE_READY || len(ready.GetEpoch()) == 0 {
+		t.Fatalf("Watch get: %v, %v, want ready with the epoch", ready, err)
+	}
+
+	revision := instance.Revision()
+	instance.PushMessageByString("ja", "ログイン", "user", "text", "login")
+	instance.PushMessageByString("en", "Sign out", "user", "text", "logout")
+
+	stream, err := client.Watch(ctx, &i18nv1.WatchRequest{
+		Scopes: []string{"user", "text", "login"},
+		Since:  revision,
+		Epoch:  ready.GetEpoch(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive := func() *i18nv1.WatchEvent {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	if event := receive(); event.GetType() != i18nv1.WatchEvent_TYPE_UPSERT || event.GetValue() != "ログイン" {
+		t.Errorf("First event get: %v, want the replayed upsert", event)
+	}
+	if event := receive(); event.GetType() != i18nv1.WatchEvent_TYPE_READY || event.GetRevision() != revision+2 {
+		t.Errorf("Second event get: %v, want ready of revision %d", event, revision+2)
+	}
+
+	instance.PushMessageByString("en", "", "user", "text", "login")
+	if event := receive(); event.GetType() != i18nv1.WatchEvent_TYPE_DELETE || event.GetLanguage() != "en" {
+		t.Errorf("Live event get: %v, want the delete of en", event)
+	}
+
+	for _, request := range []*i18nv1.WatchRequest{
+		{Since: revision + 100, Epoch: ready.GetEpoch()},
+		{Since: revision, Epoch: "restarted"},
+	} {
+		reset, err := client.Watch(ctx, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event, err := reset.Recv(); err != nil || event.GetType() != i18nv1.WatchEvent_TYPE_RESET {
+			t.Errorf("Watch from %v get: %v, %v, want reset", request, event, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Package audit is the append-only log of the catalog mutations of the web server. The entries are stored as JSON
+// lines, and the file is rotated by size.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is one mutation of the catalog.
+type Entry struct {
+	Time      time.Time           `json:"time" yaml:"time"`
+	Actor     string              `json:"actor" yaml:"actor"`
+	RequestID string              `json:"request_id" yaml:"request_id"`
+	Type      provider.ChangeType `json:"type" yaml:"type"`
+	Language  string              `json:"language" yaml:"language"`
+	Scopes    []string            `json:"scopes" yaml:"scopes"`
+	OldValue  string              `json:"old_value,omitempty" yaml:"old_value,omitempty"`
+	NewValue  string              `json:"new_value,omitempty" yaml:"new_value,omitempty"`
+}
+
+// Filter is the query of the entries, the zero value of a field means no filter.
+type Filter struct {
+	// Scopes is the scope prefix.
+	Scopes   []string
+	Language string
+	Actor    string
+	// Since and Until is the time range, both of them are inclusive.
+	Since time.Time
+	Until time.Time
+	// Limit is the max count of the result.
+	Limit int
+}
+
+// Match return true if the entry matches the Filter.
+func (f Filter) Match(entry Entry) bool {
+	if len(f.Language) != 0 && f.Language != entry.Language {
+		return false
+	}
+	if len(f.Actor) != 0 && f.Actor != entry.Actor {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if len(entry.Scopes) < len(f.Scopes) {
+		return false
+	}
+	for index, scope := range f.Scopes {
+		if entry.Scopes[index] != scope {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger writes the entries to a JSON lines file. When the file is larger than MaxSize, it is renamed to 'file.1',
+// and the older backups are renamed to 'file.2', 'file.3' and so on, the backups more than MaxBackups are removed
+// unless MaxBackups <= 0.
+//
+// The Logger is thread-safe.
+type Logger struct {
+	lock       sync.Mutex
+	file       string
+	maxSize    int64
+	maxBackups int
+
+	writer *os.File
+	size   int64
+}
+
+// Open return a *Logger which appends to the file. If maxSize <= 0, the file is never rotated. If maxBackups <= 0, all
+// backups are kept, the log is append-only and no entry is ever removed.
+func Open(file string, maxSize int64, maxBackups int) (*Logger, error) {
+	l := &Logger{file: file, maxSize: maxSize, maxBackups: maxBackups}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write append the entries to the file.
+func (l *Logger) Write(entries ...Entry) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+			if err = l.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := l.writer.Write(line)
+		l.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record write the changes as entries of the actor and the request, the time of the entries is now. A nil Logger
+// records nothing, so the apis can record the changes without checking whether the audit log is enabled.
+func (l *Logger) Record(actor, requestID string, changes ...provider.Change) error {
+	if l == nil || len(changes) == 0 {
+		return nil
+	}
+	now := time.Now()
+	entries := make([]Entry, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, Entry{
+			Time:      now,
+			Actor:     actor,
+			RequestID: requestID,
+			Type:      change.Type,
+			Language:  change.Language,
+			Scopes:    change.Scopes,
+			OldValue:  change.OldValue,
+			NewValue:  change.NewValue,
+		})
+	}
+	return l.Write(entries...)
+}
+
+// Query return the entries which match the filter, the newest first.
+//
+// The files are opened in the lock and read without it, so a long query doesn't block the writes. An opened file is
+// still readable after it is rotated, and the current file is read to its size when it is opened. The entries are
+// appended in time order, so the files are read from the newest line, and the reading stops once the Limit entries
+// are found or an entry is older than the Since, a query costs the entries in its window instead of the whole history.
+func (l *Logger) Query(filter Filter) ([]Entry, error) {
+	l.lock.Lock()
+	names := l.files()
+	readers := make([]*os.File, 0, len(names))
+	defer func() {
+		for _, reader := range readers {
+			_ = reader.Close()
+		}
+	}()
+	for _, name := range names {
+		reader, err := os.Open(name)
+		if err != nil {
+			l.lock.Unlock()
+			return nil, err
+		}
+		readers = append(readers, reader)
+	}
+	size := l.size
+	l.lock.Unlock()
+
+	res := []Entry{}
+	done := false
+	for index := len(readers) - 1; index >= 0 && !done; index-- {
+		readerSize := size
+		if index != len(readers)-1 {
+			info, err := readers[index].Stat()
+			if err != nil {
+				return nil, err
+			}
+			readerSize = info.Size()
+		}
+		err := eachLineBackward(readers[index], readerSize, func(line []byte) (bool, error) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				return true, nil
+			}
+			entry := Entry{}
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return false, fmt.Errorf("%s: %v", names[index], err)
+			}
+			if !filter.Since.IsZero() && entry.Time.Before(filter.Since) {
+				done = true
+				return false, nil
+			}
+			if filter.Match(entry) {
+				res = append(res, entry)
+			}
+			done = filter.Limit > 0 && len(res) >= filter.Limit
+			return !done, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the entries of the concurrent writes can be out of order by their time.
+	sort.SliceStable(res, func(a, b int) bool {
+		return res[a].Time.After(res[b].Time)
+	})
+	return res, nil
+}
+
+// Close close the file.
+func (l *Logger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.writer.Close()
+}
+
+func (l *Logger) open() error {
+	writer, err := os.OpenFile(l.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := writer.Stat()
+	if err != nil {
+		_ = writer.Close()
+		return err
+	}
+	l.writer, l.size = writer, info.Size()
+	return nil
+}
+
+func (l *Logger) rotate() error {
+	if err := l.writer.Close(); err != nil {
+		return err
+	}
+
+	count := l.maxBackups
+	if count > 0 {
+		_ = os.Remove(l.backup(count))
+	} else {
+		count = l.backups() + 1
+	}
+	for index := count - 1; index > 0; index-- {
+		if err := os.Rename(l.backup(index), l.backup(index+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(l.file, l.backup(1)); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// files return the backups from the oldest, and the current file.
+func (l *Logger) files() []string {
+	var res []string
+	for index := l.backups(); index > 0; index-- {
+		if _, err := os.Stat(l.backup(index)); err == nil {
+			res = append(res, l.backup(index))
+		}
+	}
+	return append(res, l.file)
+}
+
+// backups return the count of the backups, it is maxBackups, or the count of the existing backups if all are kept.
+func (l *Logger) backups() int {
+	if l.maxBackups > 0 {
+		return l.maxBackups
+	}
+	count := 0
+	for {
+		if _, err := os.Stat(l.backup(count + 1)); err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+func (l *Logger) backup(index int) string {
+	return fmt.Sprintf("%s.%d", l.file, index)
+}
+
+// eachLineBackward invoke f with the lines of the first size bytes of the reader from the last one, until f return
+// false or an error.
+func eachLineBackward(reader io.ReaderAt, size int64, f func(line []byte) (bool, error)) error {
+	const chunk = 64 * 1024
+	// rest is the beginning of the read content, it is the end of a line which is not read completely.
+	var rest []byte
+	for offset := size; offset > 0; {
+		length := int64(chunk)
+		if offset < length {
+			length = offset
+		}
+		offset -= length
+		buffer := make([]byte, length, length+int64(len(rest)))
+		if _, err := reader.ReadAt(buffer, offset); err != nil && err != io.EOF {
+			return err
+		}
+		buffer = append(buffer, rest...)
+		for index := bytes.LastIndexByte(buffer, '\n'); index >= 0; index = bytes.LastIndexByte(buffer, '\n') {
+			if ok, err := f(buffer[index+1:]); err != nil || !ok {
+				return err
+			}
+			buffer = buffer[:index]
+		}
+		rest = buffer
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	_, err := f(rest)
+	return err
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := Open(file, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	begin := time.Now()
+	for index := 0; index < 10; index++ {
+		scopes := []string{"user", "text"}
+		if index%2 == 0 {
+			scopes = []string{"admin"}
+		}
+		if err = logger.Write(Entry{
+			Time:     begin.Add(time.Duration(index) * time.Second),
+			Actor:    "vendor",
+			Language: "ja",
+			Scopes:   scopes,
+			NewValue: "value",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = os.Stat(file + ".2"); err != nil {
+		t.Errorf("The file should be rotated: %v", err)
+	}
+	if _, err = os.Stat(file + ".3"); err == nil {
+		t.Error("The backups more than max backups should be removed")
+	}
+
+	entries, err := logger.Query(Filter{Scopes: []string{"user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) >= 5 {
+		t.Fatalf("Get %d entries, want some of the 5 user entries, the oldest are rotated out", len(entries))
+	}
+	if !entries[0].Time.Equal(begin.Add(9 * time.Second)) {
+		t.Errorf("The first entry should be the newest, get: %v", entries[0].Time)
+	}
+
+	entries, _ = logger.Query(Filter{Since: begin.Add(8 * time.Second), Limit: 1})
+	if len(entries) != 1 || len(entries[0].Scopes) != 2 {
+		t.Errorf("Get entries since: %v, want the last one", entries)
+	}
+}
+
+func TestLoggerConcurrentQuery(t *testing.T) {
+	logger, err := Open(filepath.Join(t.TempDir(), "audit.log"), 500, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for index := 0; index < 200; index++ {
+			if err := logger.Write(Entry{Time: time.Now(), Actor: "vendor", Scopes: []string{"user"}}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		// a query during the writes and the rotations reads complete entries only.
+		if _, err = logger.Query(Filter{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoggerKeepAll(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := Open(file, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	for index := 0; index < 10; index++ {
+		if err = logger.Write(Entry{Time: time.Now(), Actor: "vendor", Scopes: []string{"user"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = os.Stat(file + ".3"); err != nil {
+		t.Errorf("The rotated files should be kept: %v", err)
+	}
+	if entries, _ := logger.Query(Filter{}); len(entries) != 10 {
+		t.Errorf("Get %d entries, want all 10 entries", len(entries))
+	}
+}
+
+func TestLoggerQueryNewestFirst(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := Open(file, 100*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	begin := time.Now()
+	long := strings.Repeat("value ", 20*1024)
+	for index := 0; index < 6; index++ {
+		if err = logger.Write(Entry{Time: begin.Add(time.Duration(index) * time.Second), Actor: "vendor",
+			Scopes: []string{"user", strconv.Itoa(index)}, NewValue: long}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the oldest backup is broken, a query which is done by the newer files never reads it.
+	oldest := fmt.Sprintf("%s.%d", file, logger.backups())
+	if err = os.WriteFile(oldest, []byte("broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := logger.Query(Filter{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Scopes[1] != "5" || entries[1].Scopes[1] != "4" || entries[0].NewValue != long {
+		t.Errorf("Get the newest entries: %d, want the entries 5 and 4", len(entries))
+	}
+	if entries, err = logger.Query(Filter{Since: begin.Add(3 * time.Second)}); err != nil || len(entries) != 3 {
+		t.Errorf("Get the entries since: %d, %v, want 3 entries", len(entries), err)
+	}
+	if _, err = logger.Query(Filter{}); err == nil {
+		t.Error("Query of all entries should fail on the broken file")
+	}
+}
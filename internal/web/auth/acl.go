@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"strings"
+)
+
+// Permission is the kind of change which a Rule allows.
+type Permission string
+
+const (
+	PermissionWrite  Permission = "write"  // Create and update the values.
+	PermissionDelete Permission = "delete" // Delete the values.
+	PermissionAll    Permission = "*"      // All of above.
+)
+
+// Rule allows a principal to change the values of the Language under the Scopes prefix.
+type Rule struct {
+	Language   string     `json:"language" yaml:"language"`
+	Scopes     []string   `json:"scopes" yaml:"scopes"`
+	Permission Permission `json:"permission" yaml:"permission"`
+}
+
+// Match return true if the Rule allows the permission of the language and scopes.
+func (r Rule) Match(permission Permission, language string, scopes ...string) bool {
+	if r.Permission != PermissionAll && r.Permission != permission {
+		return false
+	}
+	if r.Language != "*" && len(r.Language) != 0 && !strings.EqualFold(r.Language, language) {
+		return false
+	}
+	if len(scopes) < len(r.Scopes) {
+		return false
+	}
+	for index, scope := range r.Scopes {
+		if scopes[index] != scope {
+			return false
+		}
+	}
+	return true
+}
+
+// PrincipalAll is the principal name of the rules which apply to every principal.
+const PrincipalAll = "*"
+
+// ACL is the rules of the principals, the key is the principal name.
+type ACL map[string][]Rule
+
+// NewACL return the ACL of the rules config.
+func NewACL(rules []config.ACLRuleConfig) (ACL, error) {
+	res := ACL{}
+	for index, item := range rules {
+		permission := Permission(item.Permission)
+		switch permission {
+		case PermissionWrite, PermissionDelete, PermissionAll:
+		default:
+			return nil, fmt.Errorf("auth.acl[%d]: permission should be in [write, delete, *]", index)
+		}
+		if len(item.Principal) == 0 {
+			return nil, fmt.Errorf("auth.acl[%d]: principal is required", index)
+		}
+
+		var scopes []string
+		if len(item.Scope) != 0 {
+			scopes = strings.Split(item.Scope, ".")
+		}
+		res[item.Principal] = append(res[item.Principal], Rule{
+			Language:   item.Language,
+			Scopes:     scopes,
+			Permission: permission,
+		})
+	}
+	return res, nil
+}
+
+// Allowed return true if the principal can do the permission on the language and scopes. An empty ACL allows every
+// principal, else a principal can only do what its rules or the rules of PrincipalAll match, so a principal which is
+// forgotten or misspelled in the rules is denied. An unrestricted principal needs the rule with permission '*'.
+func (a ACL) Allowed(principal *Principal, permission Permission, language string, scopes ...string) bool {
+	if principal == nil {
+		return false
+	}
+	if len(a) == 0 {
+		return true
+	}
+	for _, name := range []string{principal.Name, PrincipalAll} {
+		for _, rule := range a[name] {
+			if rule.Match(permission, language, scopes...) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+const aclKey = "i18n/auth/acl"
+
+// Allowed return true if the Principal of the request can do the permission on the language and scopes by the ACL of
+// the Authenticator. If the request is not authenticated by Authenticator.Require, it returns false.
+func Allowed(context *gin.Context, permission Permission, language string, scopes ...string) bool {
+	principal, ok := PrincipalFrom(context)
+	if !ok {
+		return false
+	}
+	value, ok := context.Get(aclKey)
+	if !ok {
+		return true
+	}
+	return value.(ACL).Allowed(principal, permission, language, scopes...)
+}
@@ -0,0 +1,257 @@
+// Package auth is the authentication and authorization of the i18n web server.
+//
+// A request can be authenticated by a static api key, an HMAC-signed token or the basic auth with an htpasswd file.
+// Every Principal has scopes, and the routes require a scope by Authenticator.Require.
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ErrorCodeUnauthorized = "unauthorized"
+	ErrorCodeForbidden    = "forbidden"
+)
+
+const realm = "i18n"
+
+// DefaultTokenTTL is the lifetime of the issued tokens when the config has no token_ttl. The tokens can't be revoked
+// except by rotating the secret, so they always expire.
+const DefaultTokenTTL = 24 * time.Hour
+
+var (
+	ErrNoCredential    = errors.New("no credential")
+	ErrBadCredential   = errors.New("bad credential")
+	ErrTokenDisabled   = errors.New("token is disabled")
+	ErrScopeNotAllowed = errors.New("scope not allowed")
+)
+
+// Authenticator authenticates the requests by the AuthConfig. It is safe for concurrent use.
+type Authenticator struct {
+	enabled    bool
+	keys       map[[sha256.Size]byte]*Principal
+	secret     []byte
+	ttl        time.Duration
+	htpasswd   Htpasswd
+	userScopes map[string][]Scope
+	anonymous  *Principal
+	acl        ACL
+}
+
+// New return an *Authenticator of the config, the htpasswd file is read once.
+func New(config config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:    config.Enabled,
+		keys:       map[[sha256.Size]byte]*Principal{},
+		secret:     []byte(config.TokenSecret),
+		ttl:        DefaultTokenTTL,
+		userScopes: map[string][]Scope{},
+	}
+	if !a.enabled {
+		return a, nil
+	}
+
+	for index, item := range config.APIKeys {
+		scopes, ok := parseScopes(item.Scopes)
+		if !ok || len(item.Key) == 0 || len(item.Name) == 0 {
+			return nil, fmt.Errorf("auth.api_keys[%d]: need name, key and scopes in [read, write, admin]", index)
+		}
+		a.keys[sha256.Sum256([]byte(item.Key))] = &Principal{Name: item.Name, Method: MethodAPIKey, Scopes: scopes}
+	}
+
+	if len(config.TokenTTL) != 0 {
+		ttl, err := time.ParseDuration(config.TokenTTL)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("auth.token_ttl: should be a positive duration, got '%s'", config.TokenTTL)
+		}
+		a.ttl = ttl
+	}
+
+	if len(config.HtpasswdFile) != 0 {
+		htpasswd, err := ReadHtpasswdFile(config.HtpasswdFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth.htpasswd_file: %v", err)
+		}
+		a.htpasswd = htpasswd
+	}
+	for user, values := range config.HtpasswdScopes {
+		scopes, ok := parseScopes(values)
+		if !ok {
+			return nil, fmt.Errorf("auth.htpasswd_scopes.%s: scopes should be in [read, write, admin]", user)
+		}
+		a.userScopes[user] = scopes
+	}
+
+	if len(config.AnonymousScopes) != 0 {
+		scopes, ok := parseScopes(config.AnonymousScopes)
+		if !ok {
+			return nil, fmt.Errorf("auth.anonymous_scopes: scopes should be in [read, write, admin]")
+		}
+		a.anonymous = &Principal{Name: MethodAnonymous, Method: MethodAnonymous, Scopes: scopes}
+	}
+
+	acl, err := NewACL(config.ACL)
+	if err != nil {
+		return nil, err
+	}
+	a.acl = acl
+	return a, nil
+}
+
+// Enabled return false if all requests are allowed.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate return the Principal of the request. If the request has no credential, return ErrNoCredential. If the
+// credential is wrong, return ErrBadCredential or the token error.
+func (a *Authenticator) Authenticate(request *http.Request) (*Principal, error) {
+	if key := request.Header.Get("X-API-Key"); len(key) != 0 {
+		return a.apiKey(key)
+	}
+
+	authorization := request.Header.Get("Authorization")
+	if len(authorization) == 0 {
+		return nil, ErrNoCredential
+	}
+	if credential, ok := cutPrefixFold(authorization, "Bearer "); ok {
+		if IsToken(credential) {
+			return a.token(credential)
+		}
+		return a.apiKey(credential)
+	}
+	if _, ok := cutPrefixFold(authorization, "Basic "); ok {
+		user, password, _ := request.BasicAuth()
+		if a.htpasswd == nil || !a.htpasswd.Verify(user, password) {
+			return nil, ErrBadCredential
+		}
+		scopes, ok := a.userScopes[user]
+		if !ok {
+			scopes = []Scope{ScopeRead}
+		}
+		return &Principal{Name: user, Method: MethodBasic, Scopes: scopes}, nil
+	}
+	return nil, ErrBadCredential
+}
+
+// Principal like Authenticate, but the request without any credential is the anonymous Principal when the anonymous
+// scopes are configured. If the Authenticator is disabled, every request is an anonymous Principal with ScopeAdmin.
+func (a *Authenticator) Principal(request *http.Request) (*Principal, error) {
+	if !a.enabled {
+		return &Principal{Name: MethodAnonymous, Method: MethodAnonymous, Scopes: []Scope{ScopeAdmin}}, nil
+	}
+	principal, err := a.Authenticate(request)
+	if err == ErrNoCredential && a.anonymous != nil {
+		return a.anonymous, nil
+	}
+	return principal, err
+}
+
+// ACL return the ACL of the config, it is nil when the Authenticator is disabled.
+func (a *Authenticator) ACL() ACL {
+	return a.acl
+}
+
+// Require return a middleware which aborts the request with 401 when the request has no valid credential, or 403 when
+// the Principal hasn't the scope. The Principal can be got by PrincipalFrom in the next handlers, and the ACL is
+// checked by Allowed.
+//
+// If the Authenticator is disabled, every request is an anonymous Principal with ScopeAdmin.
+func (a *Authenticator) Require(scope Scope) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if !a.enabled {
+			principal, _ := a.Principal(context.Request)
+			context.Set(principalKey, principal)
+			context.Next()
+			return
+		}
+
+		principal, err := a.Principal(context.Request)
+		if err != nil {
+			a.unauthorized(context, err.Error())
+			return
+		}
+		if !principal.Has(scope) {
+			if principal.Method == MethodAnonymous {
+				a.unauthorized(context, fmt.Sprintf("scope '%s' required", scope))
+				return
+			}
+			context.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    ErrorCodeForbidden,
+				"message": fmt.Sprintf("scope '%s' required", scope),
+			})
+			return
+		}
+
+		context.Set(principalKey, principal)
+		context.Set(aclKey, a.acl)
+		context.Next()
+	}
+}
+
+// Issue return a new token of the issuer with the scopes. The subject of the token is the name of the issuer, so a
+// token never gains the ACL rules or the audit actor of another principal. If ttl is 0, the default ttl of config is
+// used. A token with higher scopes than the issuer is not allowed, return ErrScopeNotAllowed.
+func (a *Authenticator) Issue(issuer *Principal, scopes []Scope, ttl time.Duration) (string, *Claims, error) {
+	if len(a.secret) == 0 {
+		return "", nil, ErrTokenDisabled
+	}
+	for _, scope := range scopes {
+		if !issuer.Has(scope) {
+			return "", nil, ErrScopeNotAllowed
+		}
+	}
+
+	if ttl == 0 {
+		ttl = a.ttl
+	}
+	claims := &Claims{Subject: issuer.Name, Scopes: scopes}
+	if ttl > 0 {
+		claims.ExpiresAt = time.Now().Add(ttl).Unix()
+	}
+	token, err := IssueToken(a.secret, *claims)
+	return token, claims, err
+}
+
+func (a *Authenticator) apiKey(key string) (*Principal, error) {
+	if principal, ok := a.keys[sha256.Sum256([]byte(key))]; ok {
+		return principal, nil
+	}
+	return nil, ErrBadCredential
+}
+
+func (a *Authenticator) token(token string) (*Principal, error) {
+	if len(a.secret) == 0 {
+		return nil, ErrTokenDisabled
+	}
+	claims, err := ParseToken(a.secret, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{Name: claims.Subject, Method: MethodToken, Scopes: claims.Scopes}, nil
+}
+
+func (a *Authenticator) unauthorized(context *gin.Context, message string) {
+	context.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, realm))
+	if a.htpasswd != nil {
+		context.Writer.Header().Add("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, realm))
+	}
+	context.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"code":    ErrorCodeUnauthorized,
+		"message": message,
+	})
+}
+
+func cutPrefixFold(value, prefix string) (string, bool) {
+	if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(value[len(prefix):]), true
+}
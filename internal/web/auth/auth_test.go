@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"github.com/uberate/i18n/cmd/web/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToken(t *testing.T) {
+	secret := []byte("secret")
+	token, err := IssueToken(secret, Claims{Subject: "ci", Scopes: []Scope{ScopeWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsToken(token) {
+		t.Errorf("Token [%s] should be a token", token)
+	}
+
+	claims, err := ParseToken(secret, token)
+	if err != nil || claims.Subject != "ci" || len(claims.Scopes) != 1 || claims.Scopes[0] != ScopeWrite {
+		t.Errorf("Parse token get: %v, %v", claims, err)
+	}
+	if _, err = ParseToken([]byte("other"), token); err != ErrInvalidToken {
+		t.Errorf("Parse token with wrong secret get: %v, want: %v", err, ErrInvalidToken)
+	}
+
+	expired, _ := IssueToken(secret, Claims{Subject: "ci", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	if _, err = ParseToken(secret, expired); err != ErrExpiredToken {
+		t.Errorf("Parse expired token get: %v, want: %v", err, ErrExpiredToken)
+	}
+
+	authenticator, err := New(config.AuthConfig{Enabled: true, TokenSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &Principal{Name: "owner", Scopes: []Scope{ScopeAdmin}}
+	if _, claims, _ = authenticator.Issue(issuer, []Scope{ScopeRead}, 0); claims.Subject != "owner" ||
+		claims.ExpiresAt == 0 ||
+		claims.ExpiresAt > time.Now().Add(DefaultTokenTTL).Unix() {
+		t.Errorf("Get token without ttl of config: %+v, want the owner token expires in %s", claims, DefaultTokenTTL)
+	}
+}
+
+func TestHtpasswd(t *testing.T) {
+	// The passwords are 'password'.
+	htpasswd, err := ReadHtpasswd(strings.NewReader(`
+# users
+alice:$2a$05$KqUSEp1PuLMGajO1UbInouTrjd0/LnqQzHQJWelMu4veK3AncKcBa
+bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !htpasswd.Verify("alice", "password") || !htpasswd.Verify("bob", "password") {
+		t.Error("Verify right password should be true")
+	}
+	if htpasswd.Verify("alice", "wrong") || htpasswd.Verify("carol", "password") {
+		t.Error("Verify wrong password or unknown user should be false")
+	}
+
+	if _, err = ReadHtpasswd(strings.NewReader("dave:$apr1$abc$def")); err == nil {
+		t.Error("Unsupported hash should be an error")
+	}
+}
+
+func TestScope(t *testing.T) {
+	principal := &Principal{Scopes: []Scope{ScopeWrite}}
+	if !principal.Has(ScopeRead) || !principal.Has(ScopeWrite) || principal.Has(ScopeAdmin) {
+		t.Errorf("Scopes of write principal are wrong")
+	}
+	if principal.Has(Scope("unknown")) {
+		t.Errorf("Unknown scope should not be contained")
+	}
+}
+
+func TestACL(t *testing.T) {
+	acl, err := NewACL([]config.ACLRuleConfig{
+		{Principal: "vendor", Language: "ja", Scope: "user.text", Permission: "write"},
+		{Principal: "vendor", Language: "*", Scope: "legacy", Permission: "*"},
+		{Principal: "owner", Permission: "*"},
+		{Principal: PrincipalAll, Language: "en", Scope: "draft", Permission: "write"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vendor, owner, other := &Principal{Name: "vendor"}, &Principal{Name: "owner"}, &Principal{Name: "other"}
+	cases := []struct {
+		principal  *Principal
+		permission Permission
+		language   string
+		scopes     []string
+		want       bool
+	}{
+		{vendor, PermissionWrite, "ja", []string{"user", "text", "login"}, true},
+		{vendor, PermissionDelete, "ja", []string{"user", "text", "login"}, false},
+		{vendor, PermissionWrite, "zh", []string{"user", "text", "login"}, false},
+		{vendor, PermissionWrite, "ja", []string{"user", "title"}, false},
+		{vendor, PermissionDelete, "zh", []string{"legacy", "a"}, true},
+		{owner, PermissionDelete, "zh", []string{"user"}, true},
+		{other, PermissionDelete, "zh", []string{"user"}, false},
+		{other, PermissionWrite, "en", []string{"draft", "a"}, true},
+		{vendor, PermissionWrite, "en", []string{"draft", "a"}, true},
+		{nil, PermissionWrite, "ja", []string{"user", "text"}, false},
+	}
+	for _, item := range cases {
+		if got := acl.Allowed(item.principal, item.permission, item.language, item.scopes...); got != item.want {
+			t.Errorf("Allowed(%v, %s, %s, %v) get: %v, want: %v",
+				item.principal, item.permission, item.language, item.scopes, got, item.want)
+		}
+	}
+
+	if _, err = NewACL([]config.ACLRuleConfig{{Principal: "vendor", Permission: "read"}}); err == nil {
+		t.Error("Unknown permission should be an error")
+	}
+	if !(ACL{}).Allowed(other, PermissionDelete, "zh", "user") {
+		t.Error("An empty ACL should allow every principal")
+	}
+}
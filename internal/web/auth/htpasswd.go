@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Htpasswd is the users of an htpasswd file, the bcrypt ('$2y$', '$2a$', '$2b$') and SHA1 ('{SHA}') hashes are
+// supported.
+type Htpasswd map[string]string
+
+// ReadHtpasswdFile read the htpasswd file.
+func ReadHtpasswdFile(file string) (Htpasswd, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ReadHtpasswd(reader)
+}
+
+// ReadHtpasswd read the htpasswd lines like 'user:hash', the empty lines and the lines start with '#' are ignored.
+func ReadHtpasswd(reader io.Reader) (Htpasswd, error) {
+	res := Htpasswd{}
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		index := strings.Index(line, ":")
+		if index <= 0 {
+			return nil, fmt.Errorf("htpasswd line %d: want 'user:hash'", number)
+		}
+		user, hash := line[:index], line[index+1:]
+		if !isBcrypt(hash) && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd line %d: unsupported hash of user %s, use bcrypt or SHA1", number, user)
+		}
+		res[user] = hash
+	}
+	return res, scanner.Err()
+}
+
+// Verify return true if the password of the user is right.
+func (h Htpasswd) Verify(user, password string) bool {
+	hash, ok := h[user]
+	if !ok {
+		return false
+	}
+	if isBcrypt(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	want := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(want)) == 1
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2y$") || strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$")
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Scope is the permission level of a Principal. A higher Scope contains the lower ones: ScopeAdmin > ScopeWrite >
+// ScopeRead.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid return true if the Scope is known.
+func (s Scope) Valid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+// Contains return true if current Scope contains the target Scope.
+func (s Scope) Contains(target Scope) bool {
+	return scopeLevels[s] >= scopeLevels[target] && target.Valid()
+}
+
+const (
+	MethodAnonymous = "anonymous"
+	MethodAPIKey    = "api_key"
+	MethodToken     = "token"
+	MethodBasic     = "basic"
+)
+
+// Principal is the identity of a request.
+type Principal struct {
+	Name   string  `json:"name" yaml:"name"`
+	Method string  `json:"method" yaml:"method"`
+	Scopes []Scope `json:"scopes" yaml:"scopes"`
+}
+
+// Has return true if the Principal has the Scope.
+func (p *Principal) Has(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, item := range p.Scopes {
+		if item.Contains(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+const principalKey = "i18n/auth/principal"
+
+// PrincipalFrom return the Principal of the request which is set by Authenticator.Require.
+func PrincipalFrom(context *gin.Context) (*Principal, bool) {
+	value, ok := context.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+func parseScopes(values []string) ([]Scope, bool) {
+	res := make([]Scope, 0, len(values))
+	for _, value := range values {
+		scope := Scope(value)
+		if !scope.Valid() {
+			return nil, false
+		}
+		res = append(res, scope)
+	}
+	return res, true
+}
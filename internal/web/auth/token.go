@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+const tokenPrefix = "i18n"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims is the payload of a signed token. The ExpiresAt is the unix seconds, 0 means never expire.
+type Claims struct {
+	Subject   string  `json:"sub" yaml:"sub"`
+	Scopes    []Scope `json:"scopes" yaml:"scopes"`
+	ExpiresAt int64   `json:"exp,omitempty" yaml:"exp,omitempty"`
+}
+
+// IssueToken return a token signed by HMAC-SHA256 with the secret, the format is 'i18n.<payload>.<signature>', both
+// payload and signature are base64 url encoded.
+func IssueToken(secret []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return tokenPrefix + "." + encoded + "." + sign(secret, encoded), nil
+}
+
+// ParseToken verify the token by the secret and return the Claims.
+func ParseToken(secret []byte, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenPrefix {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(secret, parts[1]))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &Claims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return claims, nil
+}
+
+// IsToken return true if the value looks like a token issued by IssueToken.
+func IsToken(value string) bool {
+	return strings.HasPrefix(value, tokenPrefix+".") && strings.Count(value, ".") == 2
+}
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
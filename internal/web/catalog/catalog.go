@@ -0,0 +1,44 @@
+// Package catalog is the catalog logic which is shared by the REST api and the gRPC api, so both of them resolve the
+// languages and the fallbacks the same way.
+package catalog
+
+import (
+	"fmt"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/negotiate"
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+)
+
+// LanguageValue return the language string value of the instance Standard. The name can be the custom name of a known
+// LanguageKey like 'english', or the language string value like 'en'.
+func LanguageValue(i18n *provider.I18n, name string) string {
+	if lk, ok := provider.Mapper[strings.ToLower(name)]; ok {
+		return lk.Lower(i18n.Standard)
+	}
+	return name
+}
+
+// FallbackLanguages return the fallback chain of the language from config, the default language is the last one.
+func FallbackLanguages(config config.I18nConfig, ln string) []string {
+	var res []string
+	res = append(res, config.ApplicationConfig.LanguageFallbacks[negotiate.Normalize(ln)]...)
+	if len(config.ApplicationConfig.DefaultLanguage) != 0 {
+		res = append(res, config.ApplicationConfig.DefaultLanguage)
+	}
+	return res
+}
+
+// FallbackHandler return a MissingHandler which tries the fallback languages of the missed language from config in
+// order, see FallbackLanguages.
+func FallbackHandler(config config.I18nConfig, i18n *provider.I18n) provider.MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		return provider.FallbackLanguageHandler(i18n, FallbackLanguages(config, ln)...)(ln, scopes...)
+	}
+}
+
+// Placeholder return the placeholder of a missed message like 'en_user/text/login', it is the value of a missed
+// message when the server is not configured to respond 404.
+func Placeholder(ln string, scopes ...string) string {
+	return fmt.Sprintf("%s_%s", ln, strings.Join(scopes, "/"))
+}
@@ -0,0 +1,103 @@
+// Package certs is the TLS certificate of the web server, it is reloaded when the files are rotated, so the server
+// doesn't need a restart to serve a new certificate.
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader keeps the certificate of the cert and key files, it is safe for concurrent use.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	lock        sync.RWMutex
+	certificate *tls.Certificate
+	certPEM     []byte
+	keyPEM      []byte
+}
+
+// New return a *Reloader of the cert and key files in PEM, it returns an error if they are not a valid key pair.
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload read the files again, and use the new certificate if the files are changed. It returns false if nothing is
+// changed. If the new files are not a valid key pair, like the cert has been rotated but the key hasn't, the current
+// certificate is kept and the error is returned.
+func (r *Reloader) Reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.lock.RLock()
+	same := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.lock.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.certificate, r.certPEM, r.keyPEM = &certificate, certPEM, keyPEM
+	return true, nil
+}
+
+// GetCertificate return the current certificate, it is the tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.certificate, nil
+}
+
+// TLSConfig return a *tls.Config which serves the current certificate.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Watch reload the files on every interval until the cancel func is invoked. The errors of the reloads are passed to
+// onError, it can be nil.
+func (r *Reloader) Watch(interval time.Duration, onError func(err error)) (cancel func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := r.Reload(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair write a self-signed certificate of localhost with the serial number to the files.
+func writeKeyPair(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedSerial return the serial number of the certificate which the listener serves.
+func servedSerial(t *testing.T, addr string) int64 {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if _, err := New(certFile, keyFile); err == nil {
+		t.Error("New without the files should fail")
+	}
+	writeKeyPair(t, certFile, keyFile, 1)
+
+	reloader, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", reloader.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	if serial := servedSerial(t, listener.Addr().String()); serial != 1 {
+		t.Errorf("served serial get: %d, want: 1", serial)
+	}
+	if changed, err := reloader.Reload(); changed || err != nil {
+		t.Errorf("Reload without change get: %v, %v, want false", changed, err)
+	}
+
+	// the cert is rotated but the key isn't, the current certificate is kept.
+	writeKeyPair(t, certFile, filepath.Join(dir, "other.pem"), 2)
+	if _, err = reloader.Reload(); err == nil {
+		t.Error("Reload of a mismatched key pair should fail")
+	}
+	if serial := servedSerial(t, listener.Addr().String()); serial != 1 {
+		t.Errorf("served serial after failed reload get: %d, want: 1", serial)
+	}
+
+	writeKeyPair(t, certFile, keyFile, 3)
+	if changed, err := reloader.Reload(); !changed || err != nil {
+		t.Fatalf("Reload get: %v, %v, want true", changed, err)
+	}
+	if serial := servedSerial(t, listener.Addr().String()); serial != 3 {
+		t.Errorf("served serial after reload get: %d, want: 3", serial)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeKeyPair(t, certFile, keyFile, 1)
+	reloader, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel := reloader.Watch(10*time.Millisecond, nil)
+	defer cancel()
+
+	writeKeyPair(t, certFile, keyFile, 2)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		certificate, _ := reloader.GetCertificate(nil)
+		if leaf, err := x509.ParseCertificate(certificate.Certificate[0]); err == nil && leaf.SerialNumber.Int64() == 2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Watch should reload the rotated certificate")
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/audit"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	auditLoggerKey = "i18n/audit/logger"
+
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// Audit return a middleware which makes the mutations of the request recorded by the logger. A nil logger disables
+// the audit log.
+func Audit(logger *audit.Logger) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if logger != nil {
+			context.Set(auditLoggerKey, logger)
+		}
+		context.Next()
+	}
+}
+
+// AuditList return the audit entries, the newest first. The queries are 'scope' (the scope prefix joined by '.'),
+// 'language', 'actor', 'since' and 'until' (RFC 3339 time) and 'limit'.
+func AuditList(config config.I18nConfig, i18n *provider.I18n, logger *audit.Logger) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		filter := audit.Filter{
+			Language: context.Query("language"),
+			Actor:    context.Query("actor"),
+			Limit:    defaultAuditLimit,
+		}
+		if len(filter.Language) != 0 {
+			filter.Language = catalog.LanguageValue(i18n, filter.Language)
+		}
+		if scope := context.Query("scope"); len(scope) != 0 {
+			filter.Scopes = strings.Split(scope, ".")
+		}
+
+		var problems []ValidationError
+		for _, item := range []struct {
+			name   string
+			target *time.Time
+		}{{"since", &filter.Since}, {"until", &filter.Until}} {
+			value := context.Query(item.name)
+			if len(value) == 0 {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				problems = append(problems, ValidationError{Field: item.name, Message: "should be a RFC 3339 time"})
+				continue
+			}
+			*item.target = parsed
+		}
+		if value := context.Query("limit"); len(value) != 0 {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > maxAuditLimit {
+				problems = append(problems, ValidationError{
+					Field:   "limit",
+					Message: fmt.Sprintf("should be in [1, %d]", maxAuditLimit),
+				})
+			}
+			filter.Limit = limit
+		}
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query", problems)
+			return
+		}
+
+		entries, err := logger.Query(filter)
+		if err != nil {
+			abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+			return
+		}
+		context.JSON(http.StatusOK, entries)
+	}
+}
+
+// pushMessage push the value to i18n, and record the change to the audit log of the request. It returns the Change and
+// false if the value is not changed.
+func pushMessage(context *gin.Context, i18n *provider.I18n, ln, value string, scopes ...string) (provider.Change, bool) {
+	change, ok := i18n.Swap(ln, value, scopes...)
+	if ok {
+		recordChanges(context, change)
+	}
+	return change, ok
+}
+
+// createMessage like pushMessage, but the value is only added when it doesn't exist, see provider.I18n.SwapIfAbsent.
+func createMessage(context *gin.Context, i18n *provider.I18n, ln, value string, scopes ...string) (provider.Change,
+	bool) {
+	change, ok := i18n.SwapIfAbsent(ln, value, scopes...)
+	if ok {
+		recordChanges(context, change)
+	}
+	return change, ok
+}
+
+// recordChanges write the changes to the audit log of the request, the actor is the principal name.
+func recordChanges(context *gin.Context, changes ...provider.Change) {
+	value, ok := context.Get(auditLoggerKey)
+	if !ok {
+		return
+	}
+
+	actor := ""
+	if principal, ok := auth.PrincipalFrom(context); ok {
+		actor = principal.Name
+	}
+	if err := value.(*audit.Logger).Record(actor, requestID(context), changes...); err != nil {
+		fmt.Fprintln(gin.DefaultErrorWriter, "audit:", err)
+		_ = context.Error(err)
+	}
+}
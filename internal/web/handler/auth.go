@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TokenRequest is the request of a new token. The Subject is the principal name of the token, it can only be the name
+// of the issuer, empty means the issuer. The TTL is like '1h', empty means the default ttl of config.
+type TokenRequest struct {
+	Subject string       `json:"subject" yaml:"subject"`
+	Scopes  []auth.Scope `json:"scopes" yaml:"scopes"`
+	TTL     string       `json:"ttl" yaml:"ttl"`
+}
+
+// TokenResponse is the issued token and its claims.
+type TokenResponse struct {
+	Token  string      `json:"token" yaml:"token"`
+	Claims auth.Claims `json:"claims" yaml:"claims"`
+}
+
+// Whoami return the Principal of the request.
+func Whoami(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		principal, _ := auth.PrincipalFrom(context)
+		context.JSON(http.StatusOK, principal)
+	}
+}
+
+// TokenCreate issue a new HMAC-signed token of the issuer. The scopes of the token can't be higher than the issuer.
+func TokenCreate(config config.I18nConfig, i18n *provider.I18n, authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		request := TokenRequest{}
+		if err := context.ShouldBindJSON(&request); err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+
+		var details []ValidationError
+		if len(request.Scopes) == 0 {
+			details = append(details, ValidationError{Field: "scopes", Message: "at least one scope is required"})
+		}
+		for _, scope := range request.Scopes {
+			if !scope.Valid() {
+				details = append(details, ValidationError{Field: "scopes", Message: "unknown scope: " + string(scope)})
+			}
+		}
+		var ttl time.Duration
+		if len(request.TTL) != 0 {
+			var err error
+			if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl <= 0 {
+				details = append(details, ValidationError{Field: "ttl", Message: "should be a positive duration like '1h'"})
+			}
+		}
+		if len(details) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid token request", details)
+			return
+		}
+
+		issuer, _ := auth.PrincipalFrom(context)
+		if len(request.Subject) != 0 && request.Subject != issuer.Name {
+			abortWithError(context, http.StatusForbidden, auth.ErrorCodeForbidden, "a token can only be issued for the issuer",
+				[]ValidationError{{Field: "subject", Message: "should be " + issuer.Name}})
+			return
+		}
+
+		token, claims, err := authenticator.Issue(issuer, request.Scopes, ttl)
+		switch err {
+		case nil:
+			context.JSON(http.StatusCreated, TokenResponse{Token: token, Claims: *claims})
+		case auth.ErrScopeNotAllowed:
+			abortWithError(context, http.StatusForbidden, auth.ErrorCodeForbidden, err.Error(), nil)
+		case auth.ErrTokenDisabled:
+			abortWithError(context, http.StatusNotImplemented, ErrorCodeInvalidRequest, err.Error(), nil)
+		default:
+			abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+		}
+	}
+}
+
+// allowed return true if the principal of the request can do the permission on the value by the ACL, else the request
+// is aborted with 403.
+func allowed(context *gin.Context, permission auth.Permission, language string, scopes []string) bool {
+	if auth.Allowed(context, permission, language, scopes...) {
+		return true
+	}
+	message := forbiddenMessage(permission, language, scopes)
+	abortWithError(context, http.StatusForbidden, auth.ErrorCodeForbidden, message, nil)
+	return false
+}
+
+func forbiddenMessage(permission auth.Permission, language string, scopes []string) string {
+	return fmt.Sprintf("no %s permission of %s[%s]", permission, strings.Join(scopes, "."), language)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/bundle"
+	"github.com/uberate/i18n/pkg/negotiate"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"strings"
+)
+
+// BundleGet return all messages of a language under the scopes in one object. The query 'format' is one of 'nested'
+// (default), 'flat' and 'i18next'. The missing values are filled by the fallback languages, unless the query
+// 'fallback' is 'false'.
+//
+// The response is compressed by gzip when the client accepts it.
+func BundleGet(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		ln := catalog.LanguageValue(i18n, context.Param("ln"))
+		scopes := scopesParam(context)
+
+		var fallbacks []string
+		if context.Query("fallback") != "false" {
+			fallbacks = catalog.FallbackLanguages(config, ln)
+		}
+
+		var scopeList []string
+		if len(scopes) != 0 {
+			scopeList = strings.Split(scopes, "/")
+		}
+		res, ok, err := bundle.Build(i18n, context.Query("format"), ln, fallbacks, scopeList...)
+		if err != nil {
+			context.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if !ok {
+			context.JSON(http.StatusNotFound, nil)
+			return
+		}
+
+		context.Header("Content-Language", ln)
+		writeJSON(context, http.StatusOK, res)
+	}
+}
+
+// writeJSON write the value as json, the body is compressed by gzip when the client accepts it.
+func writeJSON(context *gin.Context, status int, value interface{}) {
+	// the body depends on the Accept-Encoding even if it is not compressed, the shared caches should know it.
+	context.Writer.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(context.GetHeader("Accept-Encoding")) {
+		context.JSON(status, value)
+		return
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		_ = context.Error(err)
+		context.Status(http.StatusInternalServerError)
+		return
+	}
+
+	context.Header("Content-Encoding", "gzip")
+	context.Header("Content-Type", "application/json; charset=utf-8")
+	context.Status(status)
+	writer := gzip.NewWriter(context.Writer)
+	if _, err = writer.Write(body); err != nil {
+		_ = context.Error(err)
+	}
+	if err = writer.Close(); err != nil {
+		_ = context.Error(err)
+	}
+}
+
+// acceptsGzip return true if the Accept-Encoding header accepts gzip with a quality above 0, by the 'gzip' item or
+// else by the '*' item. The items are parsed like the Accept-Language header.
+func acceptsGzip(header string) bool {
+	wildcard := false
+	for _, item := range negotiate.ParseAcceptLanguage(header) {
+		switch item.Tag {
+		case "gzip", "x-gzip":
+			return item.Quality > 0
+		case negotiate.Wildcard:
+			wildcard = item.Quality > 0
+		}
+	}
+	return wildcard
+}
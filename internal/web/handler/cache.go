@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Conditional is a middleware for the read apis. It sets the ETag (from the checksum of the instance), Last-Modified
+// and Cache-Control headers, and responds 304 when the If-None-Match or If-Modified-Since header shows the client
+// cache is still fresh. The X-Catalog-Epoch and X-Catalog-Revision headers are where the watch of the hub resumes.
+//
+// The ETag is weak, because the same catalog can be encoded in different ways, like gzip. The vary is the request
+// headers which select the representation of the route, like 'Accept-Language'. They are added to the Vary header even
+// on 304, and their values are a part of the ETag, so a representation is never validated by the ETag of another.
+func Conditional(config config.I18nConfig, i18n *provider.I18n, hub *watch.Hub, vary ...string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		etag := fmt.Sprintf(`W/"%s%s"`, i18n.Checksum(), variant(context.Request, vary))
+		modifiedAt := i18n.ModifiedAt()
+
+		for _, name := range vary {
+			addVary(context.Writer.Header(), name)
+		}
+		context.Header("ETag", etag)
+		context.Header("X-Catalog-Epoch", hub.Epoch())
+		context.Header("X-Catalog-Revision", fmt.Sprint(i18n.Revision()))
+		if !modifiedAt.IsZero() {
+			context.Header("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+		}
+		if len(config.ApplicationConfig.CacheControl) != 0 {
+			context.Header("Cache-Control", config.ApplicationConfig.CacheControl)
+		}
+
+		if notModified(context.Request, etag, modifiedAt) {
+			context.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+		context.Next()
+	}
+}
+
+// variant return the suffix of the ETag from the values of the vary headers, it is empty without the vary headers.
+func variant(request *http.Request, vary []string) string {
+	if len(vary) == 0 {
+		return ""
+	}
+	hash := sha256.New()
+	for _, name := range vary {
+		fmt.Fprintf(hash, "%s\n", request.Header.Get(name))
+	}
+	return "-" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// addVary add the request header to the Vary header if it is not there.
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
+
+// notModified return true when the request cache is fresh. The If-Modified-Since is ignored when the If-None-Match
+// exists, see RFC 7232.
+func notModified(request *http.Request, etag string, modifiedAt time.Time) bool {
+	if ifNoneMatch := request.Header.Get("If-None-Match"); len(ifNoneMatch) != 0 {
+		for _, item := range strings.Split(ifNoneMatch, ",") {
+			item = strings.TrimSpace(item)
+			if item == "*" || strings.TrimPrefix(item, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := request.Header.Get("If-Modified-Since"); len(ifModifiedSince) != 0 && !modifiedAt.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !modifiedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// corsMethods is the default methods of the preflight response, the read apis.
+	corsMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+	// corsHeaders is the default request headers of the preflight response, the headers which the apis read.
+	corsHeaders = []string{"Accept-Language", "Authorization", "Content-Type", "If-Modified-Since", "If-None-Match",
+		"Last-Event-ID", "X-API-Key", RequestIDHeader}
+)
+
+// CORS return a middleware which adds the CORS headers to the responses of the allowed origins. A preflight request of
+// an allowed origin is answered with 204 before the auth, the other requests are handled as usual. The requests of the
+// other origins get no CORS header, so the browsers block them.
+func CORS(corsConfig config.CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	origins := map[string]bool{}
+	for _, origin := range corsConfig.AllowOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.TrimSuffix(strings.ToLower(origin), "/")] = true
+	}
+	methods, headers := corsConfig.AllowMethods, corsConfig.AllowHeaders
+	if len(methods) == 0 {
+		methods = corsMethods
+	}
+	if len(headers) == 0 {
+		headers = corsHeaders
+	}
+	maxAge := ""
+	if duration := config.Duration(corsConfig.MaxAge); duration > 0 {
+		maxAge = strconv.Itoa(int(duration.Seconds()))
+	}
+
+	return func(context *gin.Context) {
+		origin := context.GetHeader("Origin")
+		if len(origin) == 0 {
+			context.Next()
+			return
+		}
+		header := context.Writer.Header()
+		if !allowAll {
+			// the response differs by the origin, so a cache should not share it between the origins.
+			header.Add("Vary", "Origin")
+			if !origins[strings.ToLower(origin)] {
+				context.Next()
+				return
+			}
+		}
+
+		if allowAll {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if corsConfig.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if context.Request.Method == http.MethodOptions && len(context.GetHeader("Access-Control-Request-Method")) != 0 {
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			if len(maxAge) != 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			context.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if len(corsConfig.ExposeHeaders) != 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(corsConfig.ExposeHeaders, ", "))
+		}
+		context.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	ErrorCodeInvalidRequest   = "invalid_request"
+	ErrorCodeValidationFailed = "validation_failed"
+	ErrorCodeNotFound         = "not_found"
+	ErrorCodeConflict         = "conflict"
+	ErrorCodeNotAcceptable    = "not_acceptable"
+	ErrorCodeInternal         = "internal"
+)
+
+// ErrorResponse is the structured error body of the v2 api.
+type ErrorResponse struct {
+	Code    string      `json:"code" yaml:"code"`
+	Message string      `json:"message" yaml:"message"`
+	Details interface{} `json:"details,omitempty" yaml:"details,omitempty"`
+}
+
+// abortWithError abort the request and write an ErrorResponse.
+func abortWithError(context *gin.Context, status int, code, message string, details interface{}) {
+	context.AbortWithStatusJSON(status, ErrorResponse{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// NotFound return a handler which responds 404 with an ErrorResponse, it handles the unknown routes.
+func NotFound() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, "api not found", nil)
+	}
+}
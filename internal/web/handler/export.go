@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"strings"
+)
+
+// defaultExportFormat is the format of the export when the client accepts any format.
+const defaultExportFormat = ".json"
+
+// Export return the values under the scope prefix as a catalog file, the values keep their full scopes so the file can
+// be loaded or imported as is. The queries are 'scope' (the scope prefix joined by '.'), 'language' (only the values of
+// it) and 'format' (the file extension like 'json' or '.json'). When the format is not set, it is chosen by the Accept
+// header, json by default.
+func Export(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		addVary(context.Writer.Header(), "Accept")
+		ext, ok := exportFormat(context)
+		if !ok {
+			return
+		}
+
+		var scopes []string
+		if scope := context.Query("scope"); len(scope) != 0 {
+			scopes = strings.Split(scope, ".")
+		}
+		language := context.Query("language")
+		if len(language) != 0 {
+			language = catalog.LanguageValue(i18n, language)
+		}
+
+		res := provider.NewI18n(i18n.Standard)
+		found := i18n.WalkMessageUnder(scopes, func(message map[string]string, flags ...string) {
+			for ln, value := range message {
+				if len(language) == 0 || strings.EqualFold(ln, language) {
+					res.PushMessageByString(ln, value, append(append([]string{}, scopes...), flags...)...)
+				}
+			}
+		})
+		if !found {
+			abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, "scope not found", nil)
+			return
+		}
+
+		encode, _ := files.Encoder(ext)
+		body := &bytes.Buffer{}
+		if err := encode(body, res); err != nil {
+			abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+			return
+		}
+		name := "i18n"
+		if len(scopes) != 0 {
+			name = strings.Join(scopes, ".")
+		}
+		context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, name, ext))
+		context.Data(http.StatusOK, files.ContentType(ext), body.Bytes())
+	}
+}
+
+// exportFormat return the file extension of the export format, or abort the request if the format is not supported.
+func exportFormat(context *gin.Context) (string, bool) {
+	if format := context.Query("format"); len(format) != 0 {
+		ext := "." + strings.TrimPrefix(strings.ToLower(format), ".")
+		if _, ok := files.Encoder(ext); !ok {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest,
+				fmt.Sprintf("unsupported format, should be one of %s", strings.Join(files.Exts(), ", ")), nil)
+			return "", false
+		}
+		return ext, true
+	}
+
+	accept := context.GetHeader("Accept")
+	if len(accept) == 0 {
+		return defaultExportFormat, true
+	}
+	// the media types are tried in order, the quality is not considered.
+	for _, item := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(item, ";", 2)[0])
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return defaultExportFormat, true
+		}
+		if ext, ok := files.ExtOf(mediaType); ok {
+			return ext, true
+		}
+	}
+	abortWithError(context, http.StatusNotAcceptable, ErrorCodeNotAcceptable,
+		fmt.Sprintf("unsupported Accept, the formats are %s", strings.Join(files.Exts(), ", ")), nil)
+	return "", false
+}
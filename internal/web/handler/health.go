@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/health"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+)
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusNotReady = "not_ready"
+)
+
+// HealthResponse is the response of the liveness and readiness apis. The Reason is why the server is not ready.
+type HealthResponse struct {
+	Status string `json:"status" yaml:"status"`
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Healthz return 200 while the server is serving.
+func Healthz(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Header("Cache-Control", "no-store")
+		context.JSON(http.StatusOK, HealthResponse{Status: HealthStatusOK})
+	}
+}
+
+// Readyz return 200 when the catalog has been loaded and it has at least the min keys of config, else 503.
+func Readyz(config config.I18nConfig, i18n *provider.I18n, state *health.State) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Header("Cache-Control", "no-store")
+		if err := state.Ready(i18n, config.ApplicationConfig.Health.MinKeys); err != nil {
+			context.JSON(http.StatusServiceUnavailable,
+				HealthResponse{Status: HealthStatusNotReady, Reason: err.Error()})
+			return
+		}
+		context.JSON(http.StatusOK, HealthResponse{Status: HealthStatusOK})
+	}
+}
+
+// InfoGet return the build version, the loaded files, the revision and the last load time of the catalog.
+func InfoGet(config config.I18nConfig, i18n *provider.I18n, state *health.State) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.JSON(http.StatusOK, state.Info(i18n))
+	}
+}
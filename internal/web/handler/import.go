@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/search"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ImportOverwrite sets all values of the file, the values which are not in the file are kept.
+	ImportOverwrite = "overwrite"
+	// ImportOnlyMissing sets the values of the file which the catalog hasn't, the existing values are kept.
+	ImportOnlyMissing = "only-missing"
+	// ImportReplaceSubtree makes the values under the scope the same as the file, the values which are not in the file
+	// are deleted. The scope is required.
+	ImportReplaceSubtree = "replace-subtree"
+
+	// importFileField is the multipart field of the uploaded file.
+	importFileField = "file"
+	// maxImportSize is the max size of the uploaded file.
+	maxImportSize = 32 << 20
+)
+
+var importStrategies = []string{ImportOverwrite, ImportOnlyMissing, ImportReplaceSubtree}
+
+// ImportResult is the changes of an import. They are not applied if DryRun.
+type ImportResult struct {
+	Format   string            `json:"format" yaml:"format"`
+	Strategy string            `json:"strategy" yaml:"strategy"`
+	DryRun   bool              `json:"dry_run" yaml:"dry_run"`
+	Changes  []provider.Change `json:"changes" yaml:"changes"`
+}
+
+// Import merge the values of the uploaded catalog file into the catalog, the file is the multipart field 'file'. The
+// queries are 'format' (the file extension like 'csv', detected by the file name, its content type or its content if
+// not set), 'strategy' ('overwrite' by default, 'only-missing' or 'replace-subtree'), 'scope' (the scope prefix joined
+// by '.', the values of the file out of it are skipped, it is required by 'replace-subtree') and 'dry_run' (return the
+// changes without applying them).
+//
+// The changes are recorded in the audit log. It responds 409 if the catalog is changed during the import.
+func Import(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		var problems []ValidationError
+		strategy := context.DefaultQuery("strategy", ImportOverwrite)
+		if !containsString(importStrategies, strategy) {
+			problems = append(problems, ValidationError{
+				Field:   "strategy",
+				Message: fmt.Sprintf("should be one of %s", strings.Join(importStrategies, ", ")),
+			})
+		}
+		// the replace-subtree without the scope would delete every value which is not in the file.
+		if strategy == ImportReplaceSubtree && len(context.Query("scope")) == 0 {
+			problems = append(problems, ValidationError{
+				Field:   "scope",
+				Message: fmt.Sprintf("is required by the strategy %s", ImportReplaceSubtree),
+			})
+		}
+		dryRun := false
+		if value := context.Query("dry_run"); len(value) != 0 {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				problems = append(problems, ValidationError{Field: "dry_run", Message: "should be a bool"})
+			}
+		}
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query", problems)
+			return
+		}
+		var scopes []string
+		if scope := context.Query("scope"); len(scope) != 0 {
+			scopes = strings.Split(scope, ".")
+		}
+
+		context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, maxImportSize)
+		header, err := context.FormFile(importFileField)
+		if err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest,
+				fmt.Sprintf("the multipart field '%s' is required: %v", importFileField, err), nil)
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+		content, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+
+		ext, ok := importFormat(context.Query("format"), header.Filename, header.Header.Get("Content-Type"), content)
+		if !ok {
+			abortWithError(context, http.StatusUnsupportedMediaType, ErrorCodeInvalidRequest,
+				fmt.Sprintf("unsupported format, should be one of %s", strings.Join(files.Exts(), ", ")), nil)
+			return
+		}
+		decode, _ := files.Decoder(ext)
+		incoming, err := decode(bytes.NewReader(content))
+		if err != nil {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid file",
+				[]ValidationError{{Field: importFileField, Message: err.Error()}})
+			return
+		}
+
+		// the patch is the diff from one snapshot of the catalog, so it has the imported values only, and applying it
+		// fails with a conflict instead of reverting the values which are changed after the snapshot.
+		base := i18n.Clone()
+		target, problems := importTarget(base, incoming, strategy, scopes)
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid messages", problems)
+			return
+		}
+		patch := base.Diff(target)
+
+		var denied []ValidationError
+		for _, change := range patch.Changes {
+			permission := auth.PermissionWrite
+			if change.Type == provider.ChangeRemoved {
+				permission = auth.PermissionDelete
+			}
+			if !auth.Allowed(context, permission, change.Language, change.Scopes...) {
+				denied = append(denied, ValidationError{
+					Field:   importFileField,
+					Message: forbiddenMessage(permission, change.Language, change.Scopes),
+				})
+			}
+		}
+		if len(denied) != 0 {
+			abortWithError(context, http.StatusForbidden, auth.ErrorCodeForbidden, "no permission of messages", denied)
+			return
+		}
+
+		if !dryRun {
+			if err = patch.Apply(i18n); err != nil {
+				abortWithError(context, http.StatusConflict, ErrorCodeConflict, err.Error(), nil)
+				return
+			}
+			recordChanges(context, patch.Changes...)
+		}
+		context.JSON(http.StatusOK, ImportResult{
+			Format:   ext,
+			Strategy: strategy,
+			DryRun:   dryRun,
+			Changes:  patch.Changes,
+		})
+	}
+}
+
+// importTarget return a copy of the base which has been merged with the values of the incoming file under the scopes
+// by the strategy, and the problems of the incoming values.
+func importTarget(base, incoming *provider.I18n, strategy string, scopes []string) (*provider.I18n, []ValidationError) {
+	target := base.Clone()
+	if strategy == ImportReplaceSubtree {
+		var removed []MessageItem
+		target.WalkMessageUnder(scopes, func(message map[string]string, flags ...string) {
+			itemScopes := append(append([]string{}, scopes...), flags...)
+			for ln := range message {
+				removed = append(removed, MessageItem{Scopes: itemScopes, Language: ln})
+			}
+		})
+		for _, item := range removed {
+			target.PushMessageByString(item.Language, "", item.Scopes...)
+		}
+	}
+
+	var problems []ValidationError
+	var items []MessageItem
+	incoming.WalkRecord(func(ln, messageValue string, flags ...string) {
+		if !search.HasPrefix(flags, scopes) {
+			return
+		}
+		item := MessageItem{
+			Scopes:   append([]string{}, flags...),
+			Language: catalog.LanguageValue(base, ln),
+			Value:    messageValue,
+		}
+		field := fmt.Sprintf("%s[%s]", importFileField, strings.Join(flags, files.KeySeparator))
+		if itemProblems := validateMessageItem(field, item, true); len(itemProblems) != 0 {
+			problems = append(problems, itemProblems...)
+			return
+		}
+		items = append(items, item)
+	})
+	for _, item := range items {
+		if strategy == ImportOnlyMissing {
+			if _, ok := target.Lookup(item.Language, item.Scopes...); ok {
+				continue
+			}
+		}
+		target.PushMessageByString(item.Language, item.Value, item.Scopes...)
+	}
+	return target, problems
+}
+
+// importFormat return the file extension of the uploaded file. It is the format query if it is set, else the extension
+// of the file name, the content type of the file, or the format guessed by the content in order.
+func importFormat(format, name, contentType string, content []byte) (string, bool) {
+	if len(format) != 0 {
+		ext := "." + strings.TrimPrefix(strings.ToLower(format), ".")
+		_, ok := files.Decoder(ext)
+		return ext, ok
+	}
+	if ext := strings.ToLower(path.Ext(name)); len(ext) != 0 {
+		if _, ok := files.Decoder(ext); ok {
+			return ext, true
+		}
+	}
+	if ext, ok := files.ExtOf(contentType); ok {
+		return ext, true
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\ufeff")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return ".json", true
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return ".xlf", true
+	case bytes.HasPrefix(trimmed, []byte("msgid ")) || bytes.HasPrefix(trimmed, []byte("#")) ||
+		bytes.Contains(trimmed, []byte("\nmsgid ")):
+		return ".po", true
+	case bytes.HasPrefix(trimmed, []byte("key,")):
+		return ".csv", true
+	}
+	return "", false
+}
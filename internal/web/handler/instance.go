@@ -7,6 +7,10 @@ import (
 	"net/http"
 )
 
+// InstanceGet return the whole catalog in one json.
+//
+// Deprecated: the response is too large for a big catalog, use KeyList to page the keys or Export to download a
+// subtree. It is kept for the old clients which mirror the whole catalog.
 func InstanceGet(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		context.JSON(http.StatusOK, i18n)
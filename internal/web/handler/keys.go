@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/search"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultKeyLimit = 100
+	maxKeyLimit     = 1000
+)
+
+// keySorts is the sorts of KeyList, the '-' prefix means descending.
+var keySorts = []string{"key", "-key", "value", "-value"}
+
+// KeyItem is a key, the scopes which have values, and the values of its languages.
+type KeyItem struct {
+	Scopes []string          `json:"scopes" yaml:"scopes"`
+	Values map[string]string `json:"values" yaml:"values"`
+}
+
+// KeyPage is a page of the keys. The NextCursor is the cursor of the next page, it is empty on the last page.
+type KeyPage struct {
+	Items      []KeyItem `json:"items" yaml:"items"`
+	NextCursor string    `json:"next_cursor" yaml:"next_cursor"`
+	Revision   uint64    `json:"revision" yaml:"revision"`
+}
+
+// keyCursor is the position after the last key of a page, it is encoded as base64 json. The pages sorted by key are
+// stable when the catalog is changed between them: a key is never returned twice, and the new keys after the cursor
+// are returned. The pages sorted by value are not: a key whose value changes between them can move across the cursor,
+// so it can be returned twice or missed.
+type keyCursor struct {
+	Sort   string   `json:"o"`
+	Scopes []string `json:"s"`
+	Value  string   `json:"v,omitempty"`
+}
+
+func (c keyCursor) String() string {
+	content, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func parseKeyCursor(value string) (keyCursor, error) {
+	res := keyCursor{}
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(content, &res)
+	}
+	return res, err
+}
+
+// KeyList return a page of the keys under the scope prefix. The queries are 'scope' (the scope prefix joined by '.'),
+// 'language' (only the keys which have a value of it, with the value), 'sort' ('key' by default, 'value' requires the
+// language, the '-' prefix means descending), 'cursor' (the next_cursor of the last page) and 'limit'. The keys are
+// paged from the sorted index, so a page doesn't walk the whole catalog.
+func KeyList(config config.I18nConfig, i18n *provider.I18n, index *search.Index) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		var scopes []string
+		if scope := context.Query("scope"); len(scope) != 0 {
+			scopes = strings.Split(scope, ".")
+		}
+		language := context.Query("language")
+		if len(language) != 0 {
+			language = catalog.LanguageValue(i18n, language)
+		}
+		sortBy := context.DefaultQuery("sort", "key")
+		limit := defaultKeyLimit
+
+		var problems []ValidationError
+		if !containsString(keySorts, sortBy) {
+			problems = append(problems, ValidationError{
+				Field:   "sort",
+				Message: fmt.Sprintf("should be one of %s", strings.Join(keySorts, ", ")),
+			})
+		} else if strings.TrimPrefix(sortBy, "-") == "value" && len(language) == 0 {
+			problems = append(problems, ValidationError{
+				Field:   "sort",
+				Message: "sorting by value requires the language",
+			})
+		}
+		var cursor *keyCursor
+		if value := context.Query("cursor"); len(value) != 0 {
+			parsed, err := parseKeyCursor(value)
+			if err != nil || parsed.Sort != sortBy {
+				problems = append(problems, ValidationError{
+					Field:   "cursor",
+					Message: "should be the next_cursor of the same sort",
+				})
+			}
+			cursor = &parsed
+		}
+		if value := context.Query("limit"); len(value) != 0 {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > maxKeyLimit {
+				problems = append(problems, ValidationError{
+					Field:   "limit",
+					Message: fmt.Sprintf("should be in [1, %d]", maxKeyLimit),
+				})
+			}
+			limit = parsed
+		}
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query", problems)
+			return
+		}
+
+		query := search.KeyQuery{
+			Scopes:     scopes,
+			Language:   language,
+			ByValue:    strings.TrimPrefix(sortBy, "-") == "value",
+			Descending: strings.HasPrefix(sortBy, "-"),
+			Limit:      limit,
+		}
+		if cursor != nil {
+			query.After, query.AfterValue = cursor.Scopes, cursor.Value
+		}
+		result := index.Keys(query)
+		res := KeyPage{Items: make([]KeyItem, 0, len(result.Keys)), Revision: result.Revision}
+		for _, key := range result.Keys {
+			res.Items = append(res.Items, KeyItem{Scopes: key.Scopes, Values: key.Values})
+		}
+		if result.More {
+			last := res.Items[len(res.Items)-1]
+			next := keyCursor{Sort: sortBy, Scopes: last.Scopes}
+			if query.ByValue {
+				next.Value = last.Values[language]
+			}
+			res.NextCursor = next.String()
+		}
+		context.JSON(http.StatusOK, res)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
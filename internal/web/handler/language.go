@@ -9,13 +9,7 @@ import (
 
 func StandardList(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
 	return func(context *gin.Context) {
-		context.JSON(http.StatusOK, []string{
-			provider.Custom,
-			provider.ISO6391,
-			provider.ISO6392B,
-			provider.ISO6392T,
-			provider.ISO6393,
-		})
+		context.JSON(http.StatusOK, provider.Standards)
 	}
 }
 
@@ -1,9 +1,10 @@
 package handler
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/pkg/negotiate"
 	"github.com/uberate/i18n/pkg/provider"
 	"net/http"
 	"strings"
@@ -13,22 +14,13 @@ func MessageGet(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
 	return func(context *gin.Context) {
 		language := context.Param("ln")
 		lk := provider.GetLanguageKey(language)
-		scopes := context.Param("scopes")
-		if len(scopes) > 0 && strings.HasSuffix(scopes, "/") {
-			scopes = scopes[:len(scopes)-1]
-		}
-		if len(scopes) > 0 && strings.HasPrefix(scopes, "/") {
-			scopes = scopes[1:]
-		}
+		scopes := scopesParam(context)
 
+		// the fallback value or the placeholder of a missed message is from the MissingHandler of the instance.
 		value, ok := i18n.Message(*lk, strings.Split(scopes, "/")...)
 		if !ok {
-			if config.ApplicationConfig.NotFoundWith404 {
-				context.JSON(http.StatusNotFound, nil)
-				return
-			} else {
-				value = fmt.Sprintf("%s_%s", language, scopes)
-			}
+			context.JSON(http.StatusNotFound, nil)
+			return
 		}
 		context.JSON(http.StatusOK, value)
 	}
@@ -39,15 +31,12 @@ func MessageCreate(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFun
 		language := context.Param("ln")
 		lk := provider.GetLanguageKey(language)
 		message := context.Param("msg")
-		scopes := context.Param("scopes")
-		if len(scopes) > 0 && strings.HasSuffix(scopes, "/") {
-			scopes = scopes[:len(scopes)-1]
-		}
-		if len(scopes) > 0 && strings.HasPrefix(scopes, "/") {
-			scopes = scopes[1:]
-		}
+		scopes := scopesParam(context)
 
-		i18n.PushMessage(*lk, message, strings.Split(scopes, "/")...)
+		if !allowed(context, auth.PermissionWrite, lk.Lower(i18n.Standard), strings.Split(scopes, "/")) {
+			return
+		}
+		pushMessage(context, i18n, lk.Lower(i18n.Standard), message, strings.Split(scopes, "/")...)
 	}
 }
 
@@ -55,16 +44,54 @@ func MessageDelete(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFun
 	return func(context *gin.Context) {
 		language := context.Param("ln")
 		lk := provider.GetLanguageKey(language)
-		scopes := context.Param("scopes")
+		scopes := scopesParam(context)
 
-		if len(scopes) > 0 && strings.HasSuffix(scopes, "/") {
-			scopes = scopes[:len(scopes)-1]
+		if !allowed(context, auth.PermissionDelete, lk.Lower(i18n.Standard), strings.Split(scopes, "/")) {
+			return
 		}
-		if len(scopes) > 0 && strings.HasPrefix(scopes, "/") {
-			scopes = scopes[1:]
+		pushMessage(context, i18n, lk.Lower(i18n.Standard), "", strings.Split(scopes, "/")...)
+
+	}
+}
+
+// MessageNegotiate like MessageGet, but the language is chosen from the Accept-Language header. The candidates of the
+// header are tried in order, and the chosen language is returned in the Content-Language header.
+func MessageNegotiate(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	cache := negotiate.NewCache(i18n, config.ApplicationConfig.LanguageFallbacks, config.ApplicationConfig.DefaultLanguage)
+	return func(context *gin.Context) {
+		scopes := scopesParam(context)
+		negotiator := cache.Negotiator()
+
+		addVary(context.Writer.Header(), "Accept-Language")
+		for _, language := range negotiator.Candidates(context.GetHeader("Accept-Language")) {
+			if value, ok := i18n.Lookup(language, strings.Split(scopes, "/")...); ok {
+				context.Header("Content-Language", language)
+				context.JSON(http.StatusOK, value)
+				return
+			}
 		}
 
-		i18n.PushMessage(*lk, "", strings.Split(scopes, "/")...)
+		language, _ := negotiator.Match(context.GetHeader("Accept-Language"))
+		value, ok := i18n.MessageByString(language, strings.Split(scopes, "/")...)
+		if !ok {
+			context.JSON(http.StatusNotFound, nil)
+			return
+		}
+		if len(language) != 0 {
+			context.Header("Content-Language", language)
+		}
+		context.JSON(http.StatusOK, value)
+	}
+}
 
+// scopesParam return the 'scopes' param of the path without leading and trailing '/'.
+func scopesParam(context *gin.Context) string {
+	scopes := context.Param("scopes")
+	if len(scopes) > 0 && strings.HasSuffix(scopes, "/") {
+		scopes = scopes[:len(scopes)-1]
+	}
+	if len(scopes) > 0 && strings.HasPrefix(scopes, "/") {
+		scopes = scopes[1:]
 	}
+	return scopes
 }
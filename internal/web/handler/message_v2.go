@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"strings"
+)
+
+// MessageItem is one language value of the scopes. The Language can be the custom name of a known LanguageKey like
+// 'english', or the language string value like 'en'.
+type MessageItem struct {
+	Scopes   []string `json:"scopes" yaml:"scopes"`
+	Language string   `json:"language" yaml:"language"`
+	Value    string   `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// MessageEntry is all language values of the scopes.
+type MessageEntry struct {
+	Scopes []string          `json:"scopes" yaml:"scopes"`
+	Values map[string]string `json:"values" yaml:"values"`
+}
+
+// BatchRequest upsert and delete many values in one request. The deletes are applied after the upserts.
+type BatchRequest struct {
+	Upserts []MessageItem `json:"upserts" yaml:"upserts"`
+	Deletes []MessageItem `json:"deletes" yaml:"deletes"`
+}
+
+// BatchResult is the result of BatchRequest. The NotFound is the deletes whose value not found.
+type BatchResult struct {
+	Created  int           `json:"created" yaml:"created"`
+	Updated  int           `json:"updated" yaml:"updated"`
+	Deleted  int           `json:"deleted" yaml:"deleted"`
+	NotFound []MessageItem `json:"not_found" yaml:"not_found"`
+}
+
+// ValidationError is the detail of a 422 response, the Field is like 'upserts[1].value'.
+type ValidationError struct {
+	Field   string `json:"field" yaml:"field"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// MessageGetV2 return the MessageEntry of the scopes, or the MessageItem of one language with query 'language'.
+func MessageGetV2(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		scopes := splitScopes(scopesParam(context))
+		if language := context.Query("language"); len(language) != 0 {
+			ln := catalog.LanguageValue(i18n, language)
+			value, ok := i18n.Lookup(ln, scopes...)
+			if !ok {
+				abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, "message not found", nil)
+				return
+			}
+			context.JSON(http.StatusOK, MessageItem{Scopes: scopes, Language: ln, Value: value})
+			return
+		}
+
+		values := map[string]string{}
+		i18n.WalkMessageUnder(scopes, func(message map[string]string, flags ...string) {
+			if len(flags) != 0 {
+				return
+			}
+			for language, value := range message {
+				values[language] = value
+			}
+		})
+		if len(values) == 0 {
+			abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, "message not found", nil)
+			return
+		}
+		context.JSON(http.StatusOK, MessageEntry{Scopes: scopes, Values: values})
+	}
+}
+
+// MessageCreateV2 create a value from the MessageItem body. It responds 201 with the created item, or 409 if the value
+// already exists.
+func MessageCreateV2(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		item, ok := bindMessageItem(context, i18n, true)
+		if !ok || !allowed(context, auth.PermissionWrite, item.Language, item.Scopes) {
+			return
+		}
+		if change, ok := createMessage(context, i18n, item.Language, item.Value, item.Scopes...); !ok {
+			abortWithError(context, http.StatusConflict, ErrorCodeConflict, "message already exists",
+				MessageItem{Scopes: item.Scopes, Language: item.Language, Value: change.OldValue})
+			return
+		}
+		context.JSON(http.StatusCreated, item)
+	}
+}
+
+// MessageUpsertV2 create or update a value from the MessageItem body. It responds 201 when created, and 200 when
+// updated.
+func MessageUpsertV2(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		item, ok := bindMessageItem(context, i18n, true)
+		if !ok || !allowed(context, auth.PermissionWrite, item.Language, item.Scopes) {
+			return
+		}
+
+		status := http.StatusOK
+		if change, ok := pushMessage(context, i18n, item.Language, item.Value, item.Scopes...); ok &&
+			change.Type == provider.ChangeAdded {
+			status = http.StatusCreated
+		}
+		context.JSON(status, item)
+	}
+}
+
+// MessageDeleteV2 delete the value of the scopes of query 'language'. It responds 204 when deleted, and 404 if the
+// value not found.
+func MessageDeleteV2(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		item := MessageItem{
+			Scopes:   splitScopes(scopesParam(context)),
+			Language: catalog.LanguageValue(i18n, context.Query("language")),
+		}
+		if problems := validateMessageItem("", item, false); len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid message", problems)
+			return
+		}
+		if !allowed(context, auth.PermissionDelete, item.Language, item.Scopes) {
+			return
+		}
+		if _, ok := pushMessage(context, i18n, item.Language, "", item.Scopes...); !ok {
+			abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, "message not found", nil)
+			return
+		}
+		context.Status(http.StatusNoContent)
+	}
+}
+
+// MessageBatchV2 upsert and delete many values by the BatchRequest body. All items are validated before any change,
+// an invalid item makes the whole request fail with 422, and an item denied by the ACL makes it fail with 403.
+func MessageBatchV2(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		request := BatchRequest{}
+		if err := context.ShouldBindJSON(&request); err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+
+		var problems []ValidationError
+		for index := range request.Upserts {
+			request.Upserts[index].Language = catalog.LanguageValue(i18n, request.Upserts[index].Language)
+			field := fmt.Sprintf("upserts[%d]", index)
+			problems = append(problems, validateMessageItem(field, request.Upserts[index], true)...)
+		}
+		for index := range request.Deletes {
+			request.Deletes[index].Language = catalog.LanguageValue(i18n, request.Deletes[index].Language)
+			field := fmt.Sprintf("deletes[%d]", index)
+			problems = append(problems, validateMessageItem(field, request.Deletes[index], false)...)
+		}
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid messages", problems)
+			return
+		}
+
+		var denied []ValidationError
+		for index, item := range request.Upserts {
+			if !auth.Allowed(context, auth.PermissionWrite, item.Language, item.Scopes...) {
+				denied = append(denied, ValidationError{
+					Field:   fmt.Sprintf("upserts[%d]", index),
+					Message: forbiddenMessage(auth.PermissionWrite, item.Language, item.Scopes),
+				})
+			}
+		}
+		for index, item := range request.Deletes {
+			if !auth.Allowed(context, auth.PermissionDelete, item.Language, item.Scopes...) {
+				denied = append(denied, ValidationError{
+					Field:   fmt.Sprintf("deletes[%d]", index),
+					Message: forbiddenMessage(auth.PermissionDelete, item.Language, item.Scopes),
+				})
+			}
+		}
+		if len(denied) != 0 {
+			abortWithError(context, http.StatusForbidden, auth.ErrorCodeForbidden, "no permission of messages", denied)
+			return
+		}
+
+		res := BatchResult{NotFound: []MessageItem{}}
+		for _, item := range request.Upserts {
+			// an unchanged value exists, it is counted as updated.
+			if change, ok := pushMessage(context, i18n, item.Language, item.Value, item.Scopes...); ok &&
+				change.Type == provider.ChangeAdded {
+				res.Created++
+			} else {
+				res.Updated++
+			}
+		}
+		for _, item := range request.Deletes {
+			if _, ok := pushMessage(context, i18n, item.Language, "", item.Scopes...); !ok {
+				res.NotFound = append(res.NotFound, item)
+				continue
+			}
+			res.Deleted++
+		}
+		context.JSON(http.StatusOK, res)
+	}
+}
+
+// bindMessageItem bind and validate the MessageItem body, the language of the result is the language string value. If
+// the body is invalid, the request is aborted and return false.
+func bindMessageItem(context *gin.Context, i18n *provider.I18n, withValue bool) (MessageItem, bool) {
+	item := MessageItem{}
+	if err := context.ShouldBindJSON(&item); err != nil {
+		abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+		return item, false
+	}
+	item.Language = catalog.LanguageValue(i18n, item.Language)
+	if problems := validateMessageItem("", item, withValue); len(problems) != 0 {
+		abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid message", problems)
+		return item, false
+	}
+	return item, true
+}
+
+// validateMessageItem return the problems of the item, the field is the prefix of the problem fields.
+func validateMessageItem(field string, item MessageItem, withValue bool) []ValidationError {
+	if len(field) != 0 {
+		field += "."
+	}
+
+	var res []ValidationError
+	if len(item.Scopes) == 0 {
+		res = append(res, ValidationError{Field: field + "scopes", Message: "at least one scope is required"})
+	}
+	for index, scope := range item.Scopes {
+		if len(scope) == 0 {
+			res = append(res, ValidationError{Field: fmt.Sprintf("%sscopes[%d]", field, index), Message: "empty scope"})
+		}
+	}
+	if len(item.Language) == 0 {
+		res = append(res, ValidationError{Field: field + "language", Message: "language is required"})
+	}
+	if withValue && len(item.Value) == 0 {
+		res = append(res, ValidationError{Field: field + "value", Message: "value is required"})
+	}
+	return res
+}
+
+// splitScopes split the scopes param by '/', an empty param means no scope.
+func splitScopes(scopes string) []string {
+	if len(scopes) == 0 {
+		return []string{}
+	}
+	return strings.Split(scopes, "/")
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/metrics"
+	"github.com/uberate/i18n/pkg/provider"
+	"time"
+)
+
+// unmatchedRoute is the route label of the requests which match no route, so the label values are bounded.
+const unmatchedRoute = "unmatched"
+
+// Metrics return a middleware which counts the requests and their latencies by the route.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+		context.Next()
+
+		route := context.FullPath()
+		if len(route) == 0 {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(context.Request.Method, route, context.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsGet return the metrics of the gatherer in the Prometheus text format.
+func MetricsGet(config config.I18nConfig, i18n *provider.I18n, gatherer prometheus.Gatherer) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+)
+
+// MissingList return all missed messages which recorded by the collector. With query 'format=skeleton', it returns
+// an I18n instance which contains all missed messages for translators.
+func MissingList(config config.I18nConfig, i18n *provider.I18n, collector *provider.MissingCollector) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if context.Query("format") == "skeleton" {
+			context.JSON(http.StatusOK, collector.Skeleton(i18n.Standard, nil))
+			return
+		}
+		context.JSON(http.StatusOK, collector.Records())
+	}
+}
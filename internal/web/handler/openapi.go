@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/openapi"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+)
+
+// OpenAPIGet return the OpenAPI 3 document of the server api.
+func OpenAPIGet(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		context.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Raw())
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/project"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"time"
+)
+
+// ProjectRequest is the request of a new project. The catalog of the project is empty, the values are added by its
+// apis. The Auth is the auth of the project apis, the auth of the server is used when it is disabled.
+type ProjectRequest struct {
+	ID       string            `json:"id" yaml:"id"`
+	Standard string            `json:"standard" yaml:"standard"`
+	Readonly bool              `json:"readonly" yaml:"readonly"`
+	Auth     config.AuthConfig `json:"auth" yaml:"auth"`
+}
+
+// ProjectResponse is a project without the secrets of its auth.
+type ProjectResponse struct {
+	ID          string    `json:"id" yaml:"id"`
+	Standard    string    `json:"standard" yaml:"standard"`
+	Readonly    bool      `json:"readonly" yaml:"readonly"`
+	Files       []string  `json:"files" yaml:"files"`
+	AuthEnabled bool      `json:"auth_enabled" yaml:"auth_enabled"`
+	Static      bool      `json:"static" yaml:"static"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	Revision    uint64    `json:"revision" yaml:"revision"`
+	Checksum    string    `json:"checksum" yaml:"checksum"`
+}
+
+func projectResponse(p *project.Project) ProjectResponse {
+	instance := p.Instance()
+	files := p.Config.Files
+	if files == nil {
+		files = []string{}
+	}
+	return ProjectResponse{
+		ID:          p.Config.ID,
+		Standard:    p.Config.Standard,
+		Readonly:    p.Config.Readonly,
+		Files:       files,
+		AuthEnabled: p.Config.Auth.Enabled,
+		Static:      p.Static,
+		CreatedAt:   p.CreatedAt,
+		Revision:    instance.Revision(),
+		Checksum:    instance.Checksum(),
+	}
+}
+
+// ProjectList return all projects sorted by the id.
+func ProjectList(config config.I18nConfig, i18n *provider.I18n, registry *project.Registry) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		projects := registry.List()
+		res := make([]ProjectResponse, 0, len(projects))
+		for _, p := range projects {
+			res = append(res, projectResponse(p))
+		}
+		context.JSON(http.StatusOK, res)
+	}
+}
+
+// ProjectGet return the project.
+func ProjectGet(config config.I18nConfig, i18n *provider.I18n, registry *project.Registry) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		p, ok := registry.Get(context.Param("project"))
+		if !ok {
+			abortWithProjectError(context, project.ErrNotFound)
+			return
+		}
+		context.JSON(http.StatusOK, projectResponse(p))
+	}
+}
+
+// ProjectCreate create an empty project. It responds 201 with the project, 409 if the id exists, or 422 if the request
+// is invalid.
+func ProjectCreate(serverConfig config.I18nConfig, i18n *provider.I18n, registry *project.Registry) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		request := ProjectRequest{}
+		if err := context.ShouldBindJSON(&request); err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+
+		p, err := registry.Create(config.ProjectConfig{
+			ID:       request.ID,
+			Standard: request.Standard,
+			Readonly: request.Readonly,
+			Auth:     request.Auth,
+		})
+		if err != nil {
+			abortWithProjectError(context, err)
+			return
+		}
+		context.JSON(http.StatusCreated, projectResponse(p))
+	}
+}
+
+// ProjectDelete delete the project and its catalog. The projects of the config can't be deleted, it responds 409.
+func ProjectDelete(config config.I18nConfig, i18n *provider.I18n, registry *project.Registry) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if err := registry.Delete(context.Param("project")); err != nil {
+			abortWithProjectError(context, err)
+			return
+		}
+		context.Status(http.StatusNoContent)
+	}
+}
+
+// ProjectForward serve the request by the apis of the project, the path after the project id is the path of the same
+// api of the default catalog in the version, like '/v1/projects/shop/bundle/en/user' is '/v1/bundle/en/user' of the
+// project 'shop'. The project apis authenticate the request by the auth of the project.
+func ProjectForward(config config.I18nConfig, i18n *provider.I18n, registry *project.Registry,
+	version string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		p, ok := registry.Get(context.Param("project"))
+		if !ok {
+			abortWithProjectError(context, project.ErrNotFound)
+			return
+		}
+
+		request := context.Request.Clone(context.Request.Context())
+		request.URL.Path = "/" + version + context.Param("path")
+		request.URL.RawPath = ""
+		// the project apis use the same request id in the response and the audit log.
+		request.Header.Set(RequestIDHeader, requestID(context))
+		p.Handler.ServeHTTP(context.Writer, request)
+	}
+}
+
+func abortWithProjectError(context *gin.Context, err error) {
+	switch err := err.(type) {
+	case config.ValidationError:
+		details := make([]ValidationError, 0, len(err))
+		for _, item := range err {
+			details = append(details, ValidationError{Field: item.Field, Message: item.Message})
+		}
+		abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid project", details)
+		return
+	}
+	switch err {
+	case project.ErrNotFound:
+		abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, err.Error(), nil)
+	case project.ErrExists, project.ErrStatic:
+		abortWithError(context, http.StatusConflict, ErrorCodeConflict, err.Error(), nil)
+	default:
+		abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "i18n/request_id"
+)
+
+// RequestID return a middleware which sets the request id from the 'X-Request-ID' header, or a random id if the header
+// is empty. The request id is returned in the 'X-Request-ID' header.
+func RequestID() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id := context.GetHeader(RequestIDHeader)
+		if len(id) == 0 || len(id) > 128 {
+			id = newRequestID()
+		}
+		context.Set(requestIDKey, id)
+		context.Header(RequestIDHeader, id)
+		context.Next()
+	}
+}
+
+// requestID return the request id which is set by RequestID, or empty.
+func requestID(context *gin.Context) string {
+	return context.GetString(requestIDKey)
+}
+
+func newRequestID() string {
+	buffer := make([]byte, 16)
+	_, _ = rand.Read(buffer)
+	return hex.EncodeToString(buffer)
+}
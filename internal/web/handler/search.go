@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/search"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 1000
+)
+
+// Search return the values which match the text, the newest revision of the catalog is searched. The queries are 'q'
+// (the text), 'mode' ('substring' or 'regex'), 'case_sensitive', 'in' ('value' or 'scope', the scope path is joined by
+// '.'), 'language', 'scope' (the scope prefix joined by '.'), 'offset' and 'limit'.
+func Search(config config.I18nConfig, i18n *provider.I18n, index *search.Index) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		query := search.Query{
+			Text:     context.Query("q"),
+			Field:    search.Field(context.DefaultQuery("in", string(search.FieldValue))),
+			Language: context.Query("language"),
+			Limit:    defaultSearchLimit,
+		}
+		if len(query.Language) != 0 {
+			query.Language = catalog.LanguageValue(i18n, query.Language)
+		}
+		if scope := context.Query("scope"); len(scope) != 0 {
+			query.Scopes = strings.Split(scope, ".")
+		}
+
+		var problems []ValidationError
+		switch context.DefaultQuery("mode", "substring") {
+		case "substring":
+		case "regex":
+			query.Regex = true
+		default:
+			problems = append(problems, ValidationError{Field: "mode", Message: "should be 'substring' or 'regex'"})
+		}
+		if query.Field != search.FieldValue && query.Field != search.FieldScope {
+			problems = append(problems, ValidationError{Field: "in", Message: "should be 'value' or 'scope'"})
+		}
+		if value := context.Query("case_sensitive"); len(value) != 0 {
+			caseSensitive, err := strconv.ParseBool(value)
+			if err != nil {
+				problems = append(problems, ValidationError{Field: "case_sensitive", Message: "should be a bool"})
+			}
+			query.CaseSensitive = caseSensitive
+		}
+		if value := context.Query("offset"); len(value) != 0 {
+			offset, err := strconv.Atoi(value)
+			if err != nil || offset < 0 {
+				problems = append(problems, ValidationError{Field: "offset", Message: "should be >= 0"})
+			}
+			query.Offset = offset
+		}
+		if value := context.Query("limit"); len(value) != 0 {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > maxSearchLimit {
+				problems = append(problems, ValidationError{
+					Field:   "limit",
+					Message: fmt.Sprintf("should be in [1, %d]", maxSearchLimit),
+				})
+			}
+			query.Limit = limit
+		}
+		if len(problems) != 0 {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query", problems)
+			return
+		}
+
+		result, err := index.Search(query)
+		if err != nil {
+			abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query",
+				[]ValidationError{{Field: "q", Message: err.Error()}})
+			return
+		}
+		context.JSON(http.StatusOK, result)
+	}
+}
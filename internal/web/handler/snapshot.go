@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/snapshot"
+	"net/http"
+)
+
+// SnapshotRequest is the request of a new snapshot.
+type SnapshotRequest struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// SnapshotList return all snapshots, the oldest first.
+func SnapshotList(config config.I18nConfig, i18n *provider.I18n, store *snapshot.Store) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		snapshots, err := store.List()
+		if err != nil {
+			abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+			return
+		}
+		context.JSON(http.StatusOK, snapshots)
+	}
+}
+
+// SnapshotGet return the metadata of the snapshot.
+func SnapshotGet(config config.I18nConfig, i18n *provider.I18n, store *snapshot.Store) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		item, err := store.Get(context.Param("name"))
+		if err != nil {
+			abortWithSnapshotError(context, err)
+			return
+		}
+		context.JSON(http.StatusOK, item)
+	}
+}
+
+// SnapshotCreate create a snapshot of the live catalog. It responds 201 with the snapshot, or 409 if the name exists.
+func SnapshotCreate(config config.I18nConfig, i18n *provider.I18n, store *snapshot.Store) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		request := SnapshotRequest{}
+		if err := context.ShouldBindJSON(&request); err != nil {
+			abortWithError(context, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error(), nil)
+			return
+		}
+
+		item, err := store.Create(request.Name, request.Description, false, i18n)
+		if err != nil {
+			abortWithSnapshotError(context, err)
+			return
+		}
+		context.JSON(http.StatusCreated, item)
+	}
+}
+
+// SnapshotDiff return the Patch which changes the snapshot 'name' to the snapshot 'other'. The name 'live' means the
+// live catalog.
+func SnapshotDiff(config config.I18nConfig, i18n *provider.I18n, store *snapshot.Store) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		patch, err := store.Diff(context.Param("name"), context.Param("other"), i18n)
+		if err != nil {
+			abortWithSnapshotError(context, err)
+			return
+		}
+		context.JSON(http.StatusOK, patch)
+	}
+}
+
+// SnapshotRollback replace the live catalog by the snapshot, and return the Patch which has been applied. The changes
+// are recorded in the audit log.
+func SnapshotRollback(config config.I18nConfig, i18n *provider.I18n, store *snapshot.Store) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		patch, err := store.Rollback(context.Param("name"), i18n)
+		if err != nil {
+			abortWithSnapshotError(context, err)
+			return
+		}
+		recordChanges(context, patch.Changes...)
+		context.JSON(http.StatusOK, patch)
+	}
+}
+
+func abortWithSnapshotError(context *gin.Context, err error) {
+	switch err {
+	case snapshot.ErrNotFound:
+		abortWithError(context, http.StatusNotFound, ErrorCodeNotFound, err.Error(), nil)
+	case snapshot.ErrExists:
+		abortWithError(context, http.StatusConflict, ErrorCodeConflict, err.Error(), nil)
+	case snapshot.ErrInvalidName:
+		abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, err.Error(),
+			[]ValidationError{{Field: "name", Message: err.Error()}})
+	default:
+		abortWithError(context, http.StatusInternalServerError, ErrorCodeInternal, err.Error(), nil)
+	}
+}
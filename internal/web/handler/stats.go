@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/stats"
+	"net/http"
+	"strings"
+)
+
+// StatsGet return the coverage statistics of the instance. The query 'languages' is the comma separated languages to
+// compute, default is all languages.
+func StatsGet(config config.I18nConfig, i18n *provider.I18n) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		var languages []string
+		for _, language := range strings.Split(context.Query("languages"), ",") {
+			if language = strings.TrimSpace(language); len(language) != 0 {
+				languages = append(languages, language)
+			}
+		}
+		context.JSON(http.StatusOK, stats.Compute(i18n, languages...))
+	}
+}
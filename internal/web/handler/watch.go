@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/catalog"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WatchEventReady = "ready" // The stream is ready, the data is WatchState.
+	WatchEventReset = "reset" // The resume revision is not available, the data is WatchState. Reload the catalog.
+
+	watchHeartbeat = 15 * time.Second
+)
+
+// WatchState is the data of the 'ready' and 'reset' events.
+type WatchState struct {
+	Epoch    string `json:"epoch" yaml:"epoch"`
+	Revision uint64 `json:"revision" yaml:"revision"`
+}
+
+// Watch stream the changes of the catalog as Server-Sent Events. The queries are 'scope' (the scope prefix joined by
+// '.'), 'language', 'epoch' and 'since' (the epoch and the revision to resume from, the 'Last-Event-ID' header is
+// preferred).
+//
+// The 'upsert' and 'delete' events are watch.Event, and the event id is '<epoch>:<revision>'. The events after the
+// resume revision are sent first, then a 'ready' event. If the changes after the resume revision are not kept, or the
+// epoch is not the current epoch (the server restarted, the revisions are restarted too), a 'reset' event is sent
+// instead of them, the client should reload the catalog.
+func Watch(config config.I18nConfig, i18n *provider.I18n, hub *watch.Hub) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		filter := watch.Filter{}
+		if scope := context.Query("scope"); len(scope) != 0 {
+			filter.Scopes = strings.Split(scope, ".")
+		}
+		if language := context.Query("language"); len(language) != 0 {
+			filter.Language = catalog.LanguageValue(i18n, language)
+		}
+
+		epoch, since := context.Query("epoch"), context.Query("since")
+		if lastEventID := context.GetHeader("Last-Event-ID"); len(lastEventID) != 0 {
+			epoch, since = "", lastEventID
+			if index := strings.LastIndex(lastEventID, ":"); index >= 0 {
+				epoch, since = lastEventID[:index], lastEventID[index+1:]
+			}
+		}
+		var revision uint64
+		if len(since) != 0 {
+			var err error
+			if revision, err = strconv.ParseUint(since, 10, 64); err != nil {
+				abortWithError(context, http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "invalid query",
+					[]ValidationError{{Field: "since", Message: "should be a revision"}})
+				return
+			}
+		}
+
+		subscription, replay, err := hub.Subscribe(filter, epoch, revision)
+		reset := err == watch.ErrResumeTooOld
+		if reset {
+			subscription, replay, _ = hub.Subscribe(filter, "", 0)
+		}
+		defer subscription.Cancel()
+
+		context.Header("Cache-Control", "no-cache")
+		context.Header("X-Accel-Buffering", "no")
+		context.Status(http.StatusOK)
+
+		state := WatchState{Epoch: hub.Epoch(), Revision: hub.Revision()}
+		if reset {
+			writeWatchEvent(context, sse.Event{Event: WatchEventReset, Data: state})
+		}
+		for _, event := range replay {
+			writeWatchEvent(context, watchEvent(hub.Epoch(), event))
+		}
+		writeWatchEvent(context, sse.Event{Event: WatchEventReady, Data: state})
+
+		heartbeat := time.NewTicker(watchHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-context.Request.Context().Done():
+				return
+			case event, ok := <-subscription.Events:
+				if !ok {
+					// the client is too slow, it should reconnect with the Last-Event-ID.
+					return
+				}
+				writeWatchEvent(context, watchEvent(hub.Epoch(), event))
+			case <-heartbeat.C:
+				_, _ = context.Writer.WriteString(": heartbeat\n\n")
+				context.Writer.Flush()
+			}
+		}
+	}
+}
+
+func watchEvent(epoch string, event watch.Event) sse.Event {
+	return sse.Event{
+		Event: string(event.Type),
+		Id:    epoch + ":" + strconv.FormatUint(event.Revision, 10),
+		Data:  event,
+	}
+}
+
+func writeWatchEvent(context *gin.Context, event sse.Event) {
+	context.Render(-1, event)
+	context.Writer.Flush()
+}
@@ -0,0 +1,117 @@
+// Package health is the load state and the build version of the i18n web server, the liveness, readiness and info
+// apis report them.
+package health
+
+import (
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Version is the build version of the server, it is set by the linker like:
+//
+//	go build -ldflags "-X github.com/uberate/i18n/internal/web/health.Version=v1.2.0" ./cmd/web
+//
+// If it is not set, the module version of the build info is used.
+var Version = ""
+
+// BuildVersion return the Version, or the module version of the build info, or 'devel'.
+func BuildVersion() string {
+	if len(Version) != 0 {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && len(info.Main.Version) != 0 {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+// State is the load state of the catalog, it is safe for concurrent use.
+type State struct {
+	lock      sync.RWMutex
+	loaded    bool
+	loadedAt  time.Time
+	files     []files.LoadedFile
+	lastError error
+	failedAt  time.Time
+}
+
+// NewState return a *State which has not loaded.
+func NewState() *State {
+	return &State{}
+}
+
+// Loaded record a load of the catalog. If the err is not nil, the files of the last successful load are kept, and the
+// state is still ready if there is one.
+func (s *State) Loaded(loaded []files.LoadedFile, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err != nil {
+		s.lastError, s.failedAt = err, time.Now()
+		return
+	}
+	s.loaded, s.loadedAt, s.files, s.lastError = true, time.Now(), loaded, nil
+}
+
+// Ready return nil if the catalog has been loaded, and it has at least minKeys values. Else return the reason.
+func (s *State) Ready(instance *provider.I18n, minKeys int) error {
+	s.lock.RLock()
+	loaded, lastError := s.loaded, s.lastError
+	s.lock.RUnlock()
+
+	if !loaded {
+		if lastError != nil {
+			return fmt.Errorf("the catalog is not loaded: %v", lastError)
+		}
+		return fmt.Errorf("the catalog is not loaded")
+	}
+	if minKeys > 0 {
+		keys := 0
+		instance.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+			keys++
+		})
+		if keys < minKeys {
+			return fmt.Errorf("the catalog has %d values, want at least %d", keys, minKeys)
+		}
+	}
+	return nil
+}
+
+// Info is the build and catalog info of the server.
+type Info struct {
+	Version  string             `json:"version" yaml:"version"`
+	Files    []files.LoadedFile `json:"files" yaml:"files"`
+	Revision uint64             `json:"revision" yaml:"revision"`
+	Checksum string             `json:"checksum" yaml:"checksum"`
+	// LoadedAt is the time of the last successful load, it is null before the first load.
+	LoadedAt *time.Time `json:"loaded_at" yaml:"loaded_at"`
+	// LastError is the error of the last load if it failed, and FailedAt is the time of it.
+	LastError string     `json:"last_error,omitempty" yaml:"last_error,omitempty"`
+	FailedAt  *time.Time `json:"failed_at,omitempty" yaml:"failed_at,omitempty"`
+}
+
+// Info return the Info of the instance.
+func (s *State) Info(instance *provider.I18n) Info {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	res := Info{
+		Version:  BuildVersion(),
+		Files:    append([]files.LoadedFile{}, s.files...),
+		Revision: instance.Revision(),
+		Checksum: instance.Checksum(),
+	}
+	if s.loaded {
+		loadedAt := s.loadedAt
+		res.LoadedAt = &loadedAt
+	}
+	if s.lastError != nil {
+		failedAt := s.failedAt
+		res.LastError, res.FailedAt = s.lastError.Error(), &failedAt
+	}
+	return res
+}
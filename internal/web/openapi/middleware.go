@@ -0,0 +1,207 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ViolationCode is the code of the error response when a request violates the document.
+const ViolationCode = "openapi_violation"
+
+// Violation is an error of the drift between the document and the handlers.
+type Violation struct {
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Problems []string `json:"problems"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("openapi violation %s %s: %s", v.Method, v.Path, strings.Join(v.Problems, "; "))
+}
+
+// Validator is a middleware which validates the requests and responses by the document, it should be used in the
+// development only.
+//
+// An undocumented route or an invalid request is responded with 500 or 400 directly, and the handler will not be
+// invoked. An invalid response can't be changed, so it's logged and recorded as an error of the gin context.
+func Validator(doc *Document) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		// not found route, let gin handle it.
+		if len(context.FullPath()) == 0 {
+			context.Next()
+			return
+		}
+
+		violation := &Violation{Method: context.Request.Method, Path: PathFromGin(context.FullPath())}
+		operation, ok := doc.Operation(violation.Method, violation.Path)
+		if !ok {
+			violation.Problems = []string{"the route is not documented"}
+			abortWithViolation(context, http.StatusInternalServerError, violation)
+			return
+		}
+
+		if violation.Problems = doc.validateRequest(operation, context.Request); len(violation.Problems) != 0 {
+			abortWithViolation(context, http.StatusBadRequest, violation)
+			return
+		}
+
+		writer := &recordWriter{ResponseWriter: context.Writer}
+		context.Writer = writer
+		context.Next()
+
+		if violation.Problems = doc.validateResponse(operation, writer); len(violation.Problems) != 0 {
+			fmt.Fprintln(gin.DefaultErrorWriter, violation.Error())
+			_ = context.Error(violation)
+		}
+	}
+}
+
+func (d *Document) validateRequest(operation *Operation, request *http.Request) []string {
+	var res []string
+	for _, parameter := range operation.Parameters {
+		var value string
+		var exists bool
+		switch parameter.In {
+		case "query":
+			value, exists = request.URL.Query().Get(parameter.Name), request.URL.Query().Has(parameter.Name)
+		case "header":
+			value = request.Header.Get(parameter.Name)
+			exists = len(value) != 0
+		default:
+			continue
+		}
+		if !exists {
+			if parameter.Required {
+				res = append(res, fmt.Sprintf("missing required %s parameter %s", parameter.In, parameter.Name))
+			}
+			continue
+		}
+		res = append(res, d.Validate(parameter.Schema, parameterValue(parameter.Schema, value))...)
+	}
+
+	if operation.RequestBody == nil {
+		return res
+	}
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return append(res, err.Error())
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		if operation.RequestBody.Required {
+			res = append(res, "missing required request body")
+		}
+		return res
+	}
+	mediaType, ok := operation.RequestBody.Content["application/json"]
+	if !ok {
+		return res
+	}
+	var value interface{}
+	if err = json.Unmarshal(body, &value); err != nil {
+		return append(res, "invalid json body: "+err.Error())
+	}
+	return append(res, d.Validate(mediaType.Schema, value)...)
+}
+
+func (d *Document) validateResponse(operation *Operation, writer *recordWriter) []string {
+	status := writer.Status()
+	response, ok := operation.Responses[fmt.Sprint(status)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("the status %d is not documented", status)}
+	}
+
+	mediaType, ok := response.Content["application/json"]
+	if !ok || mediaType.Schema == nil || len(writer.Header().Get("Content-Encoding")) != 0 {
+		return nil
+	}
+	contentType := writer.Header().Get("Content-Type")
+	if !strings.HasPrefix(contentType, "application/json") {
+		// the response can be another documented media type, like an export in csv.
+		for item := range response.Content {
+			if strings.HasPrefix(contentType, item) {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("want json response, got content type %s", contentType)}
+	}
+	if writer.skipped {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(writer.body.Bytes(), &value); err != nil {
+		return []string{"invalid json response: " + err.Error()}
+	}
+	return d.Validate(mediaType.Schema, value)
+}
+
+func abortWithViolation(context *gin.Context, status int, violation *Violation) {
+	context.AbortWithStatusJSON(status, gin.H{
+		"code":    ViolationCode,
+		"message": violation.Error(),
+		"details": violation,
+	})
+}
+
+// maxRecordSize is the max size of the recorded response body, a larger body is not validated.
+const maxRecordSize = 1 << 20
+
+// recordWriter records the json response body for validation. The other bodies like the event streams and the exports
+// are not recorded, and neither is a json body larger than maxRecordSize, so a long response is not held in memory.
+type recordWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	// skipped is true when the body is not recorded.
+	skipped bool
+}
+
+func (w *recordWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordWriter) WriteString(data string) (int, error) {
+	w.record([]byte(data))
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (w *recordWriter) record(data []byte) {
+	if w.skipped {
+		return
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") ||
+		w.body.Len()+len(data) > maxRecordSize {
+		w.skipped = true
+		w.body = bytes.Buffer{}
+		return
+	}
+	w.body.Write(data)
+}
+
+// parameterValue convert the string value of a query or header parameter to the json value of the schema type. If
+// the value can't be converted, return the string, and the validation will report it.
+func parameterValue(schema *Schema, value string) interface{} {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:embed openapi.json
+var raw []byte
+
+// Raw return the OpenAPI 3 document of the server api.
+func Raw() []byte {
+	return raw
+}
+
+// Load parse the OpenAPI 3 document of the server api.
+func Load() (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Document is the part of the OpenAPI 3 document which is used to validate the requests and responses.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation return the operation of the method and the OpenAPI path like '/v1/message/ln/{ln}/{scopes}'.
+func (d *Document) Operation(method, path string) (*Operation, bool) {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil, false
+	}
+	operation, ok := item[strings.ToLower(method)]
+	return operation, ok
+}
+
+// Operations return all operations of the Document, the key is like 'GET /v1/languages'.
+func (d *Document) Operations() map[string]*Operation {
+	res := map[string]*Operation{}
+	for path, item := range d.Paths {
+		for method, operation := range item {
+			res[strings.ToUpper(method)+" "+path] = operation
+		}
+	}
+	return res
+}
+
+// PathFromGin convert the gin route path to the OpenAPI path, like '/v1/message/ln/:ln/*scopes' to
+// '/v1/message/ln/{ln}/{scopes}'.
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[index] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of the OpenAPI 3 schema object used by the server document: $ref, type, nullable, enum,
+// properties, required, items, additionalProperties and oneOf.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AdditionalProperties *Additional        `json:"additionalProperties"`
+	OneOf                []*Schema          `json:"oneOf"`
+}
+
+// Additional is the additionalProperties of Schema, it can be a bool or a Schema.
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *Additional) UnmarshalJSON(value []byte) error {
+	if bytes.Equal(value, []byte("true")) || bytes.Equal(value, []byte("false")) {
+		a.Allowed = string(value) == "true"
+		return nil
+	}
+	a.Allowed = true
+	a.Schema = &Schema{}
+	return json.Unmarshal(value, a.Schema)
+}
+
+// Validate return the problems of the json value by the schema, the value should be decoded by encoding/json. An
+// empty result means the value is valid.
+func (d *Document) Validate(schema *Schema, value interface{}) []string {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, path string) []string {
+	if schema == nil {
+		return nil
+	}
+	if len(schema.Ref) != 0 {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		target, ok := d.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", path, schema.Ref)}
+		}
+		return d.validate(target, value, path)
+	}
+
+	if value == nil {
+		if schema.Nullable || (len(schema.Type) == 0 && len(schema.OneOf) == 0) {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not allowed", path)}
+	}
+
+	if len(schema.OneOf) != 0 {
+		for _, item := range schema.OneOf {
+			if len(d.validate(item, value, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: not match any schema of oneOf", path)}
+	}
+
+	if len(schema.Enum) != 0 {
+		matched := false
+		for _, item := range schema.Enum {
+			if fmt.Sprint(item) == fmt.Sprint(value) {
+				matched = true
+			}
+		}
+		if !matched {
+			return []string{fmt.Sprintf("%s: %v is not one of %v", path, value, schema.Enum)}
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: want string, got %T", path, value)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: want number, got %T", path, value)}
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return []string{fmt.Sprintf("%s: want integer, got %v", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: want boolean, got %T", path, value)}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: want array, got %T", path, value)}
+		}
+		var res []string
+		for index, item := range items {
+			res = append(res, d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, index))...)
+		}
+		return res
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: want object, got %T", path, value)}
+		}
+		return d.validateObject(schema, object, path)
+	default:
+		return []string{fmt.Sprintf("%s: unknown schema type %s", path, schema.Type)}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]interface{}, path string) []string {
+	var res []string
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			res = append(res, fmt.Sprintf("%s: missing required property %s", path, name))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := schema.Properties[name]; ok {
+			res = append(res, d.validate(property, object[name], propertyPath)...)
+			continue
+		}
+		if schema.AdditionalProperties == nil {
+			// additionalProperties is true by default.
+			continue
+		}
+		if !schema.AdditionalProperties.Allowed {
+			res = append(res, fmt.Sprintf("%s: unknown property", propertyPath))
+			continue
+		}
+		res = append(res, d.validate(schema.AdditionalProperties.Schema, object[name], propertyPath)...)
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/handler"
+	"github.com/uberate/i18n/internal/web/project"
+	"net/http"
+	"path/filepath"
+)
+
+// projectFactory return the project.Factory of the server. The apis of a project are the apis of the default catalog
+// with the project config, the audit log and the snapshots of it are in its dir when they are enabled by the server.
+// The metrics of a project are registered to the registerer with the label 'project'.
+func projectFactory(serverConfig config.I18nConfig, registerer prometheus.Registerer) project.Factory {
+	return func(p *project.Project, dir string) (projectHandler http.Handler, closeFunc func() error, err error) {
+		projectConfig := serverConfig
+		app := &projectConfig.ApplicationConfig
+		app.Files = p.Config.Files
+		app.Readonly = app.Readonly || p.Config.Readonly
+		if p.Config.Auth.Enabled {
+			app.Auth = p.Config.Auth
+		}
+		// the CORS headers are added by the server, and the gRPC api serves the default catalog only.
+		app.CORS, app.GRPC, app.Projects = config.CORSConfig{}, config.GRPCConfig{}, config.ProjectsConfig{}
+		if len(app.Audit.File) != 0 {
+			app.Audit.File = ""
+			if len(dir) != 0 {
+				app.Audit.File = filepath.Join(dir, "audit.log")
+			}
+		}
+		if len(app.Snapshots.Dir) != 0 {
+			app.Snapshots.Dir = ""
+			if len(dir) != 0 {
+				app.Snapshots.Dir = filepath.Join(dir, "snapshots")
+			}
+		}
+
+		// registerHandler panics on the errors of the config like an invalid htpasswd file.
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("%v", recovered)
+			}
+		}()
+		engine := gin.New()
+		engine.NoRoute(handler.NotFound())
+		projectRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{"project": p.Config.ID}, registerer)
+		services := registerHandler(engine, projectConfig, p.Instance(), projectRegisterer)
+		services.Loaded(p.Store.Loaded(), nil)
+
+		cancel := func() {}
+		if interval := config.Duration(app.Reload.Interval); interval > 0 {
+			cancel = p.Store.Watch(interval, nil, services.Loaded)
+		}
+		return engine, func() error {
+			cancel()
+			return services.Close()
+		}, nil
+	}
+}
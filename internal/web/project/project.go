@@ -0,0 +1,262 @@
+// Package project is the projects of the web server, every project is a catalog of an app with its own files,
+// language standard, read-only mode and auth. The server serves the apis of a project under its id.
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/storage"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("project not found")
+	ErrExists   = errors.New("project exists")
+	ErrStatic   = errors.New("project is defined by the config, it can't be deleted")
+)
+
+const (
+	// configFile is the file of a project created by the apis in its dir.
+	configFile = "project.json"
+	// storageFile is the storage file of the changes of a project in its dir.
+	storageFile = "i18n.json"
+)
+
+// Project is a catalog hosted by the server.
+type Project struct {
+	Config config.ProjectConfig
+	// Static is true if the project is defined by the config.
+	Static    bool
+	CreatedAt time.Time
+	Store     *storage.Store
+	// Handler serves the apis of the project, the path is the path of the same api of the default catalog, like
+	// '/v1/bundle/en/user'.
+	Handler http.Handler
+
+	close func() error
+}
+
+// Instance return the catalog of the project.
+func (p *Project) Instance() *provider.I18n {
+	return p.Store.Instance()
+}
+
+// Factory return the Handler of the project, and a func which releases it like closing the audit log. The dir is the
+// dir of the project, empty means the project is kept in memory only.
+type Factory func(project *Project, dir string) (handler http.Handler, close func() error, err error)
+
+// record is the content of the configFile.
+type record struct {
+	Config    config.ProjectConfig `json:"config"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// Registry is the projects of the server, it is safe for concurrent use.
+type Registry struct {
+	dir     string
+	delay   time.Duration
+	factory Factory
+	onError func(err error)
+
+	lock     sync.RWMutex
+	projects map[string]*Project
+}
+
+// Open return a *Registry with the projects of the config, and the projects created by the apis which are kept in the
+// dir of the config. The errors of the delayed writes of the storage files are passed to onError, it can be nil.
+func Open(serverConfig config.I18nConfig, factory Factory, onError func(err error)) (*Registry, error) {
+	projectsConfig := serverConfig.ApplicationConfig.Projects
+	r := &Registry{
+		dir:      projectsConfig.Dir,
+		delay:    config.Duration(serverConfig.ApplicationConfig.Storage.FlushInterval),
+		factory:  factory,
+		onError:  onError,
+		projects: map[string]*Project{},
+	}
+
+	now := time.Now()
+	for _, item := range projectsConfig.Items {
+		if err := r.open(item, true, now); err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+	}
+	if len(r.dir) == 0 {
+		return r, nil
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil && !os.IsNotExist(err) {
+		_ = r.Close()
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, ok := r.projects[entry.Name()]; ok || !entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(r.dir, entry.Name(), configFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		item := record{}
+		if err == nil {
+			err = json.Unmarshal(content, &item)
+		}
+		if err == nil {
+			err = r.open(item.Config, false, item.CreatedAt)
+		}
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("project %s: %v", entry.Name(), err)
+		}
+	}
+	return r, nil
+}
+
+// Get return the project of the id.
+func (r *Registry) Get(id string) (*Project, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	p, ok := r.projects[id]
+	return p, ok
+}
+
+// List return all projects sorted by the id.
+func (r *Registry) List() []*Project {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	res := make([]*Project, 0, len(r.projects))
+	for _, p := range r.projects {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		return res[a].Config.ID < res[b].Config.ID
+	})
+	return res
+}
+
+// Create add a project, it is kept in the dir of the projects. It returns a config.ValidationError if the config is
+// invalid, or ErrExists.
+func (r *Registry) Create(projectConfig config.ProjectConfig) (*Project, error) {
+	if err := projectConfig.Validate(); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.projects[projectConfig.ID]; ok {
+		return nil, ErrExists
+	}
+
+	now := time.Now()
+	if len(r.dir) != 0 {
+		dir := filepath.Join(r.dir, projectConfig.ID)
+		content, err := json.MarshalIndent(record{Config: projectConfig, CreatedAt: now}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		// the config has the secrets of the auth.
+		if err = os.WriteFile(filepath.Join(dir, configFile), content, 0600); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.openLocked(projectConfig, false, now); err != nil {
+		if len(r.dir) != 0 {
+			_ = os.RemoveAll(filepath.Join(r.dir, projectConfig.ID))
+		}
+		return nil, err
+	}
+	return r.projects[projectConfig.ID], nil
+}
+
+// Delete remove the project and its dir. It returns ErrNotFound, or ErrStatic if the project is defined by the config.
+func (r *Registry) Delete(id string) error {
+	r.lock.Lock()
+	p, ok := r.projects[id]
+	if ok && !p.Static {
+		delete(r.projects, id)
+	}
+	r.lock.Unlock()
+
+	switch {
+	case !ok:
+		return ErrNotFound
+	case p.Static:
+		return ErrStatic
+	}
+	err := p.close()
+	if len(r.dir) != 0 {
+		if removeErr := os.RemoveAll(filepath.Join(r.dir, id)); removeErr != nil {
+			return removeErr
+		}
+	}
+	return err
+}
+
+// Close release all projects and write their pending changes, it returns the first error.
+func (r *Registry) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var res error
+	for id, p := range r.projects {
+		if err := p.close(); err != nil && res == nil {
+			res = fmt.Errorf("project %s: %v", id, err)
+		}
+	}
+	r.projects = map[string]*Project{}
+	return res
+}
+
+func (r *Registry) open(projectConfig config.ProjectConfig, static bool, createdAt time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.openLocked(projectConfig, static, createdAt)
+}
+
+// openLocked load the catalog of the project and create its handler, it should be invoked with the lock.
+func (r *Registry) openLocked(projectConfig config.ProjectConfig, static bool, createdAt time.Time) error {
+	if len(projectConfig.Standard) == 0 {
+		projectConfig.Standard = provider.ISO6391
+	}
+	dir, file := "", ""
+	if len(r.dir) != 0 {
+		dir = filepath.Join(r.dir, projectConfig.ID)
+		file = filepath.Join(dir, storageFile)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	store, err := storage.Open(projectConfig.Standard, projectConfig.Files, file, r.delay, r.onError)
+	if err != nil {
+		return err
+	}
+	p := &Project{Config: projectConfig, Static: static, CreatedAt: createdAt, Store: store}
+	handler, closeHandler, err := r.factory(p, dir)
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+	p.Handler = handler
+	p.close = func() error {
+		err := closeHandler()
+		if storeErr := store.Close(); storeErr != nil {
+			return storeErr
+		}
+		return err
+	}
+	r.projects[projectConfig.ID] = p
+	return nil
+}
@@ -0,0 +1,70 @@
+package project
+
+import (
+	"github.com/uberate/i18n/cmd/web/config"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testFactory(project *Project, dir string) (http.Handler, func() error, error) {
+	return http.NotFoundHandler(), func() error { return nil }, nil
+}
+
+func TestRegistry(t *testing.T) {
+	c := config.Default()
+	c.ApplicationConfig.Projects.Dir = t.TempDir()
+	c.ApplicationConfig.Projects.Items = []config.ProjectConfig{{ID: "shop"}}
+
+	r, err := Open(c, testFactory, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Create(config.ProjectConfig{ID: "blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Create(config.ProjectConfig{ID: "shop"}); err != ErrExists {
+		t.Errorf("Create of an existing id get: %v, want: %v", err, ErrExists)
+	}
+	if _, err = r.Create(config.ProjectConfig{ID: "Bad Id"}); err == nil {
+		t.Error("Create of an invalid id should fail")
+	}
+	if err = r.Delete("shop"); err != ErrStatic {
+		t.Errorf("Delete of a static project get: %v, want: %v", err, ErrStatic)
+	}
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the created project is loaded from the dir again.
+	r, err = Open(c, testFactory, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if ids := projectIDs(r.List()); len(ids) != 2 || ids[0] != "blog" || ids[1] != "shop" {
+		t.Fatalf("List get: %v, want: [blog shop]", ids)
+	}
+	if p, _ := r.Get("blog"); p.Static || p.CreatedAt.IsZero() {
+		t.Errorf("the created project get static: %v, created at: %v", p.Static, p.CreatedAt)
+	}
+
+	if err = r.Delete("blog"); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Delete("blog"); err != ErrNotFound {
+		t.Errorf("Delete of a deleted project get: %v, want: %v", err, ErrNotFound)
+	}
+	if _, err = os.Stat(filepath.Join(c.ApplicationConfig.Projects.Dir, "blog")); !os.IsNotExist(err) {
+		t.Errorf("the dir of the deleted project should be removed, get: %v", err)
+	}
+}
+
+func projectIDs(projects []*Project) []string {
+	res := make([]string, 0, len(projects))
+	for _, p := range projects {
+		res = append(res, p.Config.ID)
+	}
+	return res
+}
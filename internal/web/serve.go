@@ -0,0 +1,80 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/certs"
+	"net"
+	"net/http"
+)
+
+// Certificates return the *certs.Reloader of application_config.tls, the rotated files are reloaded every
+// application_config.tls.reload_interval until the cancel func is invoked. It returns nil when the tls is not set. The
+// reloader is shared by the REST and gRPC apis, so both serve the same certificate.
+func Certificates(serverConfig config.I18nConfig) (reloader *certs.Reloader, cancel func(), err error) {
+	tlsConfig := serverConfig.ApplicationConfig.TLS
+	if len(tlsConfig.CertFile) == 0 {
+		return nil, func() {}, nil
+	}
+	reloader, err = certs.New(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tls: %v", err)
+	}
+	cancel = func() {}
+	if interval := config.Duration(tlsConfig.ReloadInterval); interval > 0 {
+		cancel = reloader.Watch(interval, func(err error) {
+			fmt.Fprintln(gin.DefaultErrorWriter, "tls reload:", err)
+		})
+	}
+	return reloader, cancel, nil
+}
+
+// Serve serve the handler on the listener until the ctx is done, then shut down gracefully: the listener is closed, the
+// onShutdown funcs are invoked to end the long-lived requests like the watch streams, and the in-flight requests are
+// drained in application_config.shutdown_timeout. The connections which are still active after it are closed.
+//
+// The server serves https with the certificate of the reloader, and plain http when it is nil, see Certificates. It
+// returns nil after a graceful shutdown.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler, serverConfig config.I18nConfig,
+	reloader *certs.Reloader, onShutdown ...func()) error {
+	server := &http.Server{Handler: handler}
+	for _, f := range onShutdown {
+		server.RegisterOnShutdown(f)
+	}
+
+	serve := func() error {
+		return server.Serve(listener)
+	}
+	if reloader != nil {
+		server.TLSConfig = reloader.TLSConfig()
+		serve = func() error {
+			return server.ServeTLS(listener, "", "")
+		}
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		config.Duration(serverConfig.ApplicationConfig.ShutdownTimeout))
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		_ = server.Close()
+	}
+	if serveErr := <-served; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
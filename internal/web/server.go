@@ -1,35 +1,292 @@
 package web
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/audit"
+	"github.com/uberate/i18n/internal/web/auth"
+	"github.com/uberate/i18n/internal/web/catalog"
 	"github.com/uberate/i18n/internal/web/handler"
+	"github.com/uberate/i18n/internal/web/health"
+	"github.com/uberate/i18n/internal/web/openapi"
+	"github.com/uberate/i18n/internal/web/project"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/metrics"
 	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/search"
+	"github.com/uberate/i18n/pkg/snapshot"
+	"github.com/uberate/i18n/pkg/watch"
+	"net/http"
 )
 
 var currentVersion = "v1"
+var nextVersion = "v2"
+
+// projectMethods is the methods of the project apis of the versions.
+var projectMethods = map[string][]string{
+	currentVersion: {http.MethodGet, http.MethodPost, http.MethodDelete},
+	nextVersion:    {http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+}
+
+// Services is the services which are created by RegisterHandler, the other apis of the same instance like the gRPC
+// api share them with the REST api.
+type Services struct {
+	Authenticator *auth.Authenticator
+	// Audit is nil when the audit log is disabled.
+	Audit *audit.Logger
+	Watch *watch.Hub
+	// Search is the search index of the catalog, it follows the changes until Close.
+	Search *search.Index
+	// Metrics is registered to the registry which is served by '/metrics', the metrics of a project have the label
+	// 'project'.
+	Metrics *metrics.Metrics
+	// Health is the load state of the catalog, the server is not ready until a load is recorded by Loaded.
+	Health *health.State
+	// Projects is the projects whose apis are served under '/v1/projects/:project', it should be closed on shutdown to
+	// write their pending changes.
+	Projects *project.Registry
+
+	// stopSnapshots stops the automatic snapshots, and stopMetrics unregisters the Metrics.
+	stopSnapshots func()
+	stopMetrics   func()
+}
+
+// Close release the services and write the pending changes of the projects.
+func (s *Services) Close() error {
+	s.stopSnapshots()
+	s.stopMetrics()
+	s.Watch.Close()
+	s.Search.Close()
+	var res error
+	if s.Projects != nil {
+		res = s.Projects.Close()
+	}
+	if s.Audit != nil {
+		if err := s.Audit.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// Loaded record a load of the catalog, a nil err means success.
+func (s *Services) Loaded(loaded []files.LoadedFile, err error) {
+	s.Health.Loaded(loaded, err)
+	s.Metrics.ObserveReload(err)
+}
+
+// RegisterHandler register all apis to the engine, and return the Services of them. All routes must be described in the
+// OpenAPI document 'openapi/openapi.json', the server test checks it.
+//
+// Every route requires a scope when the auth is enabled: the read apis require 'read', the apis which change the
+// messages require 'write', and the apis which manage the server require 'admin'.
+func RegisterHandler(engine *gin.Engine, config config.I18nConfig, i18nInstance *provider.I18n) *Services {
+	return registerHandler(engine, config, i18nInstance, nil)
+}
+
+// registerHandler is RegisterHandler. The registerer is nil for the server, and it is the registerer of the project
+// metrics for the apis of a project: they don't have the projects, '/metrics' and the health apis, because only the
+// '/v1' and '/v2' apis of a project are forwarded.
+func registerHandler(engine *gin.Engine, config config.I18nConfig, i18nInstance *provider.I18n,
+	registerer prometheus.Registerer) *Services {
+	engine.Use(handler.RequestID())
+
+	// the metrics of the requests, the lookups and the catalog, they are served by '/metrics' with the metrics of the
+	// projects.
+	withProjects := registerer == nil
+	registry, projectMetrics := prometheus.NewRegistry(), prometheus.NewRegistry()
+	if withProjects {
+		registry.MustRegister(collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		registerer = registry
+	}
+	serverMetrics := metrics.New(registerer)
+	stopInstrument := serverMetrics.Instrument(i18nInstance)
+	stopMetrics := func() {
+		stopInstrument()
+		serverMetrics.Unregister()
+	}
+	engine.Use(handler.Metrics(serverMetrics))
+
+	// the CORS headers of the browser clients, the preflight requests are answered before the auth.
+	if len(config.ApplicationConfig.CORS.AllowOrigins) != 0 {
+		engine.Use(handler.CORS(config.ApplicationConfig.CORS))
+	}
+
+	if config.ApplicationConfig.ValidateOpenAPI {
+		doc, err := openapi.Load()
+		if err != nil {
+			panic(err)
+		}
+		engine.Use(openapi.Validator(doc))
+	}
+
+	// record all missed messages, the translators can get them from the missing api. A missed message falls back to
+	// the fallback languages of config, and to the placeholder unless the server responds 404.
+	missingCollector := provider.NewMissingCollector(config.ApplicationConfig.MissingCollectLimit)
+	missingHandlers := []provider.MissingHandler{i18nInstance.MissingHandler(), missingCollector.Collect,
+		catalog.FallbackHandler(config, i18nInstance)}
+	if !config.ApplicationConfig.NotFoundWith404 {
+		missingHandlers = append(missingHandlers, provider.PlaceholderHandler(catalog.Placeholder))
+	}
+	i18nInstance.SetMissingHandler(provider.ChainMissingHandlers(missingHandlers...))
+
+	authenticator, err := auth.New(config.ApplicationConfig.Auth)
+	if err != nil {
+		panic(err)
+	}
+
+	// record all message changes when the audit log is enabled.
+	var auditLogger *audit.Logger
+	if auditConfig := config.ApplicationConfig.Audit; len(auditConfig.File) != 0 {
+		if auditLogger, err = audit.Open(auditConfig.File, auditConfig.MaxSize, auditConfig.MaxBackups); err != nil {
+			panic(err)
+		}
+	}
+	engine.Use(handler.Audit(auditLogger))
+
+	var snapshotStore *snapshot.Store
+	stopSnapshots := func() {}
+	if snapshotConfig := config.ApplicationConfig.Snapshots; len(snapshotConfig.Dir) != 0 {
+		if snapshotStore, err = snapshot.Open(snapshotConfig.Dir, snapshotConfig.Format); err != nil {
+			panic(err)
+		}
+		stopSnapshots = snapshotStore.Auto(i18nInstance, snapshotConfig.Every, snapshotConfig.KeepAuto, func(err error) {
+			fmt.Fprintln(gin.DefaultErrorWriter, "snapshot:", err)
+		})
+	}
+
+	// the buffer of every watch client, a client which falls behind more than it should reconnect.
+	watchHub := watch.NewHub(i18nInstance, config.ApplicationConfig.WatchHistory, 256)
+	searchIndex := search.NewIndex(i18nInstance)
+
+	read := authenticator.Require(auth.ScopeRead)
+	write := authenticator.Require(auth.ScopeWrite)
+	admin := authenticator.Require(auth.ScopeAdmin)
+
+	conditional := handler.Conditional(config, i18nInstance, watchHub)
+	// the representations of the negotiated routes are selected by the request headers.
+	conditionalLanguage := handler.Conditional(config, i18nInstance, watchHub, "Accept-Language")
+	conditionalFormat := handler.Conditional(config, i18nInstance, watchHub, "Accept")
+
+	var projectRegistry *project.Registry
+	if withProjects {
+		if projectRegistry, err = project.Open(config, projectFactory(config, projectMetrics), func(err error) {
+			fmt.Fprintln(gin.DefaultErrorWriter, "project:", err)
+		}); err != nil {
+			panic(err)
+		}
+	}
+
+	healthState := health.NewState()
+	if withProjects {
+		engine.GET("healthz", handler.Healthz(config, i18nInstance))
+		engine.GET("readyz", handler.Readyz(config, i18nInstance, healthState))
+		engine.GET("metrics", read, handler.MetricsGet(config, i18nInstance,
+			prometheus.Gatherers{registry, projectMetrics}))
+	}
 
-func RegisterHandler(engine *gin.Engine, config config.I18nConfig, i18nInstance *provider.I18n) {
 	v1 := engine.Group(currentVersion)
 	{
 		message := v1.Group("message")
 		{
-			message.GET("ln/:ln/*scopes", handler.MessageGet(config, i18nInstance))
+			message.GET("ln/:ln/*scopes", read, conditional, handler.MessageGet(config, i18nInstance))
+			message.GET("negotiate/*scopes", read, conditionalLanguage, handler.MessageNegotiate(config, i18nInstance))
 			if !config.ApplicationConfig.Readonly {
-				message.DELETE("/ln/:ln/*scopes", handler.MessageDelete(config, i18nInstance))
-				message.POST("/ln/:ln/msg/:msg/*scopes", handler.MessageCreate(config, i18nInstance))
+				message.DELETE("/ln/:ln/*scopes", write, handler.MessageDelete(config, i18nInstance))
+				message.POST("/ln/:ln/msg/:msg/*scopes", write, handler.MessageCreate(config, i18nInstance))
 			}
 		}
 
 		languages := v1.Group("language")
-		v1.GET("languages", handler.LanguageList(config, i18nInstance))
+		v1.GET("languages", read, handler.LanguageList(config, i18nInstance))
+		{
+			languages.GET("/standards", read, handler.StandardList(config, i18nInstance))
+			languages.GET("/:language", read, handler.LanguageGet(config, i18nInstance))
+		}
+		v1.GET("bundle/:ln/*scopes", read, conditional, handler.BundleGet(config, i18nInstance))
+		v1.GET("watch", read, handler.Watch(config, i18nInstance, watchHub))
+		v1.GET("missing", read, handler.MissingList(config, i18nInstance, missingCollector))
+		v1.GET("stats", read, handler.StatsGet(config, i18nInstance))
+		v1.GET("search", read, handler.Search(config, i18nInstance, searchIndex))
+		v1.GET("keys", read, conditional, handler.KeyList(config, i18nInstance, searchIndex))
+		v1.GET("export", read, conditionalFormat, handler.Export(config, i18nInstance))
+		if !config.ApplicationConfig.Readonly {
+			v1.POST("import", write, handler.Import(config, i18nInstance))
+		}
+		v1.GET("openapi.json", handler.OpenAPIGet(config, i18nInstance))
+		v1.GET("info", read, handler.InfoGet(config, i18nInstance, healthState))
+
+		authGroup := v1.Group("auth")
 		{
-			languages.GET("/standards", handler.StandardList(config, i18nInstance))
-			languages.GET("/:language", handler.LanguageGet(config, i18nInstance))
+			authGroup.GET("/whoami", read, handler.Whoami(config, i18nInstance))
+			authGroup.POST("/token", admin, handler.TokenCreate(config, i18nInstance, authenticator))
+		}
+		if auditLogger != nil {
+			v1.GET("audit", admin, handler.AuditList(config, i18nInstance, auditLogger))
+		}
+		if snapshotStore != nil {
+			snapshots := v1.Group("snapshots")
+			snapshots.GET("", read, handler.SnapshotList(config, i18nInstance, snapshotStore))
+			snapshots.GET("/:name", read, handler.SnapshotGet(config, i18nInstance, snapshotStore))
+			snapshots.GET("/:name/diff/:other", read, handler.SnapshotDiff(config, i18nInstance, snapshotStore))
+			snapshots.POST("", admin, handler.SnapshotCreate(config, i18nInstance, snapshotStore))
+			if !config.ApplicationConfig.Readonly {
+				snapshots.POST("/:name/rollback", admin, handler.SnapshotRollback(config, i18nInstance, snapshotStore))
+			}
+		}
+
+		if projectRegistry != nil {
+			projects := v1.Group("projects")
+			projects.GET("", read, handler.ProjectList(config, i18nInstance, projectRegistry))
+			projects.POST("", admin, handler.ProjectCreate(config, i18nInstance, projectRegistry))
+			projects.GET("/:project", read, handler.ProjectGet(config, i18nInstance, projectRegistry))
+			projects.DELETE("/:project", admin, handler.ProjectDelete(config, i18nInstance, projectRegistry))
+			// the project apis authenticate the requests by the auth of the project.
+			forward := handler.ProjectForward(config, i18nInstance, projectRegistry, currentVersion)
+			for _, method := range projectMethods[currentVersion] {
+				projects.Handle(method, "/:project/*path", forward)
+			}
 		}
+
 		ins := v1.Group("instance")
-		ins.GET("/", handler.InstanceGet(config, i18nInstance))
+		ins.GET("/", read, conditional, handler.InstanceGet(config, i18nInstance))
+		{
+		}
+	}
+
+	v2 := engine.Group(nextVersion)
+	{
+		messages := v2.Group("messages")
 		{
+			messages.GET("/*scopes", read, conditional, handler.MessageGetV2(config, i18nInstance))
+			if !config.ApplicationConfig.Readonly {
+				messages.POST("", write, handler.MessageCreateV2(config, i18nInstance))
+				messages.PUT("", write, handler.MessageUpsertV2(config, i18nInstance))
+				messages.DELETE("/*scopes", write, handler.MessageDeleteV2(config, i18nInstance))
+				messages.POST("/batch", write, handler.MessageBatchV2(config, i18nInstance))
+			}
 		}
+		if projectRegistry != nil {
+			forward := handler.ProjectForward(config, i18nInstance, projectRegistry, nextVersion)
+			for _, method := range projectMethods[nextVersion] {
+				v2.Handle(method, "projects/:project/*path", forward)
+			}
+		}
+	}
+
+	return &Services{
+		Authenticator: authenticator,
+		Audit:         auditLogger,
+		Watch:         watchHub,
+		Search:        searchIndex,
+		Metrics:       serverMetrics,
+		Health:        healthState,
+		Projects:      projectRegistry,
+		stopSnapshots: stopSnapshots,
+		stopMetrics:   stopMetrics,
 	}
 }
@@ -0,0 +1,1035 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web/audit"
+	"github.com/uberate/i18n/internal/web/handler"
+	"github.com/uberate/i18n/internal/web/health"
+	"github.com/uberate/i18n/internal/web/openapi"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestInstance() *provider.I18n {
+	instance := provider.NewI18n(provider.ISO6391)
+	p := instance.Pusher("user", "text", "login")
+	p(provider.EnglishLn, "Login")
+	p(provider.ChineseLn, "登录")
+	instance.Pusher("user", "text", "logout")(provider.EnglishLn, "Logout")
+	return instance
+}
+
+// newCanceledRequest return a request whose client has gone away, the streaming apis return after the buffered events.
+func newCanceledRequest(method, url string) *http.Request {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return httptest.NewRequest(method, url, nil).WithContext(ctx)
+}
+
+// newMultipartBody return the multipart body which uploads the content as the file, and its content type.
+func newMultipartBody(t *testing.T, name, content string) (string, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", name)
+	if err == nil {
+		_, err = part.Write([]byte(content))
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), writer.FormDataContentType()
+}
+
+func newTestConfig(t *testing.T) config.I18nConfig {
+	c := config.I18nConfig{}
+	c.ApplicationConfig.Audit.File = filepath.Join(t.TempDir(), "audit.log")
+	c.ApplicationConfig.Snapshots.Dir = filepath.Join(t.TempDir(), "snapshots")
+	c.ApplicationConfig.DefaultLanguage = "en"
+	c.ApplicationConfig.ValidateOpenAPI = true
+	c.ApplicationConfig.WatchHistory = 100
+	c.ApplicationConfig.Auth.TokenSecret = "secret"
+	return c
+}
+
+// TestOpenAPIRoutes checks all routes are documented, and all documented operations are registered.
+func TestOpenAPIRoutes(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operations := doc.Operations()
+	for _, route := range engine.Routes() {
+		key := route.Method + " " + openapi.PathFromGin(route.Path)
+		if _, ok := operations[key]; !ok {
+			t.Errorf("Route [%s] is not documented", key)
+		}
+		delete(operations, key)
+	}
+	for key := range operations {
+		t.Errorf("Operation [%s] is documented, but not registered", key)
+	}
+}
+
+// TestOpenAPIContract sends requests to every route with the validator, and checks no violation found.
+func TestOpenAPIContract(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+
+	covered := map[string]bool{}
+	engine.Use(func(context *gin.Context) {
+		context.Next()
+		covered[context.Request.Method+" "+context.FullPath()] = true
+		for _, err := range context.Errors {
+			t.Errorf("%s %s: %v", context.Request.Method, context.Request.URL, err)
+		}
+	})
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	csvBody, csvType := newMultipartBody(t, "i18n.csv", "key,en\nuser.text.login,Sign in\n")
+	poBody, poType := newMultipartBody(t, "upload", "msgid \"\"\nmsgstr \"Language: ja\\n\"\n\nmsgid \"user.a\"\nmsgstr \"A\"\n")
+	cases := []struct {
+		method string
+		url    string
+		body   string
+		header map[string]string
+		status int
+	}{
+		{"GET", "/v1/message/ln/english/user/text/login", "", nil, http.StatusOK},
+		{"GET", "/v1/message/negotiate/user/text/login", "", map[string]string{"Accept-Language": "zh-CN"}, http.StatusOK},
+		{"POST", "/v1/message/ln/japanese/msg/ログイン/user/text/login", "", nil, http.StatusOK},
+		{"DELETE", "/v1/message/ln/japanese/user/text/login", "", nil, http.StatusOK},
+		{"GET", "/v1/languages", "", nil, http.StatusOK},
+		{"GET", "/v1/language/standards", "", nil, http.StatusOK},
+		{"GET", "/v1/language/english", "", nil, http.StatusOK},
+		{"GET", "/v1/bundle/zh/user", "", nil, http.StatusOK},
+		{"GET", "/v1/bundle/zh/user?format=unknown", "", nil, http.StatusBadRequest},
+		{"GET", "/v1/bundle/zh/none", "", nil, http.StatusNotFound},
+		{"GET", "/v1/missing", "", nil, http.StatusOK},
+		{"GET", "/v1/missing?format=skeleton", "", nil, http.StatusOK},
+		{"GET", "/v1/stats", "", nil, http.StatusOK},
+		{"GET", "/v1/search?q=LOG&language=english&scope=user&offset=0&limit=10", "", nil, http.StatusOK},
+		{"GET", "/v1/search?q=text%5C.log&mode=regex&in=scope&case_sensitive=true", "", nil, http.StatusOK},
+		{"GET", "/v1/search?q=%28&mode=regex", "", nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/search?in=language", "", nil, http.StatusBadRequest},
+		{"GET", "/v1/keys?scope=user&language=english&sort=-value&limit=1", "", nil, http.StatusOK},
+		{"GET", "/v1/keys?sort=value", "", nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/keys?cursor=invalid", "", nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/keys", "", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"GET", "/v1/export?scope=user.text&language=zh", "", nil, http.StatusOK},
+		{"GET", "/v1/export?format=JSON", "", map[string]string{"Accept": "text/csv"}, http.StatusOK},
+		{"GET", "/v1/export", "", map[string]string{"Accept": "text/html"}, http.StatusNotAcceptable},
+		{"GET", "/v1/export?format=exe", "", nil, http.StatusBadRequest},
+		{"GET", "/v1/export?scope=none", "", nil, http.StatusNotFound},
+		{"GET", "/v1/export?format=csv", "", nil, http.StatusOK},
+		{"GET", "/v1/export", "", map[string]string{"Accept": "application/xliff+xml"}, http.StatusOK},
+		{"POST", "/v1/import?strategy=only-missing&dry_run=true", csvBody, map[string]string{"Content-Type": csvType},
+			http.StatusOK},
+		{"POST", "/v1/import?strategy=replace-subtree&scope=user&dry_run=true", poBody,
+			map[string]string{"Content-Type": poType}, http.StatusOK},
+		{"POST", "/v1/import?format=exe", csvBody, map[string]string{"Content-Type": csvType},
+			http.StatusUnsupportedMediaType},
+		{"POST", "/v1/import?format=json", csvBody, map[string]string{"Content-Type": csvType},
+			http.StatusUnprocessableEntity},
+		{"POST", "/v1/import", "", nil, http.StatusBadRequest},
+		{"GET", "/v1/instance/", "", nil, http.StatusOK},
+		{"GET", "/v1/instance/", "", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"GET", "/v1/openapi.json", "", nil, http.StatusOK},
+		{"GET", "/metrics", "", nil, http.StatusOK},
+		{"GET", "/healthz", "", nil, http.StatusOK},
+		{"GET", "/readyz", "", nil, http.StatusServiceUnavailable},
+		{"GET", "/v1/info", "", nil, http.StatusOK},
+		{"GET", "/v1/auth/whoami", "", nil, http.StatusOK},
+		{"POST", "/v1/auth/token", `{"scopes":["read"],"ttl":"1h"}`, nil, http.StatusCreated},
+		{"POST", "/v1/auth/token", `{"scopes":[]}`, nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/audit?scope=user.text&language=japanese&since=2020-01-01T00:00:00Z&limit=10", "", nil, http.StatusOK},
+		{"GET", "/v1/audit?until=yesterday", "", nil, http.StatusUnprocessableEntity},
+		{"POST", "/v1/snapshots", `{"name":"v1.0","description":"first release"}`, nil, http.StatusCreated},
+		{"POST", "/v1/snapshots", `{"name":"v1.0"}`, nil, http.StatusConflict},
+		{"POST", "/v1/snapshots", `{"name":"live"}`, nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/snapshots", "", nil, http.StatusOK},
+		{"GET", "/v1/snapshots/v1.0", "", nil, http.StatusOK},
+		{"GET", "/v1/snapshots/none", "", nil, http.StatusNotFound},
+		{"GET", "/v1/snapshots/v1.0/diff/live", "", nil, http.StatusOK},
+		{"POST", "/v1/snapshots/v1.0/rollback", "", nil, http.StatusOK},
+		{"POST", "/v2/messages", `{"scopes":["user","text","help"],"language":"en","value":"Help"}`, nil, http.StatusCreated},
+		{"POST", "/v2/messages", `{"scopes":["user","text","help"],"language":"en","value":"Help"}`, nil, http.StatusConflict},
+		{"POST", "/v2/messages", `{"scopes":"user","language":"en"}`, nil, http.StatusBadRequest},
+		{"PUT", "/v2/messages", `{"scopes":["user","text","help"],"language":"zh","value":"帮助"}`, nil, http.StatusCreated},
+		{"PUT", "/v2/messages", `{"scopes":["user"],"language":"zh"}`, nil, http.StatusUnprocessableEntity},
+		{"GET", "/v2/messages/user/text/help", "", nil, http.StatusOK},
+		{"GET", "/v2/messages/user/text/help?language=zh", "", nil, http.StatusOK},
+		{"GET", "/v2/messages/user/none", "", nil, http.StatusNotFound},
+		{"DELETE", "/v2/messages/user/text/help?language=zh", "", nil, http.StatusNoContent},
+		{"DELETE", "/v2/messages/user/text/help", "", nil, http.StatusBadRequest},
+		{"POST", "/v2/messages/batch", `{"upserts":[{"scopes":["a"],"language":"en","value":"A"}],"deletes":[]}`, nil,
+			http.StatusOK},
+		{"POST", "/v1/projects", `{"id":"shop","standard":"ISO 639-1"}`, nil, http.StatusCreated},
+		{"POST", "/v1/projects", `{"id":"shop"}`, nil, http.StatusConflict},
+		{"POST", "/v1/projects", `{"id":"Shop Web"}`, nil, http.StatusUnprocessableEntity},
+		{"GET", "/v1/projects", "", nil, http.StatusOK},
+		{"GET", "/v1/projects/shop", "", nil, http.StatusOK},
+		{"GET", "/v1/projects/none", "", nil, http.StatusNotFound},
+		{"POST", "/v1/projects/shop/message/ln/english/msg/Cart/shop/cart", "", nil, http.StatusOK},
+		{"GET", "/v1/projects/shop/message/ln/english/shop/cart", "", nil, http.StatusOK},
+		{"GET", "/v1/projects/none/message/ln/english/shop/cart", "", nil, http.StatusNotFound},
+		{"DELETE", "/v1/projects/shop/message/ln/english/shop/cart", "", nil, http.StatusOK},
+		{"PUT", "/v2/projects/shop/messages", `{"scopes":["shop","pay"],"language":"en","value":"Pay"}`, nil,
+			http.StatusCreated},
+		{"POST", "/v2/projects/shop/messages", `{"scopes":["shop","pay"],"language":"en","value":"Pay"}`, nil,
+			http.StatusConflict},
+		{"GET", "/v2/projects/shop/messages/shop/pay", "", nil, http.StatusOK},
+		{"GET", "/v2/projects/shop/unknown", "", nil, http.StatusNotFound},
+		{"DELETE", "/v2/projects/shop/messages/shop/pay?language=en", "", nil, http.StatusNoContent},
+		{"DELETE", "/v1/projects/shop", "", nil, http.StatusNoContent},
+		{"DELETE", "/v1/projects/shop", "", nil, http.StatusNotFound},
+	}
+	for _, item := range cases {
+		request := httptest.NewRequest(item.method, item.url, strings.NewReader(item.body))
+		request.Header.Set("Content-Type", "application/json")
+		for key, value := range item.header {
+			request.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		if recorder.Code != item.status {
+			t.Errorf("%s %s get status: %d, want: %d, body: %s",
+				item.method, item.url, recorder.Code, item.status, recorder.Body.String())
+		}
+	}
+
+	// the watch stream ends when the client goes away.
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, newCanceledRequest("GET", "/v1/watch?scope=user&language=english&since=1"))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("GET /v1/watch get status: %d, want: %d", recorder.Code, http.StatusOK)
+	}
+
+	for _, route := range engine.Routes() {
+		if !covered[route.Method+" "+route.Path] {
+			t.Errorf("Route [%s %s] is not covered by the contract test", route.Method, route.Path)
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.Auth = config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "reader", Key: "read-key", Scopes: []string{"read"}},
+			{Name: "owner", Key: "admin-key", Scopes: []string{"admin"}},
+			{Name: "vendor", Key: "vendor-key", Scopes: []string{"write"}},
+		},
+		TokenSecret: "secret",
+		ACL: []config.ACLRuleConfig{
+			{Principal: "vendor", Language: "ja", Scope: "user.text", Permission: "write"},
+			{Principal: "owner", Permission: "*"},
+		},
+	}
+	engine := gin.New()
+	RegisterHandler(engine, c, newTestInstance())
+
+	do := func(method, url, body string, header map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		for key, value := range header {
+			request.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	item := `{"scopes":["user","text","help"],"language":"en","value":"Help"}`
+	cases := []struct {
+		method string
+		url    string
+		body   string
+		header map[string]string
+		status int
+	}{
+		{"GET", "/v1/languages", "", nil, http.StatusUnauthorized},
+		{"GET", "/v1/languages", "", map[string]string{"X-API-Key": "wrong"}, http.StatusUnauthorized},
+		{"GET", "/v1/languages", "", map[string]string{"X-API-Key": "read-key"}, http.StatusOK},
+		{"GET", "/v1/languages", "", map[string]string{"Authorization": "Bearer read-key"}, http.StatusOK},
+		{"PUT", "/v2/messages", item, map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"PUT", "/v2/messages", item, map[string]string{"X-API-Key": "admin-key"}, http.StatusCreated},
+		{"POST", "/v1/auth/token", `{"scopes":["write"]}`, map[string]string{"X-API-Key": "read-key"}, http.StatusForbidden},
+		{"GET", "/v1/openapi.json", "", nil, http.StatusOK},
+
+		// The vendor can only write the japanese values under user.text.
+		{"PUT", "/v2/messages", `{"scopes":["user","text","help"],"language":"japanese","value":"ヘルプ"}`,
+			map[string]string{"X-API-Key": "vendor-key"}, http.StatusCreated},
+		{"PUT", "/v2/messages", `{"scopes":["user","title"],"language":"ja","value":"ユーザー"}`,
+			map[string]string{"X-API-Key": "vendor-key"}, http.StatusForbidden},
+		{"POST", "/v1/message/ln/chinese/msg/帮助/user/text/help", "", map[string]string{"X-API-Key": "vendor-key"},
+			http.StatusForbidden},
+		{"DELETE", "/v2/messages/user/text/help?language=ja", "", map[string]string{"X-API-Key": "vendor-key"},
+			http.StatusForbidden},
+		{"POST", "/v2/messages/batch", `{"upserts":[{"scopes":["user","text","a"],"language":"ja","value":"A"},` +
+			`{"scopes":["user","text","a"],"language":"en","value":"A"}]}`,
+			map[string]string{"X-API-Key": "vendor-key"}, http.StatusForbidden},
+	}
+	for _, item := range cases {
+		if recorder := do(item.method, item.url, item.body, item.header); recorder.Code != item.status {
+			t.Errorf("%s %s %v get status: %d, want: %d", item.method, item.url, item.header, recorder.Code, item.status)
+		}
+	}
+
+	if recorder := do("GET", "/v1/languages", "", nil); recorder.Header().Get("WWW-Authenticate") == "" {
+		t.Error("401 response should have WWW-Authenticate header")
+	}
+
+	admin := map[string]string{"X-API-Key": "admin-key"}
+	if recorder := do("POST", "/v1/auth/token", `{"subject":"vendor","scopes":["write"]}`, admin); recorder.Code !=
+		http.StatusForbidden {
+		t.Errorf("Issue token of another principal get status: %d, want: %d", recorder.Code, http.StatusForbidden)
+	}
+	recorder := do("POST", "/v1/auth/token", `{"subject":"owner","scopes":["write"]}`, admin)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Issue token get status: %d, body: %s", recorder.Code, recorder.Body.String())
+	}
+	token := handler.TokenResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &token); err != nil {
+		t.Fatal(err)
+	}
+	bearer := map[string]string{"Authorization": "Bearer " + token.Token}
+	if recorder = do("DELETE", "/v2/messages/user/text/help?language=en", "", bearer); recorder.Code != http.StatusNoContent {
+		t.Errorf("Delete with token get status: %d, want: %d", recorder.Code, http.StatusNoContent)
+	}
+	if recorder = do("GET", "/v1/auth/whoami", "", bearer); !strings.Contains(recorder.Body.String(), `"name":"owner"`) {
+		t.Errorf("Whoami with token get: %s, want name owner", recorder.Body.String())
+	}
+}
+
+// TestMissing checks a missed message falls back to the languages of config, then to the placeholder or 404.
+func TestMissing(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	for _, notFoundWith404 := range []bool{false, true} {
+		c := newTestConfig(t)
+		c.ApplicationConfig.NotFoundWith404 = notFoundWith404
+		engine := gin.New()
+		RegisterHandler(engine, c, newTestInstance())
+
+		for _, item := range []struct {
+			url    string
+			status int
+			value  string
+		}{
+			{"/v1/message/ln/japanese/user/text/login", http.StatusOK, `"Login"`},
+			{"/v1/message/ln/japanese/user/text/none", http.StatusOK, `"ja_user/text/none"`},
+		} {
+			if notFoundWith404 && item.value == `"ja_user/text/none"` {
+				item.status, item.value = http.StatusNotFound, "null"
+			}
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest("GET", item.url, nil))
+			if recorder.Code != item.status || recorder.Body.String() != item.value {
+				t.Errorf("GET %s with 404 %v get: %d %s, want: %d %s", item.url, notFoundWith404, recorder.Code,
+					recorder.Body.String(), item.status, item.value)
+			}
+		}
+	}
+}
+
+// TestBundleEncoding checks the bundle is compressed by the q-values of the Accept-Encoding, and always varies by it.
+func TestBundleEncoding(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	for header, gzipped := range map[string]bool{
+		"":                 false,
+		"gzip, deflate":    true,
+		"gzip;q=0, br":     false,
+		"*":                true,
+		"*, gzip;q=0":      false,
+		"deflate, *;q=0.1": true,
+	} {
+		request := httptest.NewRequest("GET", "/v1/bundle/en/user", nil)
+		request.Header.Set("Accept-Encoding", header)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		if (recorder.Header().Get("Content-Encoding") == "gzip") != gzipped {
+			t.Errorf("Accept-Encoding [%s] get Content-Encoding: [%s], want gzip: %v", header,
+				recorder.Header().Get("Content-Encoding"), gzipped)
+		}
+		if !strings.Contains(strings.Join(recorder.Header().Values("Vary"), ","), "Accept-Encoding") {
+			t.Errorf("Accept-Encoding [%s] get Vary: %v, want Accept-Encoding", header, recorder.Header().Values("Vary"))
+		}
+	}
+}
+
+func TestConditionalVariants(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	get := func(url, header, value, etag string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", url, nil)
+		request.Header.Set(header, value)
+		if len(etag) != 0 {
+			request.Header.Set("If-None-Match", etag)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for _, c := range []struct{ url, header, first, second string }{
+		{"/v1/message/negotiate/user/text/login", "Accept-Language", "en", "zh-CN"},
+		{"/v1/export?scope=user", "Accept", "application/json", "text/csv"},
+	} {
+		etag := get(c.url, c.header, c.first, "").Header().Get("ETag")
+		if recorder := get(c.url, c.header, c.first, etag); recorder.Code != http.StatusNotModified ||
+			!strings.Contains(strings.Join(recorder.Header().Values("Vary"), ","), c.header) {
+			t.Errorf("%s of the same %s get: %d, Vary: %v, want 304 with the Vary", c.url, c.header, recorder.Code,
+				recorder.Header().Values("Vary"))
+		}
+		if recorder := get(c.url, c.header, c.second, etag); recorder.Code != http.StatusOK {
+			t.Errorf("%s of another %s get: %d, want: %d", c.url, c.header, recorder.Code, http.StatusOK)
+		} else if len(recorder.Header().Values("Vary")) != 1 {
+			t.Errorf("%s get Vary: %v, want it once", c.url, recorder.Header().Values("Vary"))
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.Auth = config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "owner", Key: "admin-key", Scopes: []string{"admin"}}},
+	}
+	engine := gin.New()
+	RegisterHandler(engine, c, newTestInstance())
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-API-Key", "admin-key")
+		request.Header.Set("X-Request-ID", "req-"+method)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	do("PUT", "/v2/messages", `{"scopes":["user","text","login"],"language":"ja","value":"ログイン"}`)
+	do("POST", "/v1/message/ln/english/msg/Sign%20in/user/text/login", "")
+	do("POST", "/v1/message/ln/english/msg/Sign%20in/user/text/login", "")
+	do("DELETE", "/v2/messages/user/text/logout?language=en", "")
+
+	recorder := do("GET", "/v1/audit?scope=user.text.login", "")
+	var entries []audit.Entry
+	if err := json.Unmarshal(recorder.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Unmarshal audit: %v, body: %s", err, recorder.Body.String())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Get %d entries, want 2 (the no-op change is not recorded): %v", len(entries), entries)
+	}
+	if entries[0].Language != "en" || entries[0].OldValue != "Login" || entries[0].NewValue != "Sign in" ||
+		entries[0].Actor != "owner" || entries[0].RequestID != "req-POST" {
+		t.Errorf("Get newest entry: %+v", entries[0])
+	}
+	if entries[1].Type != provider.ChangeAdded || entries[1].NewValue != "ログイン" {
+		t.Errorf("Get oldest entry: %+v", entries[1])
+	}
+
+	if err := json.Unmarshal(do("GET", "/v1/audit?language=en&actor=owner&limit=1", "").Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Type != provider.ChangeRemoved {
+		t.Errorf("Get entries by language and actor: %v, want the removed logout", entries)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	do := func(method, url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(method, url, nil))
+		return recorder
+	}
+
+	// the index follows the changes by the apis.
+	do("POST", "/v1/message/ln/english/msg/Sign%20in/user/text/login")
+	do("DELETE", "/v1/message/ln/english/user/text/logout")
+
+	recorder := do("GET", "/v1/search?q=SIGN&language=english")
+	result := struct {
+		Total int `json:"total"`
+		Hits  []struct {
+			Scopes []string `json:"scopes"`
+			Value  string   `json:"value"`
+		} `json:"hits"`
+	}{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Unmarshal search: %v, body: %s", err, recorder.Body.String())
+	}
+	if result.Total != 1 || len(result.Hits) != 1 || result.Hits[0].Value != "Sign in" {
+		t.Errorf("search of the changed value get: %s", recorder.Body.String())
+	}
+
+	if err := json.Unmarshal(do("GET", "/v1/search?q=log&in=scope").Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Errorf("search of the scope path get %d hits, want 2 (the deleted logout is not found)", result.Total)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	instance := newTestInstance()
+	instance.PushMessage(provider.EnglishLn, "Cart", "shop", "cart")
+	instance.PushMessage(provider.EnglishLn, "About", "about")
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), instance)
+
+	list := func(url string) handler.KeyPage {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		page := handler.KeyPage{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			t.Fatalf("Unmarshal keys: %v, body: %s", err, recorder.Body.String())
+		}
+		return page
+	}
+
+	// page all keys by 2, a key which is added between the pages is returned by the later page.
+	var keys []string
+	page := list("/v1/keys?limit=2")
+	instance.PushMessage(provider.EnglishLn, "Sign up", "user", "text", "signup")
+	for {
+		for _, item := range page.Items {
+			keys = append(keys, strings.Join(item.Scopes, "."))
+		}
+		if len(page.NextCursor) == 0 {
+			break
+		}
+		page = list("/v1/keys?limit=2&cursor=" + page.NextCursor)
+	}
+	want := "about,shop.cart,user.text.login,user.text.logout,user.text.signup"
+	if strings.Join(keys, ",") != want {
+		t.Errorf("the pages get keys: %v, want: %s", keys, want)
+	}
+
+	page = list("/v1/keys?scope=user&language=zh&sort=-key")
+	if len(page.Items) != 1 || page.Items[0].Values["zh"] != "登录" || len(page.Items[0].Values) != 1 {
+		t.Errorf("the keys of the language get: %+v", page.Items)
+	}
+	page = list("/v1/keys?language=en&sort=-value&limit=1")
+	if len(page.Items) != 1 || page.Items[0].Values["en"] != "Sign up" {
+		t.Errorf("the first key by value descending get: %+v", page.Items)
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest("GET", "/v1/export?scope=user.text&language=english", nil))
+	exported, err := files.FromJson(recorder.Body.String())
+	if err != nil {
+		t.Fatalf("the export get: %v, body: %s", err, recorder.Body.String())
+	}
+	if value, ok := exported.Message(provider.EnglishLn, "user", "text", "login"); !ok || value != "Login" {
+		t.Errorf("the exported value get: %s, %v, want the value with the full scopes", value, ok)
+	}
+	if _, ok := exported.Message(provider.ChineseLn, "user", "text", "login"); ok {
+		t.Error("the export of a language should not have the other languages")
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.Contains(disposition, "user.text.json") {
+		t.Errorf("the export get Content-Disposition: %s", disposition)
+	}
+}
+
+func TestImport(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.Auth = config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "owner", Key: "admin-key", Scopes: []string{"admin"}},
+			{Name: "translator", Key: "ja-key", Scopes: []string{"write"}},
+		},
+		ACL: []config.ACLRuleConfig{
+			{Principal: "translator", Language: "ja", Permission: "write"},
+			{Principal: "owner", Permission: "*"},
+		},
+	}
+	instance := newTestInstance()
+	engine := gin.New()
+	RegisterHandler(engine, c, instance)
+
+	do := func(url, key, name, content string) (int, handler.ImportResult) {
+		body, contentType := newMultipartBody(t, name, content)
+		request := httptest.NewRequest("POST", url, strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("X-API-Key", key)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		res := handler.ImportResult{}
+		_ = json.Unmarshal(recorder.Body.Bytes(), &res)
+		return recorder.Code, res
+	}
+	value := func(ln provider.LanguageKey, scopes ...string) string {
+		res, _ := instance.Lookup(ln.Lower(instance.Standard), scopes...)
+		return res
+	}
+
+	csv := "key,english,japanese\nuser.text.login,Sign in,ログイン\nuser.text.signup,Sign up,\nshop.cart,Cart,\n"
+	status, res := do("/v1/import?dry_run=true", "admin-key", "i18n.csv", csv)
+	if status != http.StatusOK || len(res.Changes) != 4 || res.Format != ".csv" {
+		t.Fatalf("the dry run get: %d %+v, want 4 changes", status, res)
+	}
+	if value(provider.EnglishLn, "user", "text", "login") != "Login" {
+		t.Error("the dry run should not change the catalog")
+	}
+
+	if status, res = do("/v1/import?strategy=only-missing&scope=user", "admin-key", "i18n.csv", csv); status !=
+		http.StatusOK || len(res.Changes) != 2 {
+		t.Fatalf("the only-missing import get: %d %+v, want the ja login and the en signup", status, res)
+	}
+	if value(provider.EnglishLn, "user", "text", "login") != "Login" || value(provider.JapaneseLn, "user", "text",
+		"login") != "ログイン" || len(value(provider.EnglishLn, "shop", "cart")) != 0 {
+		t.Error("the only-missing import should keep the existing values and skip the values out of the scope")
+	}
+
+	xliff := `<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+<file original="app" source-language="en" target-language="ja" datatype="plaintext"><body>
+<trans-unit id="user.text.logout"><source>Logout</source><target>ログアウト</target></trans-unit>
+</body></file></xliff>`
+	if status, _ = do("/v1/import", "ja-key", "ja.xlf", xliff); status != http.StatusOK {
+		t.Errorf("the translator import of ja get: %d", status)
+	}
+	if status, _ = do("/v1/import", "ja-key", "en.csv", "key,en\nuser.text.logout,Log out\n"); status !=
+		http.StatusForbidden {
+		t.Errorf("the translator import of en get: %d, want: %d", status, http.StatusForbidden)
+	}
+
+	po := "msgid \"\"\nmsgstr \"Language: en\\n\"\n\nmsgid \"user.text.login\"\nmsgstr \"Log in\"\n"
+	// the replace-subtree without the scope would delete the whole catalog.
+	if status, _ = do("/v1/import?strategy=replace-subtree", "admin-key", "upload", po); status !=
+		http.StatusUnprocessableEntity {
+		t.Errorf("the replace-subtree import without the scope get: %d, want: %d", status,
+			http.StatusUnprocessableEntity)
+	}
+	if len(value(provider.JapaneseLn, "user", "text", "logout")) == 0 {
+		t.Error("the rejected replace-subtree import should not change the catalog")
+	}
+	if status, res = do("/v1/import?strategy=replace-subtree&scope=user.text", "admin-key", "upload", po); status !=
+		http.StatusOK || res.Format != ".po" {
+		t.Fatalf("the replace-subtree import get: %d %+v", status, res)
+	}
+	var keys []string
+	instance.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		keys = append(keys, strings.Join(flags, ".")+":"+languageValue+"="+messageValue)
+	})
+	if want := "[user.text.login:en=Log in]"; fmt.Sprint(keys) != want {
+		t.Errorf("the catalog after replace-subtree get: %v, want: %s", keys, want)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	instance := newTestInstance()
+	engine := gin.New()
+	epoch := RegisterHandler(engine, newTestConfig(t), instance).Watch.Epoch()
+
+	revision := instance.Revision()
+	instance.PushMessage(provider.JapaneseLn, "ログイン", "user", "text", "login")
+	instance.PushMessage(provider.EnglishLn, "", "user", "text", "logout")
+	instance.PushMessage(provider.EnglishLn, "Title", "title")
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/v1/watch?scope=user&epoch=%s&since=%d", epoch, revision)
+	engine.ServeHTTP(recorder, newCanceledRequest("GET", url))
+	body := recorder.Body.String()
+	if recorder.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Get content type: %s", recorder.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		fmt.Sprintf("id:%s:%d\nevent:upsert\n", epoch, revision+1),
+		fmt.Sprintf("id:%s:%d\nevent:delete\n", epoch, revision+2),
+		fmt.Sprintf("event:ready\ndata:{\"epoch\":\"%s\",\"revision\":%d}", epoch, revision+3),
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Watch body should contain %q, get: %s", want, body)
+		}
+	}
+	if strings.Contains(body, "Title") {
+		t.Errorf("Watch body should not contain the events out of the scope, get: %s", body)
+	}
+
+	request := newCanceledRequest("GET", "/v1/watch")
+	request.Header.Set("Last-Event-ID", fmt.Sprintf("%s:%d", epoch, revision+1))
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	if body = recorder.Body.String(); strings.Contains(body, "event:reset") || !strings.Contains(body, "Title") {
+		t.Errorf("Watch from the Last-Event-ID should resume, get: %s", body)
+	}
+
+	// the revision of another epoch, like before the server restarted.
+	for _, lastEventID := range []string{"1", fmt.Sprintf("restarted:%d", revision+1), epoch + ":1"} {
+		request = newCanceledRequest("GET", "/v1/watch")
+		request.Header.Set("Last-Event-ID", lastEventID)
+		recorder = httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		if !strings.Contains(recorder.Body.String(), "event:reset") {
+			t.Errorf("Watch from %s should be reset, get: %s", lastEventID, recorder.Body.String())
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	RegisterHandler(engine, newTestConfig(t), newTestInstance())
+
+	for _, url := range []string{"/v1/message/ln/english/user/text/login", "/v2/messages/user/none?language=ja", "/none"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+	// the writes are not counted as the lookups.
+	for _, request := range []*http.Request{
+		httptest.NewRequest("PUT", "/v2/messages", strings.NewReader(`{"scopes":["help"],"language":"en","value":"Help"}`)),
+		httptest.NewRequest("DELETE", "/v2/messages/help?language=en", nil),
+		httptest.NewRequest("DELETE", "/v2/messages/help?language=en", nil),
+	} {
+		request.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /metrics get status: %d", recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, want := range []string{
+		`i18n_http_requests_total{method="GET",route="/v1/message/ln/:ln/*scopes",status="200"} 1`,
+		`i18n_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`i18n_lookups_total{language="en",result="hit"} 1`,
+		`i18n_lookups_total{language="other",result="miss"} 1`,
+		`i18n_mutations_total{type="removed"} 1`,
+		`i18n_catalog_keys{language="en"} 2`,
+		`i18n_catalog_keys{language="zh"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Metrics should contain: %s", want)
+		}
+	}
+	if strings.Contains(body, `i18n_lookups_total{language="en",result="miss"}`) {
+		t.Error("Metrics should not count the writes as the misses")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.Health.MinKeys = 3
+	engine := gin.New()
+	instance := newTestInstance()
+	services := RegisterHandler(engine, c, instance)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		return recorder
+	}
+
+	if recorder := get("/readyz"); recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Readyz before load get status: %d, want: %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+	loaded := []files.LoadedFile{{Path: "i18n.json", Checksum: "abc", Size: 3}}
+	services.Loaded(loaded, nil)
+	if recorder := get("/readyz"); recorder.Code != http.StatusOK {
+		t.Errorf("Readyz after load get status: %d, want: %d", recorder.Code, http.StatusOK)
+	}
+
+	// a failed reload keeps the server ready, and it is reported by the info.
+	services.Loaded(nil, errors.New("broken file"))
+	if recorder := get("/readyz"); recorder.Code != http.StatusOK {
+		t.Errorf("Readyz after failed reload get status: %d, want: %d", recorder.Code, http.StatusOK)
+	}
+	info := health.Info{}
+	if err := json.Unmarshal(get("/v1/info").Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Files) != 1 || info.Files[0].Checksum != "abc" || info.LoadedAt == nil ||
+		info.LastError != "broken file" || info.Revision != instance.Revision() {
+		t.Errorf("Get info: %+v, want the loaded file, the revision and the last error", info)
+	}
+
+	instance.PushMessageByString("en", "", "user", "text", "logout")
+	recorder := get("/readyz")
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "at least 3") {
+		t.Errorf("Readyz with 2 values get: %d %s, want 503 with the min keys reason", recorder.Code, recorder.Body)
+	}
+	if recorder = get("/healthz"); recorder.Code != http.StatusOK {
+		t.Errorf("Healthz get status: %d, want: %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.Auth.Enabled = true
+	c.ApplicationConfig.CORS = config.CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"ETag"},
+		MaxAge:        "10m",
+	}
+	engine := gin.New()
+	RegisterHandler(engine, c, newTestInstance())
+
+	do := func(method, origin string, header map[string]string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/v1/bundle/en/user", nil)
+		request.Header.Set("Origin", origin)
+		for key, value := range header {
+			request.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// the preflight request is answered before the auth.
+	recorder := do(http.MethodOptions, "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method": "GET",
+	})
+	header := recorder.Header()
+	if recorder.Code != http.StatusNoContent || header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		!strings.Contains(header.Get("Access-Control-Allow-Headers"), "X-API-Key") ||
+		header.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("preflight get: %d %v", recorder.Code, header)
+	}
+
+	recorder = do(http.MethodGet, "https://app.example.com", nil)
+	header = recorder.Header()
+	if recorder.Code != http.StatusUnauthorized || header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		header.Get("Access-Control-Expose-Headers") != "ETag" {
+		t.Errorf("request get: %d %v, want 401 with the CORS headers", recorder.Code, header)
+	}
+
+	recorder = do(http.MethodOptions, "https://evil.example.com", map[string]string{
+		"Access-Control-Request-Method": "GET",
+	})
+	if len(recorder.Header().Get("Access-Control-Allow-Origin")) != 0 || recorder.Header().Get("Vary") != "Origin" {
+		t.Errorf("preflight of other origin get: %d %v, want no CORS header", recorder.Code, recorder.Header())
+	}
+}
+
+func TestServe(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	c := newTestConfig(t)
+	c.ApplicationConfig.ShutdownTimeout = "5s"
+	// the slow route is not in the OpenAPI document.
+	c.ApplicationConfig.ValidateOpenAPI = false
+	engine := gin.New()
+	services := RegisterHandler(engine, c, newTestInstance())
+
+	started, release := make(chan struct{}), make(chan struct{})
+	engine.GET("/slow", func(context *gin.Context) {
+		close(started)
+		<-release
+		context.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, listener, engine, c, nil, services.Watch.Close)
+	}()
+
+	base := "http://" + listener.Addr().String()
+	type result struct {
+		body string
+		err  error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		response, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		slow <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	// a watch stream doesn't block the shutdown.
+	watchResponse, err := http.Get(base + "/v1/watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watchResponse.Body.Close()
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	if _, err = http.Get(base + "/healthz"); err == nil {
+		t.Error("the server should refuse the new requests after the shutdown begins")
+	}
+
+	// the in-flight request is drained.
+	close(release)
+	if res := <-slow; res.err != nil || res.body != "done" {
+		t.Errorf("in-flight request get: %q, %v, want done", res.body, res.err)
+	}
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Errorf("Serve get: %v, want nil after a graceful shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve should return after the in-flight requests are drained")
+	}
+}
+
+func TestProjects(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	dir := t.TempDir()
+	catalog := provider.NewI18n(provider.ISO6391)
+	catalog.PushMessage(provider.EnglishLn, "Cart", "shop", "cart")
+	if err := files.WriteFile(filepath.Join(dir, "shop.json"), catalog); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestConfig(t)
+	c.ApplicationConfig.Auth = config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{{Name: "owner", Key: "admin-key", Scopes: []string{"admin"}}},
+	}
+	c.ApplicationConfig.Projects = config.ProjectsConfig{
+		Dir: filepath.Join(dir, "projects"),
+		Items: []config.ProjectConfig{{
+			ID:       "shop",
+			Files:    []string{filepath.Join(dir, "shop.json")},
+			Readonly: true,
+			Auth: config.AuthConfig{
+				Enabled: true,
+				APIKeys: []config.APIKeyConfig{{Name: "shop", Key: "shop-key", Scopes: []string{"write"}}},
+			},
+		}},
+	}
+	engine := gin.New()
+	services := RegisterHandler(engine, c, newTestInstance())
+
+	do := func(method, url, body, key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if len(key) != 0 {
+			request.Header.Set("X-API-Key", key)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	cases := []struct {
+		method string
+		url    string
+		body   string
+		key    string
+		status int
+	}{
+		// the project has its own files and auth.
+		{"GET", "/v1/projects/shop/message/ln/english/shop/cart", "", "shop-key", http.StatusOK},
+		{"GET", "/v1/projects/shop/message/ln/english/shop/cart", "", "admin-key", http.StatusUnauthorized},
+		{"GET", "/v2/projects/shop/messages/user/text/login", "", "shop-key", http.StatusNotFound},
+		{"GET", "/v2/messages/shop/cart", "", "admin-key", http.StatusNotFound},
+		// the project is readonly.
+		{"PUT", "/v2/projects/shop/messages", `{"scopes":["shop"],"language":"en","value":"Shop"}`, "shop-key",
+			http.StatusNotFound},
+		// the projects are managed by the server auth.
+		{"GET", "/v1/projects", "", "", http.StatusUnauthorized},
+		{"POST", "/v1/projects", `{"id":"blog","standard":"ISO 639-3"}`, "shop-key", http.StatusUnauthorized},
+		{"POST", "/v1/projects", `{"id":"blog","standard":"ISO 639-3"}`, "admin-key", http.StatusCreated},
+		{"POST", "/v1/projects", `{"id":"blog"}`, "admin-key", http.StatusConflict},
+		{"POST", "/v1/projects", `{"id":"news","standard":"unknown"}`, "admin-key", http.StatusUnprocessableEntity},
+		// a project without auth uses the server auth.
+		{"PUT", "/v2/projects/blog/messages", `{"scopes":["post"],"language":"en","value":"Post"}`, "",
+			http.StatusUnauthorized},
+		{"PUT", "/v2/projects/blog/messages", `{"scopes":["post"],"language":"en","value":"Post"}`, "admin-key",
+			http.StatusCreated},
+		{"DELETE", "/v1/projects/shop", "", "admin-key", http.StatusConflict},
+	}
+	for _, item := range cases {
+		if recorder := do(item.method, item.url, item.body, item.key); recorder.Code != item.status {
+			t.Errorf("%s %s get status: %d, want: %d, body: %s",
+				item.method, item.url, recorder.Code, item.status, recorder.Body.String())
+		}
+	}
+
+	projects := []handler.ProjectResponse{}
+	if err := json.Unmarshal(do("GET", "/v1/projects", "", "admin-key").Body.Bytes(), &projects); err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].ID != "blog" || projects[0].Standard != provider.ISO6393 ||
+		!projects[1].Static || !projects[1].AuthEnabled || projects[1].Revision == 0 {
+		t.Errorf("List projects get: %+v", projects)
+	}
+
+	// the metrics of the projects are served by the server with the project label.
+	body := do("GET", "/metrics", "", "admin-key").Body.String()
+	for _, want := range []string{
+		`i18n_lookups_total{language="en",project="shop",result="hit"} 1`,
+		`i18n_catalog_keys{language="en",project="blog"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Metrics should contain: %s", want)
+		}
+	}
+
+	// the created project and its changes are kept in the dir.
+	if err := services.Close(); err != nil {
+		t.Fatal(err)
+	}
+	engine = gin.New()
+	services = RegisterHandler(engine, c, newTestInstance())
+	defer services.Close()
+	if recorder := do("GET", "/v2/projects/blog/messages/post", "", "admin-key"); recorder.Code != http.StatusOK {
+		t.Errorf("Get value of the reopened project get status: %d, body: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := do("DELETE", "/v1/projects/blog", "", "admin-key"); recorder.Code != http.StatusNoContent {
+		t.Errorf("Delete project get status: %d, body: %s", recorder.Code, recorder.Body)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "projects", "blog")); !os.IsNotExist(err) {
+		t.Errorf("the dir of the deleted project should be removed, get: %v", err)
+	}
+	// the metrics of the deleted project are unregistered, so it can be created again.
+	if recorder := do("POST", "/v1/projects", `{"id":"blog"}`, "admin-key"); recorder.Code != http.StatusCreated {
+		t.Errorf("Create the deleted project get status: %d, body: %s", recorder.Code, recorder.Body)
+	}
+}
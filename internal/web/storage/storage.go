@@ -0,0 +1,329 @@
+// Package storage is the catalog of the web server: it loads the catalog files, persists the changes by the apis to the
+// storage file, and reloads the catalog when the files are changed.
+//
+// The storage file only keeps the values which differ from the catalog files, so a change of the catalog files is not
+// hidden by an old copy of it. The values of the catalog files which are removed by the apis are kept in the removed
+// file '<storage file>.removed' with their values, they are removed again after the catalog files are loaded unless the
+// catalog files change them.
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	mfiles "github.com/uberate/mocker-utils/files"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store is the catalog loaded from the files, it is safe for concurrent use.
+type Store struct {
+	standard string
+	paths    []string
+	file     string
+	delay    time.Duration
+	onError  func(err error)
+	instance *provider.I18n
+
+	// lock protects the fields below, and serializes the flushes and reloads.
+	lock    sync.Mutex
+	base    *provider.I18n
+	sources []files.LoadedFile
+	loaded  []files.LoadedFile
+	err     error
+
+	// timerLock protects the timer and the pending, it is not the lock because the listener is invoked in the changes of
+	// a reload. The pending is true when there are changes which are not written.
+	timerLock sync.Mutex
+	timer     *time.Timer
+	pending   bool
+
+	unsubscribe func()
+}
+
+// Open load the paths and then the storage file, and return a *Store of them. The file is the storage file, empty means
+// the changes are kept in memory only. The changes are written to the file after the delay, the changes in the delay
+// are written once, and 0 means every change is written at once. The errors of the delayed writes are passed to onError,
+// it can be nil.
+func Open(standard string, paths []string, file string, delay time.Duration, onError func(err error)) (*Store, error) {
+	s := &Store{standard: standard, paths: paths, file: file, delay: delay, onError: onError}
+	instance, base, sources, loaded, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.instance, s.base, s.sources, s.loaded = instance, base, sources, loaded
+	if len(file) != 0 {
+		s.unsubscribe = instance.Subscribe(func(event provider.ChangeEvent) {
+			s.schedule()
+		})
+	}
+	return s, nil
+}
+
+// Instance return the live instance, the apis serve and change it.
+func (s *Store) Instance() *provider.I18n {
+	return s.instance
+}
+
+// Loaded return the files of the last successful load, the storage file is the last one if it exists.
+func (s *Store) Loaded() []files.LoadedFile {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]files.LoadedFile{}, s.loaded...)
+}
+
+// Err return the error of the last write of the storage file, nil if it succeeded.
+func (s *Store) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// Flush write the pending changes to the storage file at once. It does nothing without the storage file.
+func (s *Store) Flush() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.flush()
+}
+
+// Reload load the files again, and apply the changes of the catalog files to the instance if any file except the
+// storage file is changed. It returns false if nothing is changed, and the instance is not changed when an error is
+// returned.
+//
+// Only the changes of the catalog files are applied, and every change is applied only if the instance still has the
+// old value of the catalog files, so the changes by the apis are kept even if they are made during the reload. A value
+// which is removed by the apis is restored when the catalog files change it, like the load of the removed file.
+func (s *Store) Reload() (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.flush(); err != nil {
+		return false, err
+	}
+	_, base, sources, loaded, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	if sameFiles(s.sources, sources) {
+		return false, nil
+	}
+
+	var restored []provider.Change
+	for _, conflict := range s.base.Diff(base).ApplyPartial(s.instance) {
+		if !conflict.Exists && conflict.Change.Type == provider.ChangeChanged {
+			restored = append(restored, provider.Change{Type: provider.ChangeAdded, Scopes: conflict.Change.Scopes,
+				Language: conflict.Change.Language, NewValue: conflict.Change.NewValue})
+		}
+	}
+	// the value is added by the apis after the conflict, the apis win.
+	_ = (&provider.Patch{Changes: restored}).ApplyPartial(s.instance)
+	s.base, s.sources, s.loaded = base, sources, loaded
+	return true, nil
+}
+
+// Watch reload the files on every interval and on every value of the signals, until the cancel func is invoked. The
+// result of every effective reload is passed to onLoad, it can be nil. An interval <= 0 means only the signals.
+func (s *Store) Watch(interval time.Duration, signals <-chan os.Signal,
+	onLoad func(loaded []files.LoadedFile, err error)) (cancel func()) {
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick:
+			case <-signals:
+			}
+			changed, err := s.Reload()
+			if (changed || err != nil) && onLoad != nil {
+				onLoad(s.Loaded(), err)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// Close write the pending changes, and stop writing the storage file.
+func (s *Store) Close() error {
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	return s.Flush()
+}
+
+// schedule the write of the storage file after the delay.
+func (s *Store) schedule() {
+	s.timerLock.Lock()
+	defer s.timerLock.Unlock()
+
+	s.pending = true
+	if s.timer != nil {
+		return
+	}
+	s.timer = time.AfterFunc(s.delay, func() {
+		if err := s.Flush(); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	})
+}
+
+// flush write the values which differ from the catalog files to the storage file. It should be invoked with the lock.
+// If the write fails, the changes are still pending, and the next flush retries.
+func (s *Store) flush() error {
+	s.timerLock.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	pending := s.pending
+	s.pending = false
+	s.timerLock.Unlock()
+	if !pending {
+		return nil
+	}
+
+	overlay := provider.NewI18n(s.standard)
+	removed := []removal{}
+	for _, change := range s.base.Diff(s.instance).Changes {
+		if change.Type == provider.ChangeRemoved {
+			removed = append(removed, removal{Language: change.Language, Scopes: change.Scopes, Value: change.OldValue})
+			continue
+		}
+		overlay.PushMessageByString(change.Language, change.NewValue, change.Scopes...)
+	}
+	if s.err = writeRemoved(removedFile(s.file), removed); s.err == nil {
+		s.err = write(s.file, overlay)
+	}
+	if s.err != nil {
+		s.err = fmt.Errorf("write storage file %s: %v", s.file, s.err)
+		s.timerLock.Lock()
+		s.pending = true
+		s.timerLock.Unlock()
+	}
+	return s.err
+}
+
+// load return the instance of the paths and the storage file, and the base instance of the paths only. The sources is
+// the files of the paths, and the loaded is the sources and the storage file.
+func (s *Store) load() (instance, base *provider.I18n, sources, loaded []files.LoadedFile, err error) {
+	base, sources, err = files.Load(s.standard, s.paths...)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	instance, loaded = base.Clone(), sources
+	if len(s.file) != 0 && mfiles.IsFileExists(s.file) {
+		overlay, storageLoaded, err := files.Load(s.standard, s.file)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("read storage file %s: %v", s.file, err)
+		}
+		instance.CoveredMessage(overlay)
+		loaded = append(append([]files.LoadedFile{}, sources...), storageLoaded...)
+	}
+	if len(s.file) != 0 {
+		removed, err := readRemoved(removedFile(s.file))
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("read removed file %s: %v", removedFile(s.file), err)
+		}
+		for _, item := range removed {
+			// the value is changed by the catalog files after it is removed, the catalog files win.
+			if value, ok := base.Lookup(item.Language, item.Scopes...); ok && value == item.Value {
+				instance.PushMessageByString(item.Language, "", item.Scopes...)
+			}
+		}
+	}
+	return instance, base, sources, loaded, nil
+}
+
+// removal is a value of the catalog files which is removed by the apis, the Value is the removed value.
+type removal struct {
+	Language string   `json:"language"`
+	Scopes   []string `json:"scopes"`
+	Value    string   `json:"value"`
+}
+
+// removedFile return the removed file of the storage file.
+func removedFile(file string) string {
+	return file + ".removed"
+}
+
+// readRemoved return the removals of the file, nothing if the file doesn't exist.
+func readRemoved(file string) ([]removal, error) {
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res []removal
+	if err = json.Unmarshal(content, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// writeRemoved write the removals to the file like write, the file is removed if there is no removal.
+func writeRemoved(file string, removed []removal) error {
+	if len(removed) == 0 {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(removed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	temp := file + ".tmp"
+	if err = os.WriteFile(temp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, file)
+}
+
+// write the instance to a temp file and rename it to the file, so a reader never sees a half-written file.
+func write(file string, instance *provider.I18n) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	temp := file + ".tmp" + filepath.Ext(file)
+	if err := files.WriteFile(temp, instance); err != nil {
+		return err
+	}
+	return os.Rename(temp, file)
+}
+
+// sameFiles return true if the files have the same paths and checksums in the same order.
+func sameFiles(a, b []files.LoadedFile) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index].Path != b[index].Path || a[index].Checksum != b[index].Checksum {
+			return false
+		}
+	}
+	return true
+}
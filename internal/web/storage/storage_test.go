@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	source, file := filepath.Join(dir, "i18n.json"), filepath.Join(dir, "data", "storage.json")
+
+	catalog := provider.NewI18n(provider.ISO6391)
+	catalog.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+	catalog.PushMessage(provider.EnglishLn, "Logout", "user", "text", "logout")
+	if err := files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(provider.ISO6391, []string{source}, file, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance := store.Instance()
+	instance.PushMessage(provider.JapaneseLn, "ログイン", "user", "text", "login")
+	if err = store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the storage file keeps only the change.
+	overlay, err := files.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := overlay.Message(provider.JapaneseLn, "user", "text", "login"); !ok || value != "ログイン" {
+		t.Errorf("storage file get: %s, %v, want the change", value, ok)
+	}
+	if _, ok := overlay.Message(provider.EnglishLn, "user", "text", "login"); ok {
+		t.Error("storage file should not keep the values of the catalog files")
+	}
+
+	// the change survives a restart, and the change of the catalog files is not hidden.
+	catalog.PushMessage(provider.EnglishLn, "Sign in", "user", "text", "login")
+	if err = files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	store, err = Open(provider.ISO6391, []string{source}, file, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := store.Instance().Message(provider.JapaneseLn, "user", "text", "login"); value != "ログイン" {
+		t.Errorf("restarted store get: %s, want the change", value)
+	}
+	if value, _ := store.Instance().Message(provider.EnglishLn, "user", "text", "login"); value != "Sign in" {
+		t.Errorf("restarted store get: %s, want the value of the catalog file", value)
+	}
+	if loaded := store.Loaded(); len(loaded) != 2 || loaded[1].Path != file {
+		t.Errorf("Loaded get: %v, want the catalog file and the storage file", loaded)
+	}
+
+	// the removed values of the catalog files are still removed after a restart.
+	store.Instance().PushMessage(provider.EnglishLn, "", "user", "text", "login")
+	store.Instance().PushMessage(provider.EnglishLn, "", "user", "text", "logout")
+	if err = store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	catalog.PushMessage(provider.EnglishLn, "Sign out", "user", "text", "logout")
+	if err = files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	store, err = Open(provider.ISO6391, []string{source}, file, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if value, ok := store.Instance().Message(provider.EnglishLn, "user", "text", "login"); ok {
+		t.Errorf("restarted store get: %s, want the value removed", value)
+	}
+	if value, _ := store.Instance().Message(provider.EnglishLn, "user", "text", "logout"); value != "Sign out" {
+		t.Errorf("restarted store get: %s, want the value changed by the catalog file after the removal", value)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	source, file := filepath.Join(dir, "i18n.json"), filepath.Join(dir, "storage.json")
+
+	catalog := provider.NewI18n(provider.ISO6391)
+	catalog.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+	if err := files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(provider.ISO6391, []string{source}, file, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if changed, err := store.Reload(); changed || err != nil {
+		t.Errorf("Reload without change get: %v, %v, want false", changed, err)
+	}
+
+	// the pending change is kept by the reload.
+	store.Instance().PushMessage(provider.JapaneseLn, "ログイン", "user", "text", "login")
+	catalog.PushMessage(provider.EnglishLn, "Sign in", "user", "text", "login")
+	if err = files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan provider.ChangeEvent, 10)
+	cancel := store.Instance().Subscribe(func(event provider.ChangeEvent) {
+		events <- event
+	})
+	defer cancel()
+
+	if changed, err := store.Reload(); !changed || err != nil {
+		t.Fatalf("Reload get: %v, %v, want true", changed, err)
+	}
+	if event := <-events; event.Type != provider.ChangeChanged || event.NewValue != "Sign in" {
+		t.Errorf("Reload event get: %+v", event)
+	}
+	if value, _ := store.Instance().Message(provider.JapaneseLn, "user", "text", "login"); value != "ログイン" {
+		t.Errorf("reloaded instance get: %s, want the pending change", value)
+	}
+
+	// a value removed by the apis is restored when the catalog file changes it.
+	store.Instance().PushMessage(provider.EnglishLn, "", "user", "text", "login")
+	catalog.PushMessage(provider.EnglishLn, "Log in", "user", "text", "login")
+	if err = files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := store.Reload(); !changed || err != nil {
+		t.Fatalf("Reload get: %v, %v, want true", changed, err)
+	}
+	if value, _ := store.Instance().Message(provider.EnglishLn, "user", "text", "login"); value != "Log in" {
+		t.Errorf("the removed value after the reload get: %s, want the value of the catalog file", value)
+	}
+
+	// an invalid file keeps the instance.
+	if err = os.WriteFile(source, []byte("{"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := store.Reload(); changed || err == nil {
+		t.Errorf("Reload invalid file get: %v, %v, want an error", changed, err)
+	}
+	if value, _ := store.Instance().Message(provider.EnglishLn, "user", "text", "login"); value != "Log in" {
+		t.Errorf("instance after failed reload get: %s", value)
+	}
+}
+
+func TestReloadConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	source, file := filepath.Join(dir, "i18n.json"), filepath.Join(dir, "storage.json")
+
+	catalog := provider.NewI18n(provider.ISO6391)
+	for index := 0; index < 200; index++ {
+		catalog.PushMessage(provider.EnglishLn, "Value", "catalog", strconv.Itoa(index))
+	}
+	if err := files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(provider.ISO6391, []string{source}, file, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// the writes by the apis during the reloads are kept.
+	const writes = 500
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for index := 0; index < writes; index++ {
+			store.Instance().PushMessage(provider.JapaneseLn, "値", "api", strconv.Itoa(index))
+		}
+	}()
+	reloads := 0
+	for running := true; running || reloads == 0; reloads++ {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		catalog.PushMessage(provider.EnglishLn, "Value "+strconv.Itoa(reloads), "catalog", "0")
+		if err = files.WriteFile(source, catalog); err != nil {
+			t.Fatal(err)
+		}
+		if changed, err := store.Reload(); !changed || err != nil {
+			t.Fatalf("Reload get: %v, %v, want true", changed, err)
+		}
+	}
+
+	for index := 0; index < writes; index++ {
+		if _, ok := store.Instance().Message(provider.JapaneseLn, "api", strconv.Itoa(index)); !ok {
+			t.Fatalf("the write %d during the reloads is lost", index)
+		}
+	}
+	want := "Value " + strconv.Itoa(reloads-1)
+	if value, _ := store.Instance().Message(provider.EnglishLn, "catalog", "0"); value != want {
+		t.Errorf("the reloaded value get: %s, want: %s", value, want)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "i18n.json")
+	catalog := provider.NewI18n(provider.ISO6391)
+	catalog.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+	if err := files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	store, err := Open(provider.ISO6391, []string{source}, "", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loads := make(chan []files.LoadedFile, 1)
+	cancel := store.Watch(10*time.Millisecond, nil, func(loaded []files.LoadedFile, err error) {
+		if err == nil {
+			loads <- loaded
+		}
+	})
+	defer cancel()
+
+	catalog.PushMessage(provider.EnglishLn, "Sign in", "user", "text", "login")
+	if err = files.WriteFile(source, catalog); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case loaded := <-loads:
+		if len(loaded) != 1 || loaded[0].Path != source {
+			t.Errorf("Watch get: %v", loaded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch should reload the changed file")
+	}
+	if value, _ := store.Instance().Message(provider.EnglishLn, "user", "text", "login"); value != "Sign in" {
+		t.Errorf("watched instance get: %s", value)
+	}
+}
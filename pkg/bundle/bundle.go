@@ -0,0 +1,128 @@
+package bundle
+
+import (
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+)
+
+const (
+	FormatNested  = "nested"  // {"text": {"login": "Login"}}
+	FormatFlat    = "flat"    // {"text.login": "Login"}
+	FormatI18next = "i18next" // {"en": {"translation": {"text": {"login": "Login"}}}}
+
+	// ValueKey is the key of the value of a scope which also has children in nested format, and the key of the value
+	// of the requested scope itself in all formats.
+	ValueKey = "_"
+
+	// Separator is the separator of the scopes of the key in flat format.
+	Separator = "."
+
+	i18nextNamespace = "translation"
+)
+
+// Flat return all values of the language under the scopes, the key is the scopes relative to the input scopes joined
+// by Separator. If the language hasn't the value, the value of the first fallback language which has it is used.
+//
+// The second result is false when the scopes not found.
+func Flat(i *provider.I18n, ln string, fallbacks []string, scopes ...string) (map[string]string, bool) {
+	res := map[string]string{}
+	found := i.WalkMessageUnder(scopes, func(message map[string]string, flags ...string) {
+		value, ok := lookup(message, ln, fallbacks)
+		if !ok {
+			return
+		}
+		key := strings.Join(flags, Separator)
+		if len(flags) == 0 {
+			key = ValueKey
+		}
+		res[key] = value
+	})
+	if !found {
+		return nil, false
+	}
+	return res, true
+}
+
+// Nested like Flat, but the result is a nested object of the scopes. If a scope has value and children both, the
+// value is saved in the children object with ValueKey.
+func Nested(i *provider.I18n, ln string, fallbacks []string, scopes ...string) (map[string]interface{}, bool) {
+	res := map[string]interface{}{}
+	found := i.WalkMessageUnder(scopes, func(message map[string]string, flags ...string) {
+		value, ok := lookup(message, ln, fallbacks)
+		if !ok {
+			return
+		}
+		if len(flags) == 0 {
+			res[ValueKey] = value
+			return
+		}
+
+		current := res
+		for _, flag := range flags[:len(flags)-1] {
+			switch child := current[flag].(type) {
+			case map[string]interface{}:
+				current = child
+			case string:
+				current[flag] = map[string]interface{}{ValueKey: child}
+				current = current[flag].(map[string]interface{})
+			default:
+				current[flag] = map[string]interface{}{}
+				current = current[flag].(map[string]interface{})
+			}
+		}
+
+		last := flags[len(flags)-1]
+		if child, ok := current[last].(map[string]interface{}); ok {
+			child[ValueKey] = value
+		} else {
+			current[last] = value
+		}
+	})
+	if !found {
+		return nil, false
+	}
+	return res, true
+}
+
+// I18next return the Nested result in i18next resources format: {"<ln>": {"translation": {...}}}.
+func I18next(i *provider.I18n, ln string, fallbacks []string, scopes ...string) (map[string]interface{}, bool) {
+	nested, ok := Nested(i, ln, fallbacks, scopes...)
+	if !ok {
+		return nil, false
+	}
+	return map[string]interface{}{
+		ln: map[string]interface{}{
+			i18nextNamespace: nested,
+		},
+	}, true
+}
+
+// Build return the bundle in specify format, it returns an error if the format is unknown. The second result is false
+// when the scopes not found.
+func Build(i *provider.I18n, format, ln string, fallbacks []string, scopes ...string) (interface{}, bool, error) {
+	switch format {
+	case FormatFlat:
+		res, ok := Flat(i, ln, fallbacks, scopes...)
+		return res, ok, nil
+	case FormatNested, "":
+		res, ok := Nested(i, ln, fallbacks, scopes...)
+		return res, ok, nil
+	case FormatI18next:
+		res, ok := I18next(i, ln, fallbacks, scopes...)
+		return res, ok, nil
+	}
+	return nil, false, fmt.Errorf("unknown bundle format: %s", format)
+}
+
+func lookup(message map[string]string, ln string, fallbacks []string) (string, bool) {
+	if value, ok := message[ln]; ok {
+		return value, true
+	}
+	for _, fallback := range fallbacks {
+		if value, ok := message[fallback]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,40 @@
+package bundle
+
+import (
+	"encoding/json"
+	"github.com/uberate/i18n/pkg/provider"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.Pusher("user", "text", "login")(provider.EnglishLn, "Login")
+	instance.Pusher("user", "text", "login")(provider.ChineseLn, "登录")
+	instance.Pusher("user", "text", "logout")(provider.EnglishLn, "Logout")
+	instance.Pusher("user", "text")(provider.ChineseLn, "文本")
+	instance.Pusher("system", "error")(provider.ChineseLn, "错误")
+
+	flat, ok := Flat(instance, "zh", []string{"en"}, "user")
+	if !ok || len(flat) != 3 || flat["text.logout"] != "Logout" || flat["text"] != "文本" {
+		t.Errorf("Get flat bundle: %v", flat)
+	}
+
+	cases := map[string]string{
+		FormatNested:  `{"text":{"_":"文本","login":"登录"}}`,
+		FormatFlat:    `{"text":"文本","text.login":"登录"}`,
+		FormatI18next: `{"zh":{"translation":{"text":{"_":"文本","login":"登录"}}}}`,
+	}
+	for format, want := range cases {
+		res, ok, err := Build(instance, format, "zh", nil, "user")
+		if err != nil || !ok {
+			t.Fatalf("Build %s get: %v, %v", format, ok, err)
+		}
+		if value, _ := json.Marshal(res); string(value) != want {
+			t.Errorf("Build %s get: %s, want: %s", format, value, want)
+		}
+	}
+
+	if _, ok := Flat(instance, "zh", nil, "none"); ok {
+		t.Error("Bundle of not exists scopes should not be found. But found.")
+	}
+}
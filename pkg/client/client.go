@@ -0,0 +1,280 @@
+// Package client is the Go client of the i18n message server. The Client wraps the server api in typed methods, and the
+// Mirror keeps a local copy of the server catalog, so the lookups are served locally.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxKeysLimit is the max page size of the keys api.
+const maxKeysLimit = 1000
+
+// MessageItem is one language value of the scopes.
+type MessageItem struct {
+	Scopes   []string `json:"scopes" yaml:"scopes"`
+	Language string   `json:"language" yaml:"language"`
+	Value    string   `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// MessageEntry is all language values of the scopes.
+type MessageEntry struct {
+	Scopes []string          `json:"scopes" yaml:"scopes"`
+	Values map[string]string `json:"values" yaml:"values"`
+}
+
+// BatchRequest upsert and delete many values in one request.
+type BatchRequest struct {
+	Upserts []MessageItem `json:"upserts" yaml:"upserts"`
+	Deletes []MessageItem `json:"deletes" yaml:"deletes"`
+}
+
+// BatchResult is the result of BatchRequest.
+type BatchResult struct {
+	Created  int           `json:"created" yaml:"created"`
+	Updated  int           `json:"updated" yaml:"updated"`
+	Deleted  int           `json:"deleted" yaml:"deleted"`
+	NotFound []MessageItem `json:"not_found" yaml:"not_found"`
+}
+
+// Catalog is the whole catalog of the server. The ETag, Epoch and Revision are from the response headers, they can be
+// used for the next conditional request and the watch.
+type Catalog struct {
+	I18n     *provider.I18n
+	ETag     string
+	Epoch    string
+	Revision uint64
+}
+
+// Error is the error response of the server.
+type Error struct {
+	Status  int             `json:"-"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("i18n server: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// IsNotFound return true if the err is an Error with status 404.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Status == http.StatusNotFound
+}
+
+// Client is the client of the i18n message server. The zero value of the fields are usable, except the BaseURL.
+type Client struct {
+	// BaseURL is the root url of the server, like 'http://localhost:3000'.
+	BaseURL string
+	// HTTPClient is the client to send the requests, nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// Credential is an api key or a token of the server, it is sent as 'Authorization: Bearer <Credential>'.
+	Credential string
+}
+
+// New return a *Client of the server.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Message return the value of the language and scopes, the language can be like 'ja' or 'japanese'. If the value not
+// found, the error is an Error and IsNotFound return true.
+func (c *Client) Message(ctx context.Context, language string, scopes ...string) (string, error) {
+	item := MessageItem{}
+	query := url.Values{"language": []string{language}}
+	err := c.do(ctx, http.MethodGet, "/v2/messages/"+scopesPath(scopes)+"?"+query.Encode(), nil, &item)
+	return item.Value, err
+}
+
+// Entry return all language values of the scopes.
+func (c *Client) Entry(ctx context.Context, scopes ...string) (MessageEntry, error) {
+	entry := MessageEntry{}
+	err := c.do(ctx, http.MethodGet, "/v2/messages/"+scopesPath(scopes), nil, &entry)
+	return entry, err
+}
+
+// Create create the value, the error is an Error with status 409 if the value exists.
+func (c *Client) Create(ctx context.Context, item MessageItem) error {
+	return c.do(ctx, http.MethodPost, "/v2/messages", item, nil)
+}
+
+// Upsert create or update the value.
+func (c *Client) Upsert(ctx context.Context, item MessageItem) error {
+	return c.do(ctx, http.MethodPut, "/v2/messages", item, nil)
+}
+
+// Delete delete the value of the language and scopes.
+func (c *Client) Delete(ctx context.Context, language string, scopes ...string) error {
+	query := url.Values{"language": []string{language}}
+	return c.do(ctx, http.MethodDelete, "/v2/messages/"+scopesPath(scopes)+"?"+query.Encode(), nil, nil)
+}
+
+// Batch upsert and delete many values in one request.
+func (c *Client) Batch(ctx context.Context, request BatchRequest) (BatchResult, error) {
+	res := BatchResult{}
+	err := c.do(ctx, http.MethodPost, "/v2/messages/batch", request, &res)
+	return res, err
+}
+
+// Bundle return the bundle of the language under the scopes, the format is 'nested', 'flat' or 'i18next'.
+func (c *Client) Bundle(ctx context.Context, language, format string, scopes ...string) (json.RawMessage, error) {
+	var res json.RawMessage
+	query := url.Values{"format": []string{format}}
+	path := "/v1/bundle/" + url.PathEscape(language) + "/" + scopesPath(scopes) + "?" + query.Encode()
+	err := c.do(ctx, http.MethodGet, path, nil, &res)
+	return res, err
+}
+
+// Catalog download the whole catalog from the paged keys api, so a large catalog is not one huge response. If the etag
+// is not empty and the catalog is not changed, it returns nil and no error. The language values are as the server has
+// them, and the Standard of the I18n is provider.Custom.
+//
+// The ETag, Epoch and Revision are of the first page. The later pages can have the newer values, a watch from the
+// Revision replays the changes of them, so the catalog is the same as the server once they are applied.
+func (c *Client) Catalog(ctx context.Context, etag string) (*Catalog, error) {
+	page, header, err := c.keys(ctx, "", etag)
+	if err != nil || page == nil {
+		return nil, err
+	}
+	revision, _ := strconv.ParseUint(header.Get("X-Catalog-Revision"), 10, 64)
+	res := &Catalog{
+		I18n:     provider.NewI18n(provider.Custom),
+		ETag:     header.Get("ETag"),
+		Epoch:    header.Get("X-Catalog-Epoch"),
+		Revision: revision,
+	}
+	for {
+		for _, item := range page.Items {
+			for language, value := range item.Values {
+				res.I18n.PushMessageByString(language, value, item.Scopes...)
+			}
+		}
+		if len(page.NextCursor) == 0 {
+			return res, nil
+		}
+		if page, _, err = c.keys(ctx, page.NextCursor, ""); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// keyPage is a page of the keys api.
+type keyPage struct {
+	Items      []MessageEntry `json:"items"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// keys return the page of all keys after the cursor and the response headers. If the etag is not empty and the
+// catalog is not changed, the page is nil.
+func (c *Client) keys(ctx context.Context, cursor, etag string) (*keyPage, http.Header, error) {
+	query := url.Values{"limit": []string{strconv.Itoa(maxKeysLimit)}}
+	if len(cursor) != 0 {
+		query.Set("cursor", cursor)
+	}
+	request, err := c.newRequest(ctx, http.MethodGet, "/v1/keys?"+query.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(etag) != 0 {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := c.httpClient().Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotModified {
+		return nil, response.Header, nil
+	}
+	if err = checkResponse(response); err != nil {
+		return nil, nil, err
+	}
+	page := &keyPage{}
+	if err = json.NewDecoder(response.Body).Decode(page); err != nil {
+		return nil, nil, err
+	}
+	return page, response.Header, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	request, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	response, err := c.httpClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err = checkResponse(response); err != nil {
+		return err
+	}
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		value, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(value)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(c.Credential) != 0 {
+		request.Header.Set("Authorization", "Bearer "+c.Credential)
+	}
+	return request, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// checkResponse return an *Error if the status is not 2xx.
+func checkResponse(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	res := &Error{Status: response.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err := json.Unmarshal(body, res); err != nil || len(res.Code) == 0 {
+		res.Code = strings.ToLower(strings.ReplaceAll(http.StatusText(response.StatusCode), " ", "_"))
+		res.Message = strings.TrimSpace(string(body))
+	}
+	return res
+}
+
+func scopesPath(scopes []string) string {
+	escaped := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		escaped = append(escaped, url.PathEscape(scope))
+	}
+	return strings.Join(escaped, "/")
+}
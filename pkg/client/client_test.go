@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/uberate/i18n/cmd/web/config"
+	"github.com/uberate/i18n/internal/web"
+	"github.com/uberate/i18n/pkg/provider"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestServer() (*httptest.Server, *provider.I18n) {
+	gin.SetMode(gin.ReleaseMode)
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+	instance.PushMessage(provider.JapaneseLn, "ログイン", "user", "text", "login")
+
+	c := config.I18nConfig{}
+	c.ApplicationConfig.WatchHistory = 100
+	engine := gin.New()
+	web.RegisterHandler(engine, c, instance)
+	return httptest.NewServer(engine), instance
+}
+
+// eventually wait until the condition is true.
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if condition() {
+			return
+		}
+	}
+	t.Fatal(message)
+}
+
+func TestClient(t *testing.T) {
+	server, _ := newTestServer()
+	defer server.Close()
+	client := New(server.URL)
+	ctx := context.Background()
+
+	if value, err := client.Message(ctx, "japanese", "user", "text", "login"); err != nil || value != "ログイン" {
+		t.Errorf("Message get: %s, %v", value, err)
+	}
+	if _, err := client.Message(ctx, "ja", "user", "none"); !IsNotFound(err) {
+		t.Errorf("Message not found get: %v, want not found error", err)
+	}
+
+	logout := MessageItem{Scopes: []string{"user", "text", "logout"}, Language: "en", Value: "Logout"}
+	if err := client.Upsert(ctx, logout); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Create(ctx, logout); err == nil {
+		t.Error("Create exists value should be an error")
+	}
+	entry, err := client.Entry(ctx, "user", "text", "logout")
+	if err != nil || entry.Values["en"] != "Logout" {
+		t.Errorf("Entry get: %v, %v", entry, err)
+	}
+	result, err := client.Batch(ctx, BatchRequest{Deletes: []MessageItem{{Scopes: logout.Scopes, Language: "en"}}})
+	if err != nil || result.Deleted != 1 {
+		t.Errorf("Batch get: %v, %v", result, err)
+	}
+	if err = client.Delete(ctx, "en", "user", "text", "logout"); !IsNotFound(err) {
+		t.Errorf("Delete not found get: %v", err)
+	}
+
+	bundle, err := client.Bundle(ctx, "ja", "flat", "user")
+	if err != nil || string(bundle) != `{"text.login":"ログイン"}` {
+		t.Errorf("Bundle get: %s, %v", bundle, err)
+	}
+
+	catalog, err := client.Catalog(ctx, "")
+	if err != nil || catalog.Revision == 0 || len(catalog.ETag) == 0 {
+		t.Fatalf("Catalog get: %v, %v", catalog, err)
+	}
+	if catalog, err = client.Catalog(ctx, catalog.ETag); err != nil || catalog != nil {
+		t.Errorf("Catalog with same etag get: %v, %v, want not modified", catalog, err)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	server, instance := newTestServer()
+	defer server.Close()
+
+	fallback := provider.NewI18n(provider.ISO6391)
+	fallback.PushMessage(provider.EnglishLn, "Embedded", "user", "text", "embedded")
+	fallback.PushMessage(provider.EnglishLn, "Old login", "user", "text", "login")
+
+	for _, pollInterval := range []time.Duration{0, 20 * time.Millisecond} {
+		mirror := NewMirror(New(server.URL), MirrorConfig{Fallback: fallback, PollInterval: pollInterval})
+		if value, _ := mirror.Message("en", "user", "text", "login"); value != "Old login" {
+			t.Errorf("Message before sync get: %s, want the fallback value", value)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- mirror.Run(ctx)
+		}()
+
+		eventually(t, "The mirror should be synced", mirror.Synced)
+		if value, _ := mirror.Message("en", "user", "text", "login"); value != "Login" {
+			t.Errorf("Message after sync get: %s, want the server value", value)
+		}
+		if value, _ := mirror.Message("en", "user", "text", "embedded"); value != "Embedded" {
+			t.Errorf("Message not in server get: %s, want the fallback value", value)
+		}
+
+		instance.PushMessage(provider.EnglishLn, "Sign in", "user", "text", "login")
+		eventually(t, "The mirror should receive the change", func() bool {
+			value, _ := mirror.Message("en", "user", "text", "login")
+			return value == "Sign in"
+		})
+		instance.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("Run get: %v, want: %v", err, context.Canceled)
+		}
+	}
+}
+
+func TestMirrorOffline(t *testing.T) {
+	fallback := provider.NewI18n(provider.ISO6391)
+	fallback.PushMessage(provider.EnglishLn, "Embedded", "user", "text", "login")
+
+	mirror := NewMirror(New("http://127.0.0.1:1"), MirrorConfig{
+		Fallback:      fallback,
+		Staleness:     time.Minute,
+		RetryInterval: time.Second,
+	})
+	if err := mirror.Sync(context.Background()); err == nil {
+		t.Error("Sync with an unreachable server should be an error")
+	}
+	if value, ok := mirror.Message("en", "user", "text", "login"); !ok || value != "Embedded" || !mirror.Stale() {
+		t.Errorf("Offline message get: %s, %v, want the fallback value", value, ok)
+	}
+}
+
+func TestCatalogPages(t *testing.T) {
+	server, instance := newTestServer()
+	defer server.Close()
+	for index := 0; index < 2500; index++ {
+		instance.PushMessage(provider.EnglishLn, strconv.Itoa(index), "page", strconv.Itoa(index))
+	}
+
+	catalog, err := New(server.URL).Catalog(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Revision != instance.Revision() || !catalog.I18n.IsMessageEquals(instance) {
+		t.Errorf("Catalog of the pages get revision: %d, want: %d and all values", catalog.Revision, instance.Revision())
+	}
+}
+
+func TestMirrorStaleEvents(t *testing.T) {
+	// the catalog is at revision 5, and the watch replays an older change.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/keys", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Catalog-Epoch", "e")
+		writer.Header().Set("X-Catalog-Revision", "5")
+		_, _ = writer.Write([]byte(`{"items":[{"scopes":["user","login"],"values":{"en":"Sign in"}}],"next_cursor":""}`))
+	})
+	mux.HandleFunc("/v1/watch", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = writer.Write([]byte("event:upsert\ndata:" +
+			`{"type":"upsert","scopes":["user","login"],"language":"en","value":"Login","revision":3}` + "\n\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mirror := NewMirror(New(server.URL), MirrorConfig{})
+	if err := mirror.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mirror.watch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := mirror.Message("en", "user", "login"); value != "Sign in" || mirror.Revision() != 5 {
+		t.Errorf("Mirror after a stale event get: %s, revision: %d, want: Sign in, 5", value, mirror.Revision())
+	}
+}
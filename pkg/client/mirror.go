@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/uberate/i18n/pkg/provider"
+	"github.com/uberate/i18n/pkg/watch"
+	"sync"
+	"time"
+)
+
+var errReset = errors.New("watch reset")
+
+// MirrorConfig is the config of Mirror.
+type MirrorConfig struct {
+	// Standard is the Standard of the local I18n instance, it should be the same as the server. Default is
+	// provider.ISO6391.
+	Standard string
+	// Fallback is the embedded catalog, like a json file from go:embed and read by files.FromJson. It serves the
+	// lookups before the first sync, and the values which are not in the mirror. It can be nil.
+	Fallback *provider.I18n
+	// Staleness is the max age of the mirror since the last confirmation from the server. When the mirror is older,
+	// Stale return true and a sync is started in background on the next lookup. 0 means no limit.
+	Staleness time.Duration
+	// PollInterval is the interval of the conditional catalog downloads. 0 means the changes are streamed by the watch
+	// api instead of polling.
+	PollInterval time.Duration
+	// RetryInterval is the wait after a failed sync, default is 5 seconds.
+	RetryInterval time.Duration
+	// OnError receives the errors of the background sync, it can be nil.
+	OnError func(err error)
+}
+
+// Mirror is a local copy of the server catalog. The lookups are served by the local I18n instance, and the instance is
+// synced by the catalog downloads plus the watch stream, or polling with the ETag. The catalog is downloaded page by
+// page from the keys api, see Client.Catalog. The Mirror is thread-safe.
+//
+//	mirror := client.NewMirror(client.New("http://localhost:3000"), client.MirrorConfig{Fallback: embedded})
+//	go mirror.Run(ctx)
+//	value, ok := mirror.Message("ja", "user", "text", "login")
+type Mirror struct {
+	client *Client
+	config MirrorConfig
+	local  *provider.I18n
+
+	// applyLock keeps the changes applied to the local instance one by one, and the lock protects the fields.
+	applyLock  sync.Mutex
+	lock       sync.Mutex
+	etag       string
+	epoch      string
+	revision   uint64
+	synced     bool
+	confirmed  time.Time
+	refreshing bool
+}
+
+// NewMirror return a *Mirror of the client server, call Sync or Run to sync it.
+func NewMirror(client *Client, config MirrorConfig) *Mirror {
+	if len(config.Standard) == 0 {
+		config.Standard = provider.ISO6391
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 5 * time.Second
+	}
+	return &Mirror{
+		client: client,
+		config: config,
+		local:  provider.NewI18n(config.Standard),
+	}
+}
+
+// I18n return the local I18n instance, it is changed by the sync. Don't change it.
+func (m *Mirror) I18n() *provider.I18n {
+	return m.local
+}
+
+// Message return the value of the language string value and scopes. The value is from the mirror, or the Fallback if
+// the mirror is never synced or hasn't the value. If the mirror is stale, a sync is started in background.
+func (m *Mirror) Message(ln string, scopes ...string) (string, bool) {
+	if m.Stale() {
+		m.refresh()
+	}
+
+	if m.Synced() {
+		if value, ok := m.local.Lookup(ln, scopes...); ok {
+			return value, true
+		}
+	}
+	if m.config.Fallback != nil {
+		return m.config.Fallback.Lookup(ln, scopes...)
+	}
+	return "", false
+}
+
+// Synced return true if the mirror has been synced from the server at least once.
+func (m *Mirror) Synced() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.synced
+}
+
+// Revision return the server revision which the mirror has seen.
+func (m *Mirror) Revision() uint64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.revision
+}
+
+// Confirmed return the last time when the server confirmed the mirror is fresh.
+func (m *Mirror) Confirmed() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.confirmed
+}
+
+// Stale return true if the mirror is never synced, or it's older than the Staleness.
+func (m *Mirror) Stale() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.synced {
+		return true
+	}
+	return m.config.Staleness > 0 && time.Since(m.confirmed) > m.config.Staleness
+}
+
+// Sync download the catalog if it's changed since the last sync, and replace the mirror by it.
+func (m *Mirror) Sync(ctx context.Context) error {
+	m.lock.Lock()
+	etag := m.etag
+	if !m.synced {
+		etag = ""
+	}
+	m.lock.Unlock()
+
+	catalog, err := m.client.Catalog(ctx, etag)
+	if err != nil {
+		return err
+	}
+
+	m.applyLock.Lock()
+	defer m.applyLock.Unlock()
+
+	// the watch has applied newer changes during the download.
+	m.lock.Lock()
+	if catalog != nil && m.synced && catalog.Epoch == m.epoch && catalog.Revision < m.revision {
+		catalog = nil
+	}
+	m.lock.Unlock()
+	if catalog != nil {
+		m.local.Replace(catalog.I18n)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if catalog != nil {
+		m.etag, m.epoch, m.revision, m.synced = catalog.ETag, catalog.Epoch, catalog.Revision, true
+	}
+	m.confirmed = time.Now()
+	return nil
+}
+
+// Run keep the mirror synced until the ctx is done, and return the ctx error. The errors of the sync are passed to
+// MirrorConfig.OnError, and the sync is retried after the RetryInterval.
+func (m *Mirror) Run(ctx context.Context) error {
+	needSync := true
+	for {
+		var err error
+		if needSync || m.config.PollInterval > 0 {
+			if err = m.Sync(ctx); err == nil {
+				needSync = false
+			}
+		}
+		if err == nil {
+			if m.config.PollInterval > 0 {
+				if !sleep(ctx, m.config.PollInterval) {
+					return ctx.Err()
+				}
+				continue
+			}
+			if err = m.watch(ctx); err == errReset {
+				needSync = true
+				continue
+			}
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			m.report(err)
+			if !sleep(ctx, m.config.RetryInterval) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// watch apply the changes of the watch stream to the mirror until the stream ends. If the resume revision is not
+// available, it returns errReset.
+func (m *Mirror) watch(ctx context.Context) error {
+	m.lock.Lock()
+	options := WatchOptions{Since: m.revision, Epoch: m.epoch}
+	m.lock.Unlock()
+
+	return m.client.Watch(ctx, options, func(event StreamEvent) error {
+		if event.Name == StreamReset {
+			// reload the catalog even if it's not changed, the revision of the mirror can be from another epoch.
+			m.lock.Lock()
+			m.etag = ""
+			m.lock.Unlock()
+			return errReset
+		}
+
+		m.applyLock.Lock()
+		defer m.applyLock.Unlock()
+		changed := event.Name == string(watch.EventUpsert) || event.Name == string(watch.EventDelete)
+		// the change is already in the mirror, like a change before the catalog which is downloaded during the watch,
+		// so the revision of the mirror never goes backwards. The events are of the epoch which the watch resumes.
+		m.lock.Lock()
+		if changed && options.Epoch == m.epoch && event.Revision <= m.revision {
+			changed = false
+		}
+		m.lock.Unlock()
+		if changed {
+			m.local.PushMessageByString(event.Event.Language, event.Event.Value, event.Event.Scopes...)
+		}
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if changed {
+			// the etag is unknown until the next download.
+			m.revision, m.etag = event.Revision, ""
+		}
+		m.confirmed = time.Now()
+		return nil
+	})
+}
+
+// refresh start a sync in background, only one background sync at the same time.
+func (m *Mirror) refresh() {
+	m.lock.Lock()
+	if m.refreshing {
+		m.lock.Unlock()
+		return
+	}
+	m.refreshing = true
+	m.lock.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.RetryInterval)
+		defer cancel()
+		if err := m.Sync(ctx); err != nil {
+			m.report(err)
+		}
+
+		m.lock.Lock()
+		m.refreshing = false
+		m.lock.Unlock()
+	}()
+}
+
+func (m *Mirror) report(err error) {
+	if m.config.OnError != nil {
+		m.config.OnError(err)
+	}
+}
+
+// sleep wait the duration, return false if the ctx is done.
+func sleep(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
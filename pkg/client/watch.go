@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/uberate/i18n/pkg/watch"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	StreamReady     = "ready"     // The replay is finished, the Revision is the server revision.
+	StreamReset     = "reset"     // The resume revision is not available, the catalog should be reloaded.
+	StreamHeartbeat = "heartbeat" // The stream is alive.
+)
+
+// StreamEvent is an event of the watch stream. The Name is 'upsert', 'delete', StreamReady, StreamReset or
+// StreamHeartbeat. The Event is set for 'upsert' and 'delete', the Revision is set for all events except the
+// StreamHeartbeat, and the Epoch is set for StreamReady and StreamReset.
+type StreamEvent struct {
+	Name     string
+	Event    watch.Event
+	Revision uint64
+	Epoch    string
+}
+
+// WatchOptions is the filter and the resume revision of Client.Watch.
+type WatchOptions struct {
+	// Scopes is the scope prefix.
+	Scopes   []string
+	Language string
+	// Since is the revision to resume from, 0 means only the new changes. The Epoch is the server epoch of the Since,
+	// like Catalog.Epoch, the stream is reset if the server has restarted since then.
+	Since uint64
+	Epoch string
+}
+
+// Watch receive the changes of the server by the Server-Sent Events, and invoke f for every event. It blocks until the
+// ctx is done, the stream ends, or f returns an error. The error of f is returned.
+func (c *Client) Watch(ctx context.Context, options WatchOptions, f func(event StreamEvent) error) error {
+	query := url.Values{}
+	if len(options.Scopes) != 0 {
+		query.Set("scope", strings.Join(options.Scopes, "."))
+	}
+	if len(options.Language) != 0 {
+		query.Set("language", options.Language)
+	}
+	if options.Since != 0 {
+		query.Set("since", strconv.FormatUint(options.Since, 10))
+		query.Set("epoch", options.Epoch)
+	}
+
+	request, err := c.newRequest(ctx, http.MethodGet, "/v1/watch?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	response, err := c.httpClient().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if err = checkResponse(response); err != nil {
+		return err
+	}
+
+	name, data := "", ""
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case len(line) == 0:
+			if len(data) != 0 {
+				if err = dispatchStreamEvent(name, data, f); err != nil {
+					return err
+				}
+			}
+			name, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			if err = f(StreamEvent{Name: StreamHeartbeat}); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if len(data) != 0 {
+				data += "\n"
+			}
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
+
+func dispatchStreamEvent(name, data string, f func(event StreamEvent) error) error {
+	event := StreamEvent{Name: name}
+	switch name {
+	case string(watch.EventUpsert), string(watch.EventDelete):
+		if err := json.Unmarshal([]byte(data), &event.Event); err != nil {
+			return err
+		}
+		event.Revision = event.Event.Revision
+	case StreamReady, StreamReset:
+		state := struct {
+			Epoch    string `json:"epoch"`
+			Revision uint64 `json:"revision"`
+		}{}
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			return err
+		}
+		event.Revision, event.Epoch = state.Revision, state.Epoch
+	default:
+		// unknown events are ignored for the compatibility.
+		return nil
+	}
+	return f(event)
+}
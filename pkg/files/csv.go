@@ -0,0 +1,105 @@
+package files
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"sort"
+	"strings"
+)
+
+// KeySeparator is the separator of the scopes in the key of the csv, po and xliff formats, like 'user.text.login'.
+const KeySeparator = "."
+
+// csvKeyColumn is the header of the key column of the csv format.
+const csvKeyColumn = "key"
+
+// EncodeCSV write the instance to the writer as csv. The first row is the header, the first column is the key and the
+// others are the languages. A row is a key, the cell of a language which hasn't the value is empty.
+func EncodeCSV(writer io.Writer, instance *provider.I18n) error {
+	keys, languages, values := tableOf(instance)
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(append([]string{csvKeyColumn}, languages...)); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		row := []string{key}
+		for _, language := range languages {
+			row = append(row, values[key][language])
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// DecodeCSV read an instance in csv from the reader, see EncodeCSV. The empty cells are skipped, and the languages are
+// kept as the header, so the Standard of the result is provider.Custom.
+func DecodeCSV(reader io.Reader) (*provider.I18n, error) {
+	csvReader := csv.NewReader(reader)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return provider.NewI18n(provider.Custom), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the csv which is saved by a spreadsheet may start with a byte order mark.
+	if len(header) == 0 || strings.TrimPrefix(header[0], "\ufeff") != csvKeyColumn {
+		return nil, fmt.Errorf("the first column of the csv header should be '%s'", csvKeyColumn)
+	}
+
+	res := provider.NewI18n(provider.Custom)
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for index := 1; index < len(row) && index < len(header); index++ {
+			if len(row[index]) != 0 {
+				res.PushMessageByString(strings.TrimSpace(header[index]), row[index], splitKey(row[0])...)
+			}
+		}
+	}
+}
+
+// tableOf return the keys and the languages of the instance sorted, and the values by the key and the language.
+func tableOf(instance *provider.I18n) ([]string, []string, map[string]map[string]string) {
+	values := map[string]map[string]string{}
+	languageSet := map[string]struct{}{}
+	instance.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		key := strings.Join(flags, KeySeparator)
+		if _, ok := values[key]; !ok {
+			values[key] = map[string]string{}
+		}
+		values[key][languageValue] = messageValue
+		languageSet[languageValue] = struct{}{}
+	})
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	languages := make([]string, 0, len(languageSet))
+	for language := range languageSet {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return keys, languages, values
+}
+
+// splitKey return the scopes of the key, an empty key is the root scope.
+func splitKey(key string) []string {
+	if len(key) == 0 {
+		return nil
+	}
+	return strings.Split(key, KeySeparator)
+}
@@ -1,23 +1,188 @@
 package files
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"github.com/uberate/i18n/pkg/provider"
 	"github.com/uberate/mocker-utils/files"
+	"io"
 	"io/fs"
+	"mime"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
 var readers = map[string]func(string) (*provider.I18n, error){
-	".json": ReadFromJSONFile,
+	".json":  ReadFromJSONFile,
+	".csv":   readFileWith(DecodeCSV),
+	".po":    readFileWith(DecodeGettextPO),
+	".xlf":   readFileWith(DecodeXLIFF),
+	".xliff": readFileWith(DecodeXLIFF),
+}
+
+var writers = map[string]func(string, *provider.I18n) error{
+	".json":  WriteToJSONFile,
+	".csv":   writeFileWith(EncodeCSV),
+	".po":    writeFileWith(EncodeGettextPO),
+	".xlf":   writeFileWith(EncodeXLIFF),
+	".xliff": writeFileWith(EncodeXLIFF),
+}
+
+var encoders = map[string]func(io.Writer, *provider.I18n) error{
+	".json":  EncodeJSON,
+	".csv":   EncodeCSV,
+	".po":    EncodeGettextPO,
+	".xlf":   EncodeXLIFF,
+	".xliff": EncodeXLIFF,
+}
+
+var decoders = map[string]func(io.Reader) (*provider.I18n, error){
+	".json":  DecodeJSON,
+	".csv":   DecodeCSV,
+	".po":    DecodeGettextPO,
+	".xlf":   DecodeXLIFF,
+	".xliff": DecodeXLIFF,
+}
+
+// contentTypes is the media types of the formats, the apis choose the format by them.
+var contentTypes = map[string]string{
+	".json":  "application/json",
+	".csv":   "text/csv",
+	".po":    "text/x-gettext-translation",
+	".xlf":   "application/xliff+xml",
+	".xliff": "application/xliff+xml",
+}
+
+// Exts return the extensions of all supported formats, sorted.
+func Exts() []string {
+	res := make([]string, 0, len(encoders))
+	for ext := range encoders {
+		res = append(res, ext)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Encoder return the function which writes an instance in the format of specify file extension, like '.json'. The
+// extension is case-insensitive.
+func Encoder(ext string) (func(io.Writer, *provider.I18n) error, bool) {
+	encodeFunc, ok := encoders[strings.ToLower(ext)]
+	return encodeFunc, ok
+}
+
+// Decoder return the function which reads an instance in the format of specify file extension, like '.json'. The
+// extension is case-insensitive.
+func Decoder(ext string) (func(io.Reader) (*provider.I18n, error), bool) {
+	decodeFunc, ok := decoders[strings.ToLower(ext)]
+	return decodeFunc, ok
+}
+
+// ContentType return the media type of specify file extension, like 'application/json' of '.json'. It returns
+// 'application/octet-stream' if the extension is not supported.
+func ContentType(ext string) string {
+	if contentType, ok := contentTypes[strings.ToLower(ext)]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// ExtOf return the file extension of the media type, like '.json' of 'application/json; charset=utf-8'. If some
+// extensions have the same media type, the first one in order is returned.
+func ExtOf(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	for _, ext := range Exts() {
+		if contentTypes[ext] == mediaType {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// readFileWith return the read function of the file by the decode function.
+func readFileWith(decode func(io.Reader) (*provider.I18n, error)) func(string) (*provider.I18n, error) {
+	return func(file string) (*provider.I18n, error) {
+		reader, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return decode(reader)
+	}
+}
+
+// writeFileWith return the write function of the file by the encode function.
+func writeFileWith(encode func(io.Writer, *provider.I18n) error) func(string, *provider.I18n) error {
+	return func(file string, instance *provider.I18n) error {
+		writer, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		if err = encode(writer, instance); err != nil {
+			_ = writer.Close()
+			return err
+		}
+		return writer.Close()
+	}
+}
+
+// Reader return the read function of specify file extension, like '.json'. The extension is case-insensitive.
+func Reader(ext string) (func(string) (*provider.I18n, error), bool) {
+	readFunc, ok := readers[strings.ToLower(ext)]
+	return readFunc, ok
+}
+
+// Writer return the write function of specify file extension, like '.json'. The extension is case-insensitive.
+func Writer(ext string) (func(string, *provider.I18n) error, bool) {
+	writeFunc, ok := writers[strings.ToLower(ext)]
+	return writeFunc, ok
+}
+
+// ReadFile read an i18n instance from the file, the format is decided by the extension of the file.
+func ReadFile(file string) (*provider.I18n, error) {
+	readFunc, ok := Reader(path.Ext(file))
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format: %s", file)
+	}
+	return readFunc(file)
+}
+
+// WriteFile write the i18n instance to the file, the format is decided by the extension of the file.
+func WriteFile(file string, instance *provider.I18n) error {
+	writeFunc, ok := Writer(path.Ext(file))
+	if !ok {
+		return fmt.Errorf("unsupported file format: %s", file)
+	}
+	return writeFunc(file, instance)
+}
+
+// LoadedFile is a file which has been read by Load. The Checksum is the hex sha256 of the file content.
+type LoadedFile struct {
+	Path     string    `json:"path" yaml:"path"`
+	Checksum string    `json:"checksum" yaml:"checksum"`
+	Size     int64     `json:"size" yaml:"size"`
+	ModTime  time.Time `json:"mod_time" yaml:"mod_time"`
 }
 
 // FromFiles will read the files in specify files and load to an i18n instance. By read order, the new instance will
 // cover new instance.
 func FromFiles(standard string, paths ...string) (*provider.I18n, error) {
+	res, _, err := Load(standard, paths...)
+	return res, err
+}
+
+// Load like FromFiles, but it also returns the files which have been read, in read order. The files which not exist
+// or have an unsupported extension are skipped.
+func Load(standard string, paths ...string) (*provider.I18n, []LoadedFile, error) {
 	res := provider.NewI18n(standard)
+	loaded := []LoadedFile{}
 
 	for _, pathItem := range paths {
 		if !files.IsFileExists(pathItem) {
@@ -37,21 +202,37 @@ func FromFiles(standard string, paths ...string) (*provider.I18n, error) {
 				children = append(children, pathStr)
 				return nil
 			})
-			childI18n, err := FromFiles(standard, children...)
+			childI18n, childLoaded, err := Load(standard, children...)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			res.CoveredMessage(childI18n)
+			loaded = append(loaded, childLoaded...)
 		} else {
 			if readFunc, ok := readers[strings.ToLower(path.Ext(pathItem))]; ok {
 				instance, err := readFunc(pathItem)
 				if err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 				res.CoveredMessage(instance)
+
+				file, err := loadedFile(pathItem, fi)
+				if err != nil {
+					return nil, nil, err
+				}
+				loaded = append(loaded, file)
 			}
 		}
 	}
 
-	return res, nil
+	return res, loaded, nil
+}
+
+func loadedFile(file string, fi fs.FileInfo) (LoadedFile, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return LoadedFile{}, err
+	}
+	sum := sha256.Sum256(content)
+	return LoadedFile{Path: file, Checksum: hex.EncodeToString(sum[:]), Size: fi.Size(), ModTime: fi.ModTime()}, nil
 }
@@ -1,8 +1,14 @@
 package files
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/uberate/i18n/pkg/provider"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -41,3 +47,139 @@ func TestToJson(t *testing.T) {
 		fmt.Println(ToJSON(BaseI18nValue))
 	}
 }
+
+func TestLoad(t *testing.T) {
+	Init()
+	dir := t.TempDir()
+	if err := WriteFile(filepath.Join(dir, "base.json"), BaseI18nValue); err != nil {
+		t.Fatal(err)
+	}
+	override := provider.NewI18n(provider.ISO6391)
+	override.PushMessage(provider.EnglishLn, "Error", "system", "text", "error")
+	if err := os.Mkdir(filepath.Join(dir, "override"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(filepath.Join(dir, "override", "en.json"), override); err != nil {
+		t.Fatal(err)
+	}
+
+	instance, loaded, err := Load(provider.ISO6391,
+		filepath.Join(dir, "base.json"), filepath.Join(dir, "none.json"), filepath.Join(dir, "override"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := instance.Message(provider.EnglishLn, "system", "text", "error"); value != "Error" {
+		t.Errorf("Get value: %s, want the value of the later file: Error", value)
+	}
+	if len(loaded) != 2 || loaded[0].Path != filepath.Join(dir, "base.json") {
+		t.Fatalf("Get loaded files: %v, want base.json and override/en.json", loaded)
+	}
+	content, _ := os.ReadFile(loaded[0].Path)
+	sum := sha256.Sum256(content)
+	if loaded[0].Checksum != hex.EncodeToString(sum[:]) || loaded[0].Size != int64(len(content)) {
+		t.Errorf("Get loaded file: %v, want the sha256 and size of the content", loaded[0])
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	Init()
+	for _, ext := range Exts() {
+		encode, _ := Encoder(ext)
+		decode, ok := Decoder(strings.ToUpper(ext))
+		if !ok {
+			t.Fatalf("the format %s has no decoder", ext)
+		}
+		buffer := &bytes.Buffer{}
+		if err := encode(buffer, BaseI18nValue); err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		res, err := decode(buffer)
+		if err != nil {
+			t.Fatalf("%s: %v", ext, err)
+		}
+		if !res.IsMessageEquals(BaseI18nValue) {
+			t.Errorf("%s: the decoded instance should equal the encoded one", ext)
+		}
+		if got, ok := ExtOf(ContentType(ext) + "; charset=utf-8"); !ok || ContentType(got) != ContentType(ext) {
+			t.Errorf("ExtOf the content type of %s get: %s, %v", ext, got, ok)
+		}
+	}
+	if _, ok := ExtOf("text/html"); ok {
+		t.Error("ExtOf an unsupported media type should be false")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name    string
+		ext     string
+		content string
+		want    map[string]string
+	}{
+		{"csv", ".csv", "\ufeffkey,en,ja\nuser.text.login,Login,ログイン\nuser.text.logout,\"Log, out\",\n",
+			map[string]string{
+				"en:user.text.login": "Login", "ja:user.text.login": "ログイン", "en:user.text.logout": "Log, out",
+			}},
+		{"po of one language", ".po", `# translator comments
+msgid ""
+msgstr ""
+"Language: ja\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: src/login.go:10
+msgid "user.text.login"
+msgstr "ログ"
+"イン"
+
+#, fuzzy
+msgid "user.text.logout"
+msgstr "ログアウト"
+
+msgid "user.text.help"
+msgstr ""
+
+msgctxt "en"
+msgid "user.text.help"
+msgstr "Line one\nline \"two\""
+
+#~ msgid "user.text.old"
+#~ msgstr "古い"
+`, map[string]string{"ja:user.text.login": "ログイン", "en:user.text.help": "Line one\nline \"two\""}},
+		{"xliff from the translators", ".xlf", `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app" source-language="en" target-language="ja" datatype="plaintext">
+    <header><tool tool-id="editor"/></header>
+    <body>
+      <trans-unit id="user.text.login"><source>Login</source><target>ログイン</target></trans-unit>
+      <trans-unit id="user.text.logout"><source>Logout</source></trans-unit>
+    </body>
+  </file>
+</xliff>`, map[string]string{"ja:user.text.login": "ログイン"}},
+	}
+	for _, c := range cases {
+		decode, _ := Decoder(c.ext)
+		res, err := decode(strings.NewReader(c.content))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		values := map[string]string{}
+		res.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+			values[languageValue+":"+strings.Join(flags, KeySeparator)] = messageValue
+		})
+		if fmt.Sprint(values) != fmt.Sprint(c.want) {
+			t.Errorf("%s get: %v, want: %v", c.name, values, c.want)
+		}
+	}
+
+	for ext, content := range map[string]string{
+		".csv":  "name,en\nlogin,Login\n",
+		".po":   "msgid \"user.text.login\"\nmsgstr \"Login\"\n",
+		".xlf":  "<xliff><file",
+		".json": "{",
+	} {
+		decode, _ := Decoder(ext)
+		if _, err := decode(strings.NewReader(content)); err == nil {
+			t.Errorf("decode the invalid %s should fail", ext)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package files
 import (
 	"encoding/json"
 	"github.com/uberate/i18n/pkg/provider"
+	"io"
 	"os"
 )
 
@@ -40,3 +41,17 @@ func FromJson(value string) (*provider.I18n, error) {
 	}
 	return res, nil
 }
+
+// EncodeJSON write the instance to the writer as json.
+func EncodeJSON(writer io.Writer, instance *provider.I18n) error {
+	return json.NewEncoder(writer).Encode(instance)
+}
+
+// DecodeJSON read an instance in json from the reader.
+func DecodeJSON(reader io.Reader) (*provider.I18n, error) {
+	res := &provider.I18n{}
+	if err := json.NewDecoder(reader).Decode(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,160 @@
+package files
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EncodeGettextPO write the instance to the writer as a gettext po file. An entry is a value, the msgctxt is the
+// language, the msgid is the key and the msgstr is the value. The header has no 'Language', because the file has all
+// languages.
+func EncodeGettextPO(writer io.Writer, instance *provider.I18n) error {
+	keys, languages, values := tableOf(instance)
+
+	buffer := bufio.NewWriter(writer)
+	fmt.Fprintln(buffer, `msgid ""`)
+	fmt.Fprintln(buffer, `msgstr "Content-Type: text/plain; charset=UTF-8\n"`)
+	for _, language := range languages {
+		for _, key := range keys {
+			value, ok := values[key][language]
+			if !ok {
+				continue
+			}
+			fmt.Fprintln(buffer)
+			fmt.Fprintf(buffer, "msgctxt %s\n", poQuote(language))
+			fmt.Fprintf(buffer, "msgid %s\n", poQuote(key))
+			fmt.Fprintf(buffer, "msgstr %s\n", poQuote(value))
+		}
+	}
+	return buffer.Flush()
+}
+
+// poEntry is an entry of a po file, the field is the keyword of the last line, the continued strings are appended to
+// it.
+type poEntry struct {
+	context string
+	id      string
+	value   string
+	fuzzy   bool
+	field   string
+}
+
+// DecodeGettextPO read an instance in gettext po from the reader, see EncodeGettextPO. The language of an entry is its
+// msgctxt, or the 'Language' of the header if it has no msgctxt, so a po file of one language from the translators can
+// be read too. The untranslated, fuzzy and obsolete entries are skipped, and the msgstr[0] is the value of a plural
+// entry.
+func DecodeGettextPO(reader io.Reader) (*provider.I18n, error) {
+	var entries []*poEntry
+	entry := &poEntry{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#,"):
+			// the flags comment is before the keywords of its entry.
+			if len(entry.field) != 0 {
+				entries, entry = append(entries, entry), &poEntry{}
+			}
+			entry.fuzzy = entry.fuzzy || strings.Contains(line, "fuzzy")
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, `"`):
+			value, err := strconv.Unquote(line)
+			if err != nil || len(entry.field) == 0 {
+				return nil, fmt.Errorf("po line %d: invalid string", number)
+			}
+			entry.append(value)
+			continue
+		}
+
+		keyword, rest := line, ""
+		if index := strings.IndexAny(line, " \t"); index >= 0 {
+			keyword, rest = line[:index], strings.TrimSpace(line[index:])
+		}
+		value, err := strconv.Unquote(rest)
+		if err != nil {
+			return nil, fmt.Errorf("po line %d: invalid string", number)
+		}
+		switch keyword {
+		case "msgctxt", "msgid":
+			// a new entry starts by its msgctxt, or by its msgid if it has no msgctxt.
+			if len(entry.field) != 0 && (keyword == "msgctxt" || entry.field != "msgctxt") {
+				entries, entry = append(entries, entry), &poEntry{}
+			}
+		case "msgstr", "msgstr[0]", "msgid_plural":
+		default:
+			if !strings.HasPrefix(keyword, "msgstr[") {
+				return nil, fmt.Errorf("po line %d: unknown keyword %s", number, keyword)
+			}
+			keyword = "msgstr[n]"
+		}
+		if keyword == "msgstr[0]" {
+			keyword = "msgstr"
+		}
+		entry.field = keyword
+		entry.append(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	entries = append(entries, entry)
+
+	res := provider.NewI18n(provider.Custom)
+	language := ""
+	for _, item := range entries {
+		if len(item.id) == 0 && len(item.context) == 0 {
+			language = poHeader(item.value, "Language")
+			break
+		}
+	}
+	for _, item := range entries {
+		if len(item.id) == 0 || len(item.value) == 0 || item.fuzzy {
+			continue
+		}
+		itemLanguage := item.context
+		if len(itemLanguage) == 0 {
+			itemLanguage = language
+		}
+		if len(itemLanguage) == 0 {
+			return nil, fmt.Errorf("po entry %s: no language, the msgctxt or the 'Language' header is required",
+				item.id)
+		}
+		res.PushMessageByString(itemLanguage, item.value, splitKey(item.id)...)
+	}
+	return res, nil
+}
+
+// append the string to the field of the last keyword.
+func (e *poEntry) append(value string) {
+	switch e.field {
+	case "msgctxt":
+		e.context += value
+	case "msgid":
+		e.id += value
+	case "msgstr":
+		e.value += value
+	}
+}
+
+// poHeader return the field of the po header, like 'ja' of the 'Language: ja'.
+func poHeader(header, name string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if index := strings.Index(line, ":"); index >= 0 && strings.TrimSpace(line[:index]) == name {
+			return strings.TrimSpace(line[index+1:])
+		}
+	}
+	return ""
+}
+
+// poQuote return the po string of the value, the escapes of po are the same as go.
+func poQuote(value string) string {
+	return strconv.Quote(value)
+}
@@ -0,0 +1,93 @@
+package files
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+)
+
+const xliffVersion = "1.2"
+
+// xliffDocument is the XLIFF 1.2 document, the elements and attributes which are not used are ignored.
+type xliffDocument struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string      `xml:"version,attr"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr,omitempty"`
+	Datatype       string      `xml:"datatype,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID     string  `xml:"id,attr"`
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// EncodeXLIFF write the instance to the writer as XLIFF 1.2. A file element is a language, its source-language is the
+// language and it has no target. A trans-unit is a value, the id is the key and the source is the value.
+func EncodeXLIFF(writer io.Writer, instance *provider.I18n) error {
+	keys, languages, values := tableOf(instance)
+
+	document := xliffDocument{Version: xliffVersion, Files: []xliffFile{}}
+	for _, language := range languages {
+		file := xliffFile{Original: "i18n", SourceLanguage: language, Datatype: "plaintext", Units: []xliffUnit{}}
+		for _, key := range keys {
+			if value, ok := values[key][language]; ok {
+				file.Units = append(file.Units, xliffUnit{ID: key, Source: value})
+			}
+		}
+		document.Files = append(document.Files, file)
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// DecodeXLIFF read an instance in XLIFF 1.2 from the reader, see EncodeXLIFF. The values of a file element with a
+// target-language are the targets of the trans-units, so a file from the translators imports the translations only,
+// else the values are the sources in the source-language. The empty values are skipped.
+func DecodeXLIFF(reader io.Reader) (*provider.I18n, error) {
+	document := xliffDocument{}
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	res := provider.NewI18n(provider.Custom)
+	for _, file := range document.Files {
+		language := file.TargetLanguage
+		if len(language) == 0 {
+			language = file.SourceLanguage
+		}
+		if len(language) == 0 {
+			return nil, fmt.Errorf("xliff file %s: no language, the source-language is required", file.Original)
+		}
+		for _, unit := range file.Units {
+			value := unit.Source
+			if len(file.TargetLanguage) != 0 {
+				value = ""
+				if unit.Target != nil {
+					value = *unit.Target
+				}
+			}
+			if len(value) != 0 {
+				res.PushMessageByString(language, value, splitKey(unit.ID)...)
+			}
+		}
+	}
+	return res, nil
+}
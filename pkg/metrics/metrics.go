@@ -0,0 +1,190 @@
+// Package metrics is the Prometheus metrics of the I18n instances: the lookup hits and misses, the key counts and the
+// mutations of the catalog, and the requests and reloads of the server which serves it.
+//
+// A library user can collect the lookup metrics of its own instance:
+//
+//	m := metrics.New(prometheus.DefaultRegisterer)
+//	cancel := m.Instrument(instance)
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/uberate/i18n/pkg/provider"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const namespace = "i18n"
+
+const (
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// LanguageOther is the language label of the missed lookups whose language is not in the catalog, so the languages
+// from the requests can't grow the label values without bound.
+const LanguageOther = "other"
+
+// Metrics is the collectors of the I18n instances, it is safe for concurrent use.
+type Metrics struct {
+	registerer prometheus.Registerer
+
+	requests  *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	lookups   *prometheus.CounterVec
+	reloads   *prometheus.CounterVec
+	mutations *prometheus.CounterVec
+}
+
+// New return a *Metrics whose collectors are registered to the registerer. It panics if the collectors have been
+// registered, so create only one Metrics for a registerer.
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		registerer: registerer,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "The count of the http requests by the method, route and status.",
+		}, []string{"method", "route", "status"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "The latency of the http requests by the method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lookups_total",
+			Help:      "The count of the message lookups by the language and result (hit or miss).",
+		}, []string{"language", "result"}),
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reloads_total",
+			Help:      "The count of the catalog loads by the result (success or failure).",
+		}, []string{"result"}),
+		mutations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mutations_total",
+			Help:      "The count of the message changes by the type (added, changed or removed).",
+		}, []string{"type"}),
+	}
+	registerer.MustRegister(m.requests, m.durations, m.lookups, m.reloads, m.mutations)
+	return m
+}
+
+// Unregister remove the collectors of New from the registerer, so another Metrics can be registered to it, like the
+// Metrics of a project which is created again.
+func (m *Metrics) Unregister() {
+	for _, collector := range []prometheus.Collector{m.requests, m.durations, m.lookups, m.reloads, m.mutations} {
+		m.registerer.Unregister(collector)
+	}
+}
+
+// Instrument collect the metrics of the instance: a LookupHook is chained to the current hook of the instance, the
+// changes are counted, and the key counts are reported on every scrape. The cancel func stops all of them.
+//
+// The lookups are counted by the language and the result, the language of a lookup is LanguageOther if it has never
+// been in the instance.
+func (m *Metrics) Instrument(instance *provider.I18n) (cancel func()) {
+	languages := &languageSet{values: map[string]bool{}}
+	unsubscribe := instance.Subscribe(func(event provider.ChangeEvent) {
+		m.mutations.WithLabelValues(string(event.Type)).Inc()
+		if event.Type != provider.ChangeRemoved {
+			languages.add(event.Language)
+		}
+	})
+	languages.add(instance.Languages()...)
+
+	previous := instance.LookupHook()
+	instance.SetLookupHook(provider.ChainLookupHooks(previous, m.lookupHook(languages)))
+
+	catalog := &catalogCollector{instance: instance}
+	registered := m.registerer.Register(catalog) == nil
+
+	return func() {
+		instance.SetLookupHook(previous)
+		unsubscribe()
+		if registered {
+			m.registerer.Unregister(catalog)
+		}
+	}
+}
+
+// lookupHook return a provider.LookupHook which counts the hits and misses of the languages.
+func (m *Metrics) lookupHook(languages *languageSet) provider.LookupHook {
+	return func(ln string, found bool, scopes ...string) {
+		result, language := ResultMiss, ln
+		if found {
+			result = ResultHit
+		} else if !languages.has(ln) {
+			language = LanguageOther
+		}
+		m.lookups.WithLabelValues(language, result).Inc()
+	}
+}
+
+// ObserveRequest count a http request, the route is the route pattern like '/v1/bundle/:ln/*scopes' instead of the
+// path, so the label values are bounded.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.durations.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveReload count a load of the catalog, a nil err means success.
+func (m *Metrics) ObserveReload(err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+	m.reloads.WithLabelValues(result).Inc()
+}
+
+var (
+	keysDesc = prometheus.NewDesc(namespace+"_catalog_keys", "The count of the values by the language.",
+		[]string{"language"}, nil)
+	revisionDesc = prometheus.NewDesc(namespace+"_catalog_revision", "The revision of the catalog.", nil, nil)
+)
+
+// languageSet is the languages which have been in an instance, they are the bounded label values of the lookups.
+type languageSet struct {
+	lock   sync.RWMutex
+	values map[string]bool
+}
+
+func (s *languageSet) add(languages ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, language := range languages {
+		s.values[language] = true
+	}
+}
+
+func (s *languageSet) has(language string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.values[language]
+}
+
+// catalogCollector reports the key counts and the revision of an instance on every scrape.
+type catalogCollector struct {
+	instance *provider.I18n
+}
+
+func (c *catalogCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- keysDesc
+	descs <- revisionDesc
+}
+
+func (c *catalogCollector) Collect(metrics chan<- prometheus.Metric) {
+	counts := map[string]int{}
+	c.instance.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		counts[languageValue]++
+	})
+	for language, count := range counts {
+		metrics <- prometheus.MustNewConstMetric(keysDesc, prometheus.GaugeValue, float64(count), language)
+	}
+	metrics <- prometheus.MustNewConstMetric(revisionDesc, prometheus.GaugeValue, float64(c.instance.Revision()))
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.PushMessageByString("en", "Login", "user", "text", "login")
+	cancel := m.Instrument(instance)
+
+	instance.Lookup("en", "user", "text", "login")
+	instance.Lookup("en", "user", "text", "none")
+	instance.Lookup("ja", "user", "text", "login")
+	instance.PushMessageByString("ja", "ログイン", "user", "text", "login")
+	instance.PushMessageByString("en", "Sign in", "user", "text", "login")
+	m.ObserveReload(nil)
+	m.ObserveReload(errors.New("broken file"))
+	m.ObserveRequest("GET", "/v1/languages", 200, time.Millisecond)
+
+	// the mutations are counted by the dispatcher of the instance, wait for it.
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(m.mutations.WithLabelValues(string(provider.ChangeChanged))) == 0 &&
+		time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// the language is in the catalog after the change.
+	instance.Lookup("ja", "user", "text", "none")
+
+	want := `
+# HELP i18n_catalog_keys The count of the values by the language.
+# TYPE i18n_catalog_keys gauge
+i18n_catalog_keys{language="en"} 1
+i18n_catalog_keys{language="ja"} 1
+# HELP i18n_lookups_total The count of the message lookups by the language and result (hit or miss).
+# TYPE i18n_lookups_total counter
+i18n_lookups_total{language="en",result="hit"} 1
+i18n_lookups_total{language="en",result="miss"} 1
+i18n_lookups_total{language="ja",result="miss"} 1
+i18n_lookups_total{language="other",result="miss"} 1
+# HELP i18n_mutations_total The count of the message changes by the type (added, changed or removed).
+# TYPE i18n_mutations_total counter
+i18n_mutations_total{type="added"} 1
+i18n_mutations_total{type="changed"} 1
+# HELP i18n_reloads_total The count of the catalog loads by the result (success or failure).
+# TYPE i18n_reloads_total counter
+i18n_reloads_total{result="failure"} 1
+i18n_reloads_total{result="success"} 1
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(want),
+		"i18n_catalog_keys", "i18n_lookups_total", "i18n_mutations_total", "i18n_reloads_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(m.requests); count != 1 {
+		t.Errorf("Get %d request series, want: 1", count)
+	}
+
+	cancel()
+	instance.Lookup("en", "user", "text", "login")
+	if value := testutil.ToFloat64(m.lookups.WithLabelValues("en", ResultHit)); value != 1 {
+		t.Errorf("Get %v en hits after cancel, want: 1", value)
+	}
+	if count, _ := testutil.GatherAndCount(registry, "i18n_catalog_keys"); count != 0 {
+		t.Errorf("Get %d catalog key series after cancel, want: 0", count)
+	}
+}
@@ -0,0 +1,205 @@
+package negotiate
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Wildcard is the language range which matches any language.
+const Wildcard = "*"
+
+// LanguageRange is one item of the Accept-Language header, the Tag is normalized to lower case with '-' separator.
+type LanguageRange struct {
+	Tag     string  `yaml:"tag" json:"tag"`
+	Quality float64 `yaml:"quality" json:"quality"`
+}
+
+// ParseAcceptLanguage parse the Accept-Language header like 'zh-CN,zh;q=0.9,en;q=0.8'. The result is sorted by quality
+// from high to low, the ranges with same quality keep the order in header. The invalid items are dropped.
+func ParseAcceptLanguage(header string) []LanguageRange {
+	var res []LanguageRange
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		tag := Normalize(parts[0])
+		if len(tag) == 0 {
+			continue
+		}
+
+		quality, valid := 1.0, true
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") && !strings.HasPrefix(param, "Q=") {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(param[2:]), 64)
+			if err != nil || value < 0 || value > 1 {
+				valid = false
+				break
+			}
+			quality = value
+		}
+		if valid {
+			res = append(res, LanguageRange{Tag: tag, Quality: quality})
+		}
+	}
+
+	sort.SliceStable(res, func(a, b int) bool {
+		return res[a].Quality > res[b].Quality
+	})
+	return res
+}
+
+// Normalize return the lower case language tag with '-' separator, like 'zh_CN' to 'zh-cn'.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// Negotiator chooses the best language from the Available languages for an Accept-Language header.
+//
+// The Fallbacks is the fallback chain of a language, like 'zh-tw' to ['zh-hant', 'zh']. The Default is the language
+// used when nothing matched.
+type Negotiator struct {
+	Available []string
+	Fallbacks map[string][]string
+	Default   string
+}
+
+// New return a *Negotiator. The fallbacks and the default language are normalized, but the available languages are
+// kept, so the result of the Negotiator can be used to find the message directly.
+func New(available []string, fallbacks map[string][]string, defaultLanguage string) *Negotiator {
+	n := &Negotiator{
+		Available: available,
+		Fallbacks: map[string][]string{},
+		Default:   Normalize(defaultLanguage),
+	}
+	for language, chain := range fallbacks {
+		for _, item := range chain {
+			n.Fallbacks[Normalize(language)] = append(n.Fallbacks[Normalize(language)], Normalize(item))
+		}
+	}
+	return n
+}
+
+// ForI18n return a *Negotiator whose Available languages are the languages present in the instance.
+func ForI18n(i *provider.I18n, fallbacks map[string][]string, defaultLanguage string) *Negotiator {
+	return New(i.Languages(), fallbacks, defaultLanguage)
+}
+
+// Cache keeps the *Negotiator of an instance like ForI18n, it is rebuilt only after the instance changed, so the
+// languages of the instance are not walked for every header. The Cache is thread-safe.
+type Cache struct {
+	i18n            *provider.I18n
+	fallbacks       map[string][]string
+	defaultLanguage string
+
+	lock       sync.Mutex
+	revision   uint64
+	negotiator *Negotiator
+}
+
+// NewCache return a *Cache of the instance, the arguments are the same as ForI18n.
+func NewCache(i *provider.I18n, fallbacks map[string][]string, defaultLanguage string) *Cache {
+	return &Cache{i18n: i, fallbacks: fallbacks, defaultLanguage: defaultLanguage}
+}
+
+// Negotiator return the *Negotiator of the current languages of the instance, it should not be changed.
+func (c *Cache) Negotiator() *Negotiator {
+	// the revision is read before the languages, a change between them only makes the next call rebuild again.
+	revision := c.i18n.Revision()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.negotiator == nil || c.revision != revision {
+		c.negotiator = ForI18n(c.i18n, c.fallbacks, c.defaultLanguage)
+		c.revision = revision
+	}
+	return c.negotiator
+}
+
+// Match return the best language for the header. If nothing matched, return the Default and false.
+func (n *Negotiator) Match(header string) (string, bool) {
+	candidates, matched := n.candidates(header)
+	if matched == 0 {
+		return n.Default, false
+	}
+	return candidates[0], true
+}
+
+// Candidates return all acceptable available languages in preference order, the Default is the last one. The caller
+// can try the candidates one by one until the message is found.
+//
+// For every language range of the header by quality order, the candidates are:
+//   - the available language equals the range.
+//   - the fallback chain of the range.
+//   - the range truncated from the end, like 'zh-hant-tw' to 'zh-hant' and 'zh', and their fallback chains.
+//   - all available languages for the wildcard range '*'.
+//
+// The language whose quality is 0 is never a candidate.
+func (n *Negotiator) Candidates(header string) []string {
+	res, _ := n.candidates(header)
+	return res
+}
+
+// candidates return the candidates and the count of candidates matched by the header.
+func (n *Negotiator) candidates(header string) ([]string, int) {
+	// the normalized language to the available language.
+	available := map[string]string{}
+	for _, language := range n.Available {
+		available[Normalize(language)] = language
+	}
+
+	ranges := ParseAcceptLanguage(header)
+	excluded := map[string]bool{}
+	for _, item := range ranges {
+		if item.Quality == 0 {
+			excluded[item.Tag] = true
+		}
+	}
+
+	var res []string
+	added := map[string]bool{}
+	add := func(language string) {
+		language = Normalize(language)
+		if original, ok := available[language]; ok && !excluded[language] && !added[language] {
+			added[language] = true
+			res = append(res, original)
+		}
+	}
+
+	for _, item := range ranges {
+		if item.Quality == 0 {
+			continue
+		}
+		if item.Tag == Wildcard {
+			add(n.Default)
+			for _, language := range n.Available {
+				add(language)
+			}
+			continue
+		}
+
+		for tag := item.Tag; len(tag) != 0; tag = truncate(tag) {
+			add(tag)
+			for _, fallback := range n.Fallbacks[tag] {
+				add(fallback)
+			}
+		}
+	}
+	matched := len(res)
+	add(n.Default)
+
+	return res, matched
+}
+
+// truncate drop the last subtag of the language tag, like 'zh-hant-tw' to 'zh-hant'. It returns empty for a primary
+// language tag.
+func truncate(tag string) string {
+	index := strings.LastIndex(tag, "-")
+	if index < 0 {
+		return ""
+	}
+	return tag[:index]
+}
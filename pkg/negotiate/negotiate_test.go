@@ -0,0 +1,60 @@
+package negotiate
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	ranges := ParseAcceptLanguage("en;q=0.8, zh_CN, ja;q=0.9, fr;q=abc, *;q=0.1")
+	var tags []string
+	for _, item := range ranges {
+		tags = append(tags, item.Tag)
+	}
+	if strings.Join(tags, ",") != "zh-cn,ja,en,*" {
+		t.Errorf("Get ranges: %v", ranges)
+	}
+}
+
+func TestNegotiator(t *testing.T) {
+	n := New([]string{"en", "zh", "zh-Hant", "ja"}, map[string][]string{"zh-TW": {"zh-hant"}}, "en")
+	cases := []struct {
+		header  string
+		want    string
+		matched bool
+	}{
+		{"ja", "ja", true},
+		{"zh-CN,en;q=0.5", "zh", true},
+		{"zh-TW", "zh-Hant", true},
+		{"fr, ja;q=0.3", "ja", true},
+		{"fr", "en", false},
+		{"", "en", false},
+		{"fr, *;q=0.1", "en", true},
+		{"en;q=0, *", "zh", true},
+	}
+	for _, item := range cases {
+		if language, matched := n.Match(item.header); language != item.want || matched != item.matched {
+			t.Errorf("Header [%s] get: [%s], [%v], want: [%s], [%v]", item.header, language, matched, item.want, item.matched)
+		}
+	}
+
+	if candidates := n.Candidates("zh-TW, ja;q=0.5"); strings.Join(candidates, ",") != "zh-Hant,zh,ja,en" {
+		t.Errorf("Get candidates: %v", candidates)
+	}
+}
+
+func TestCache(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.Pusher("user", "login")(provider.EnglishLn, "Login")
+	cache := NewCache(instance, nil, "en")
+
+	n := cache.Negotiator()
+	if cache.Negotiator() != n {
+		t.Error("The Negotiator should be cached when the instance is not changed. But not.")
+	}
+	instance.Pusher("user", "login")(provider.JapaneseLn, "ログイン")
+	if language, _ := cache.Negotiator().Match("ja"); language != "ja" {
+		t.Errorf("Get: [%s] after the language is added, want: [ja]", language)
+	}
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the kind of Change between two I18n instances.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"   // The MessageValue only exists in the new instance.
+	ChangeRemoved ChangeType = "removed" // The MessageValue only exists in the old instance.
+	ChangeChanged ChangeType = "changed" // The MessageValue exists in both instances, but the value is different.
+)
+
+// Change describes the difference of one language value at specify scopes.
+//
+// For ChangeAdded, the OldValue is empty. For ChangeRemoved, the NewValue is empty.
+type Change struct {
+	Type     ChangeType `yaml:"type" json:"type"`
+	Scopes   []string   `yaml:"scopes" json:"scopes"`
+	Language string     `yaml:"language" json:"language"`
+	OldValue string     `yaml:"old_value,omitempty" json:"old_value,omitempty"`
+	NewValue string     `yaml:"new_value,omitempty" json:"new_value,omitempty"`
+}
+
+// Patch is an ordered list of Change. It can be applied to another I18n instance, and it can be serialized as json or
+// yaml for review.
+//
+// The Changes are sorted by scopes and language, so the same two instances always produce the same Patch.
+type Patch struct {
+	Changes []Change `yaml:"changes" json:"changes"`
+}
+
+// Conflict is a Change which can't be applied to a target instance, because the target value is not the base value of
+// the Change. The Current is the value in the target, and Exists is false when the target hasn't the value.
+type Conflict struct {
+	Change  Change `yaml:"change" json:"change"`
+	Current string `yaml:"current,omitempty" json:"current,omitempty"`
+	Exists  bool   `yaml:"exists" json:"exists"`
+}
+
+// ConflictError is returned by Patch.Apply when the target instance has drifted from the base of the Patch.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	scopes := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		scopes = append(scopes, fmt.Sprintf("%s[%s]", strings.Join(conflict.Change.Scopes, "."), conflict.Change.Language))
+	}
+	return fmt.Sprintf("patch conflict at %d value(s): %s", len(e.Conflicts), strings.Join(scopes, ", "))
+}
+
+// Diff return a Patch which describes how to change current instance to b. If i or b is nil, it will be treated as an
+// empty instance.
+//
+// i.Diff(b).Apply(i) will make i.IsMessageEquals(b) return true.
+func (i *I18n) Diff(b *I18n) *Patch {
+	return diffRecords(i.records(), b.records())
+}
+
+// diffRecords return the Patch which changes the oldRecords to newRecords.
+func diffRecords(oldRecords, newRecords map[string]record) *Patch {
+	patch := &Patch{Changes: []Change{}}
+	for key, oldRecord := range oldRecords {
+		newRecord, ok := newRecords[key]
+		if !ok {
+			patch.Changes = append(patch.Changes, Change{
+				Type:     ChangeRemoved,
+				Scopes:   oldRecord.scopes,
+				Language: oldRecord.language,
+				OldValue: oldRecord.value,
+			})
+			continue
+		}
+		if newRecord.value != oldRecord.value {
+			patch.Changes = append(patch.Changes, Change{
+				Type:     ChangeChanged,
+				Scopes:   oldRecord.scopes,
+				Language: oldRecord.language,
+				OldValue: oldRecord.value,
+				NewValue: newRecord.value,
+			})
+		}
+	}
+	for key, newRecord := range newRecords {
+		if _, ok := oldRecords[key]; !ok {
+			patch.Changes = append(patch.Changes, Change{
+				Type:     ChangeAdded,
+				Scopes:   newRecord.scopes,
+				Language: newRecord.language,
+				NewValue: newRecord.value,
+			})
+		}
+	}
+
+	patch.sort()
+	return patch
+}
+
+// IsEmpty return true when the Patch has no Change.
+func (p *Patch) IsEmpty() bool {
+	return p == nil || len(p.Changes) == 0
+}
+
+// Reverse return a new Patch which undo current Patch.
+func (p *Patch) Reverse() *Patch {
+	res := &Patch{Changes: make([]Change, 0, len(p.Changes))}
+	for _, change := range p.Changes {
+		reversed := Change{
+			Type:     change.Type,
+			Scopes:   change.Scopes,
+			Language: change.Language,
+			OldValue: change.NewValue,
+			NewValue: change.OldValue,
+		}
+		switch change.Type {
+		case ChangeAdded:
+			reversed.Type = ChangeRemoved
+		case ChangeRemoved:
+			reversed.Type = ChangeAdded
+		}
+		res.Changes = append(res.Changes, reversed)
+	}
+	return res
+}
+
+// Conflicts return all Change which can't be applied to target. A Change can be applied when the target value equals
+// the Change.OldValue (added value must not exist), or the target already has the Change.NewValue. The LookupHook of
+// the target is not invoked.
+func (p *Patch) Conflicts(target *I18n) []Conflict {
+	target.lock.RLock()
+	defer target.lock.RUnlock()
+	return p.conflicts(target.Values)
+}
+
+// conflicts is Conflicts without lock.
+func (p *Patch) conflicts(values *Namespace) []Conflict {
+	var res []Conflict
+	for _, change := range p.Changes {
+		if conflict, ok := changeConflict(values, change); ok {
+			res = append(res, conflict)
+		}
+	}
+	return res
+}
+
+// changeConflict return the Conflict of the Change and true if the Change can't be applied to the values.
+func changeConflict(values *Namespace, change Change) (Conflict, bool) {
+	current, ok := values.Message(change.Language, change.Scopes...)
+
+	// The target already has the result of the Change.
+	if (change.Type == ChangeRemoved && !ok) || (change.Type != ChangeRemoved && ok && current == change.NewValue) {
+		return Conflict{}, false
+	}
+
+	// The target still has the base value of the Change.
+	if (change.Type == ChangeAdded && !ok) || (change.Type != ChangeAdded && ok && current == change.OldValue) {
+		return Conflict{}, false
+	}
+	return Conflict{Change: change, Current: current, Exists: ok}, true
+}
+
+// Apply will apply all Change to the target. If the target has drifted from the base of the Patch, Apply changes
+// nothing and return a *ConflictError which contains all conflicts. The conflicts are checked and the Changes are
+// applied in one write lock, so a concurrent change of the target is never overwritten.
+func (p *Patch) Apply(target *I18n) error {
+	target.lock.Lock()
+	if conflicts := p.conflicts(target.Values); len(conflicts) != 0 {
+		target.lock.Unlock()
+		return &ConflictError{Conflicts: conflicts}
+	}
+	target.unlockAndNotify(target.pushChanges(p.Changes)...)
+	return nil
+}
+
+// ApplyPartial like Apply, but the Changes without conflict are applied, and the conflicts are returned. The conflicts
+// are checked and the Changes are applied in one write lock, so a concurrent change of the target is never
+// overwritten.
+func (p *Patch) ApplyPartial(target *I18n) []Conflict {
+	target.lock.Lock()
+	var conflicts []Conflict
+	changes := make([]Change, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		if conflict, ok := changeConflict(target.Values, change); ok {
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	target.unlockAndNotify(target.pushChanges(changes)...)
+	return conflicts
+}
+
+// ApplyForce will apply all Change to the target without conflict detection.
+func (p *Patch) ApplyForce(target *I18n) {
+	for _, change := range p.Changes {
+		target.PushMessageByString(change.Language, change.NewValue, change.Scopes...)
+	}
+}
+
+func (p *Patch) sort() {
+	sort.SliceStable(p.Changes, func(a, b int) bool {
+		return lessRecord(p.Changes[a].Scopes, p.Changes[a].Language, p.Changes[b].Scopes, p.Changes[b].Language)
+	})
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+type record struct {
+	scopes   []string
+	language string
+	value    string
+}
+
+// recordKey return an unique key of specify language and scopes.
+func recordKey(language string, scopes ...string) string {
+	return strings.Join(scopes, "\x00") + "\x01" + language
+}
+
+// records return all MessageValue of current instance, the key is from recordKey.
+func (i *I18n) records() map[string]record {
+	if i == nil || i.Values == nil {
+		return map[string]record{}
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return namespaceRecords(i.Values)
+}
+
+// namespaceRecords return all MessageValue of the namespace without lock, the key is from recordKey.
+func namespaceRecords(namespace *Namespace) map[string]record {
+	res := map[string]record{}
+	namespace.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		scopes := make([]string, len(flags))
+		copy(scopes, flags)
+		res[recordKey(languageValue, scopes...)] = record{scopes: scopes, language: languageValue, value: messageValue}
+	})
+	return res
+}
+
+func lessRecord(aScopes []string, aLanguage string, bScopes []string, bLanguage string) bool {
+	for index := 0; index < len(aScopes) && index < len(bScopes); index++ {
+		if aScopes[index] != bScopes[index] {
+			return aScopes[index] < bScopes[index]
+		}
+	}
+	if len(aScopes) != len(bScopes) {
+		return len(aScopes) < len(bScopes)
+	}
+	return aLanguage < bLanguage
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	Init()
+	base := BaseI18nValue.Clone()
+	changed := BaseI18nValue.Clone()
+	changed.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+	changed.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	changed.PushMessage(ChineseLn, "", "system", "error", "unknown")
+
+	patch := base.Diff(changed)
+	if len(patch.Changes) != 3 {
+		t.Fatalf("Get %d changes, want 3: %v", len(patch.Changes), patch.Changes)
+	}
+	want := []ChangeType{ChangeRemoved, ChangeChanged, ChangeAdded}
+	for index, change := range patch.Changes {
+		if change.Type != want[index] {
+			t.Errorf("Get change type: [%s], want: [%s]", change.Type, want[index])
+		}
+	}
+
+	value, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Patch{}
+	if err = json.Unmarshal(value, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err = decoded.Apply(base); err != nil {
+		t.Fatal(err)
+	}
+	if !base.IsMessageEquals(changed) {
+		t.Error("After apply, base should equals changed. But not.")
+	}
+	if !base.Diff(changed).IsEmpty() {
+		t.Error("After apply, diff should be empty. But not.")
+	}
+
+	patch.Reverse().ApplyForce(base)
+	if !base.IsMessageEquals(BaseI18nValue) {
+		t.Error("After apply reversed patch, base should equals BaseI18nValue. But not.")
+	}
+}
+
+func TestPatchConflict(t *testing.T) {
+	Init()
+	changed := BaseI18nValue.Clone()
+	changed.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	patch := BaseI18nValue.Diff(changed)
+
+	drifted := BaseI18nValue.Clone()
+	drifted.PushMessage(EnglishLn, "something wrong", "system", "text", "error")
+
+	err := patch.Apply(drifted)
+	conflictErr := &ConflictError{}
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 {
+		t.Fatalf("Get error: [%v], want one conflict", err)
+	}
+	if value, _ := drifted.Message(EnglishLn, "system", "text", "error"); value != "something wrong" {
+		t.Errorf("Conflict patch should change nothing, but get: [%s]", value)
+	}
+}
+
+func TestPatchApplyPartial(t *testing.T) {
+	Init()
+	changed := BaseI18nValue.Clone()
+	changed.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	changed.PushMessage(EnglishLn, "a test", "user", "text", "test")
+	patch := BaseI18nValue.Diff(changed)
+
+	drifted := BaseI18nValue.Clone()
+	drifted.PushMessage(EnglishLn, "something wrong", "system", "text", "error")
+
+	conflicts := patch.ApplyPartial(drifted)
+	if len(conflicts) != 1 || conflicts[0].Current != "something wrong" {
+		t.Fatalf("Get conflicts: %v, want the conflict of the drifted value", conflicts)
+	}
+	if value, _ := drifted.Message(EnglishLn, "system", "text", "error"); value != "something wrong" {
+		t.Errorf("The conflict should change nothing, but get: [%s]", value)
+	}
+	if value, _ := drifted.Message(EnglishLn, "user", "text", "test"); value != "a test" {
+		t.Errorf("The change without conflict should be applied, but get: [%s]", value)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	ja := JapaneseLn.Lower(instance.Standard)
+
+	if change, ok := instance.Swap(ja, "テスト", "user", "text", "test"); !ok || change.Type != ChangeAdded {
+		t.Errorf("Swap new value get: %v, %v, want added", change, ok)
+	}
+	if change, ok := instance.Swap(ja, "試験", "user", "text", "test"); !ok || change.Type != ChangeChanged ||
+		change.OldValue != "テスト" || change.NewValue != "試験" {
+		t.Errorf("Swap value get: %v, %v, want changed", change, ok)
+	}
+	if _, ok := instance.Swap(ja, "試験", "user", "text", "test"); ok {
+		t.Error("Swap same value should return false")
+	}
+	if change, ok := instance.Swap(ja, "", "user", "text", "test"); !ok || change.Type != ChangeRemoved {
+		t.Errorf("Swap empty value get: %v, %v, want removed", change, ok)
+	}
+}
+
+func TestPatchApplyConcurrent(t *testing.T) {
+	Init()
+	instance := NewI18n(Custom)
+	instance.PushMessageByString("en", "0", "counter")
+	instance.SetLookupHook(func(ln string, found bool, scopes ...string) {
+		t.Error("Apply should not invoke the LookupHook")
+	})
+
+	// every goroutine increases the counter by patches, a lost update means a conflict is not detected.
+	const workers, times = 8, 500
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for count := 0; count < times; {
+				value := instance.records()[recordKey("en", "counter")].value
+				number, _ := strconv.Atoi(value)
+				patch := &Patch{Changes: []Change{{Type: ChangeChanged, Scopes: []string{"counter"}, Language: "en",
+					OldValue: value, NewValue: strconv.Itoa(number + 1)}}}
+				if patch.Apply(instance) == nil {
+					count++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if value := instance.records()[recordKey("en", "counter")].value; value != strconv.Itoa(workers*times) {
+		t.Errorf("Get counter: [%s], want: [%d]", value, workers*times)
+	}
+}
+
+func TestSwapIfAbsent(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	ja := JapaneseLn.Lower(instance.Standard)
+
+	if change, ok := instance.SwapIfAbsent(ja, "テスト", "user", "text", "test"); !ok || change.Type != ChangeAdded {
+		t.Errorf("SwapIfAbsent new value get: %v, %v, want added", change, ok)
+	}
+	revision := instance.Revision()
+	if change, ok := instance.SwapIfAbsent(ja, "試験", "user", "text", "test"); ok || change.OldValue != "テスト" {
+		t.Errorf("SwapIfAbsent existing value get: %v, %v, want the existing value", change, ok)
+	}
+	if value, _ := instance.Lookup(ja, "user", "text", "test"); value != "テスト" || instance.Revision() != revision {
+		t.Errorf("SwapIfAbsent existing value should change nothing, but get: [%s]", value)
+	}
+}
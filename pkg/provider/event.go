@@ -0,0 +1,129 @@
+package provider
+
+// ChangeEvent is a Change which has been applied to an I18n instance, the Revision is the revision of the instance
+// after the Change.
+type ChangeEvent struct {
+	Change   `yaml:",inline"`
+	Revision uint64 `yaml:"revision" json:"revision"`
+}
+
+// Subscribe add a listener which is invoked after every effective change of current instance, and return a func to
+// remove the listener.
+//
+// The listeners are invoked one by one in order of revision, after the write lock is released, so a listener can read
+// current instance. The listeners are invoked by the goroutine which made the change, or by another changing goroutine
+// at the same time, so a listener should return quickly.
+func (i *I18n) Subscribe(f func(event ChangeEvent)) (cancel func()) {
+	i.eventsLock.Lock()
+	defer i.eventsLock.Unlock()
+
+	if i.listeners == nil {
+		i.listeners = map[uint64]func(ChangeEvent){}
+	}
+	i.listenerID++
+	id := i.listenerID
+	i.listeners[id] = f
+
+	return func() {
+		i.eventsLock.Lock()
+		defer i.eventsLock.Unlock()
+		delete(i.listeners, id)
+	}
+}
+
+// Replace replace all MessageValue of current instance by the MessageValue of b, and return the Patch which has been
+// applied. Different from Patch.ApplyForce, the Patch is applied in one write lock, no one can see the half-replaced
+// instance. Every Change increases the revision, and the Standard is not changed.
+func (i *I18n) Replace(b *I18n) *Patch {
+	if i == b {
+		return &Patch{Changes: []Change{}}
+	}
+	newRecords := b.records()
+
+	i.lock.Lock()
+	patch := diffRecords(namespaceRecords(i.Values), newRecords)
+	i.unlockAndNotify(i.pushChanges(patch.Changes)...)
+
+	return patch
+}
+
+// pushChanges push the NewValue of the changes, and return the events of the effective ones. Every effective Change
+// increases the revision, the OldValue of an event is the value before it. It should be invoked with the write lock.
+func (i *I18n) pushChanges(changes []Change) []ChangeEvent {
+	events := make([]ChangeEvent, 0, len(changes))
+	for _, change := range changes {
+		oldValue, ok := i.Values.Message(change.Language, change.Scopes...)
+		if (ok && oldValue == change.NewValue) || (!ok && len(change.NewValue) == 0) {
+			continue
+		}
+		i.Values.PushMessage(change.Language, change.NewValue, change.Scopes...)
+		i.modified()
+
+		applied := Change{Type: ChangeChanged, Scopes: change.Scopes, Language: change.Language, OldValue: oldValue,
+			NewValue: change.NewValue}
+		switch {
+		case !ok:
+			applied.Type = ChangeAdded
+		case len(change.NewValue) == 0:
+			applied.Type = ChangeRemoved
+		}
+		events = append(events, ChangeEvent{Change: applied, Revision: i.revision})
+	}
+	return events
+}
+
+// unlockAndNotify release the write lock, and invoke the listeners with the events. It should be invoked with the
+// write lock.
+func (i *I18n) unlockAndNotify(events ...ChangeEvent) {
+	// Queue the events before the write lock is released, so the events are queued in order of revision.
+	i.eventsLock.Lock()
+	if len(i.listeners) != 0 {
+		i.pending = append(i.pending, events...)
+	}
+	i.eventsLock.Unlock()
+	i.lock.Unlock()
+
+	i.dispatch()
+}
+
+// dispatch invoke the listeners with the pending events. Only one goroutine dispatches at the same time, the events
+// queued by others during the dispatching are dispatched by it too.
+func (i *I18n) dispatch() {
+	i.eventsLock.Lock()
+	defer i.eventsLock.Unlock()
+	if i.dispatching {
+		return
+	}
+
+	i.dispatching = true
+	for len(i.pending) != 0 {
+		events := i.pending
+		i.pending = nil
+		listeners := make([]func(ChangeEvent), 0, len(i.listeners))
+		for _, listener := range i.listeners {
+			listeners = append(listeners, listener)
+		}
+
+		i.eventsLock.Unlock()
+		i.invoke(listeners, events)
+		i.eventsLock.Lock()
+	}
+	i.dispatching = false
+}
+
+// invoke the listeners without the eventsLock. If a listener panics, the dispatching is reset before the panic goes on.
+func (i *I18n) invoke(listeners []func(ChangeEvent), events []ChangeEvent) {
+	defer func() {
+		if err := recover(); err != nil {
+			i.eventsLock.Lock()
+			i.dispatching = false
+			i.eventsLock.Unlock()
+			panic(err)
+		}
+	}()
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener(event)
+		}
+	}
+}
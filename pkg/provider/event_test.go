@@ -0,0 +1,57 @@
+package provider
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	ja := JapaneseLn.Lower(instance.Standard)
+
+	var events []ChangeEvent
+	cancel := instance.Subscribe(func(event ChangeEvent) {
+		// The listener can read the instance.
+		if instance.Revision() != event.Revision {
+			t.Errorf("Revision in listener: %d, want: %d", instance.Revision(), event.Revision)
+		}
+		events = append(events, event)
+	})
+
+	instance.PushMessageByString(ja, "テスト", "user", "text", "test")
+	instance.PushMessageByString(ja, "テスト", "user", "text", "test")
+	instance.PushMessageByString(ja, "", "user", "text", "test")
+	if len(events) != 2 || events[0].Type != ChangeAdded || events[1].Type != ChangeRemoved ||
+		events[1].Revision != events[0].Revision+1 {
+		t.Errorf("Get events: %v, want added and removed", events)
+	}
+
+	cancel()
+	instance.PushMessageByString(ja, "テスト", "user", "text", "test")
+	if len(events) != 2 {
+		t.Errorf("Canceled listener should not be invoked, get %d events", len(events))
+	}
+}
+
+func TestReplace(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	target := BaseI18nValue.Clone()
+	target.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+	target.PushMessage(EnglishLn, "", "user", "text", "test")
+
+	var events []ChangeEvent
+	instance.Subscribe(func(event ChangeEvent) {
+		events = append(events, event)
+	})
+
+	revision := instance.Revision()
+	patch := instance.Replace(target)
+	if len(patch.Changes) != 2 || !instance.IsMessageEquals(target) {
+		t.Errorf("Replace get patch: %v, want 2 changes and equal instances", patch.Changes)
+	}
+	if len(events) != 2 || instance.Revision() != revision+2 {
+		t.Errorf("Replace get %d events and revision %d, want 2 and %d", len(events), instance.Revision(), revision+2)
+	}
+	if !instance.Replace(target).IsEmpty() {
+		t.Error("Replace by equal instance should change nothing")
+	}
+}
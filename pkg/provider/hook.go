@@ -0,0 +1,36 @@
+package provider
+
+// LookupHook is invoked after every lookup of I18n with the result, so the hits and misses of the languages can be
+// counted. The hook is in the lookup path, it should be fast, and it should not change the I18n instance.
+//
+// Every I18n.Lookup invokes the hook, so do I18n.Message and I18n.MessageByString. The found is false if the value is
+// not in the instance, even if the MissingHandler returns a fallback value. The fallback lookups of
+// FallbackLanguageHandler invoke the hook too.
+type LookupHook func(ln string, found bool, scopes ...string)
+
+// SetLookupHook will set the LookupHook of current instance, a nil hook will disable it. If you need more than one
+// hook, see ChainLookupHooks.
+func (i *I18n) SetLookupHook(hook LookupHook) *I18n {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.lookupHook = hook
+	return i
+}
+
+// LookupHook return the LookupHook of current instance, it may be nil.
+func (i *I18n) LookupHook() LookupHook {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.lookupHook
+}
+
+// ChainLookupHooks return a LookupHook which invokes all hooks in order.
+func ChainLookupHooks(hooks ...LookupHook) LookupHook {
+	return func(ln string, found bool, scopes ...string) {
+		for _, hook := range hooks {
+			if hook != nil {
+				hook(ln, found, scopes...)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package provider
+
+import "testing"
+
+func TestLookupHook(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+
+	hits, misses := map[string]int{}, map[string]int{}
+	count := func(ln string, found bool, scopes ...string) {
+		if found {
+			hits[ln]++
+		} else {
+			misses[ln]++
+		}
+	}
+	instance.SetLookupHook(ChainLookupHooks(count, nil))
+	instance.SetMissingHandler(FallbackLanguageHandler(instance, EnglishLn.Lower(instance.Standard)))
+
+	instance.Message(EnglishLn, "user", "text", "test")
+	instance.Message(JapaneseLn, "user", "text", "test")
+	instance.Lookup("ja", "user", "text", "none")
+
+	if hits["en"] != 2 || misses["ja"] != 2 {
+		t.Errorf("Get hits: %v, misses: %v, want en hit 2 (with the fallback) and ja miss 2", hits, misses)
+	}
+
+	instance.SetLookupHook(nil)
+	instance.Lookup("en", "user", "text", "test")
+	if hits["en"] != 2 {
+		t.Errorf("Get en hits: %d after the hook is removed, want: 2", hits["en"])
+	}
+}
+
+func TestLookupHookConcurrent(t *testing.T) {
+	instance := NewI18n(ISO6391)
+	instance.PushMessageByString("en", "Login", "user", "login")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for index := 0; index < 100; index++ {
+			instance.SetLookupHook(func(ln string, found bool, scopes ...string) {})
+			instance.SetMissingHandler(func(ln string, scopes ...string) (string, bool) { return "", false })
+			instance.SetLookupHook(nil)
+			instance.SetMissingHandler(nil)
+		}
+	}()
+	// the hooks are changed during the lookups, the race detector reports the unguarded fields.
+	for index := 0; index < 100; index++ {
+		instance.MessageByString("ja", "user", "login")
+	}
+	<-done
+}
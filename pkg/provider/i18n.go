@@ -1,5 +1,11 @@
 package provider
 
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
 func NewI18n(standard string) *I18n {
 	if len(standard) == 0 {
 		standard = Custom
@@ -16,11 +22,32 @@ func NewI18n(standard string) *I18n {
 // You can change Standard, but the old MessageValue will not be updated. If you want to use different Standard, please
 // use different I18n instance to do it.
 //
-// I18n is not thread-safe, the I18n MessageValue should build at application bootstrap age. After bootstrap, the I18n
-// info should not change(the tools of I18n except).
+// The methods of I18n are thread-safe, but the I18n.Values is not: change the Values directly will not be seen by the
+// lock and the Revision. Prefer to build the I18n MessageValue at application bootstrap age, and change it by the
+// methods of I18n only after bootstrap.
+//
+// Every effective change by the methods of I18n increases the Revision, see Revision and Checksum.
+//
+// When a MessageValue not found, the MissingHandler of I18n will be invoked, see SetMissingHandler. The lookups can be
+// counted by SetLookupHook, and the changes can be watched by Subscribe.
 type I18n struct {
 	Values   *Namespace `yaml:"values" json:"values"`
 	Standard string     `yaml:"standard" json:"standard"`
+
+	lock           sync.RWMutex
+	revision       uint64
+	modifiedAt     time.Time
+	checksum       string
+	checksumValid  bool
+	missingHandler MissingHandler
+	lookupHook     LookupHook
+
+	// eventsLock protects the listeners and the pending events, see Subscribe.
+	eventsLock  sync.Mutex
+	listeners   map[uint64]func(ChangeEvent)
+	listenerID  uint64
+	pending     []ChangeEvent
+	dispatching bool
 }
 
 //--------------------------------------------------
@@ -72,14 +99,49 @@ func (mb *messageStringBuilder) Push(key, message string) *messageStringBuilder
 // If specify MessageValue already haven value, the new MessageValue will cover it directly. Specify if the input
 // MessageValue value is emtpy, the MessageValue will be deleted. See Message.PushMessage.
 //
-// Note that the PushMessage not thread-safe.
+// If the MessageValue changed, the Revision of I18n will be increased.
 func (i *I18n) PushMessage(ln LanguageKey, messageValue string, scopes ...string) {
 	i.PushMessageByString(ln.Lower(i.Standard), messageValue, scopes...)
 }
 
 // PushMessageByString like PushMessage, but it receives the string as language key.
 func (i *I18n) PushMessageByString(ln string, message string, scopes ...string) {
-	i.Values.PushMessage(ln, message, scopes...)
+	i.Swap(ln, message, scopes...)
+}
+
+// Swap like PushMessageByString, but it returns the Change which has been applied. If the value is not changed, it
+// returns false.
+func (i *I18n) Swap(ln string, message string, scopes ...string) (Change, bool) {
+	scopesCopy := make([]string, len(scopes))
+	copy(scopesCopy, scopes)
+
+	i.lock.Lock()
+	events := i.pushChanges([]Change{{Scopes: scopesCopy, Language: ln, NewValue: message}})
+	i.unlockAndNotify(events...)
+	if len(events) == 0 {
+		return Change{}, false
+	}
+	return events[0].Change, true
+}
+
+// SwapIfAbsent like Swap, but the value is only added when the language and scopes has no value, the check and the
+// change are in one write lock. If the value exists, nothing is changed and it returns false with the Change whose
+// OldValue is the existing value.
+func (i *I18n) SwapIfAbsent(ln string, message string, scopes ...string) (Change, bool) {
+	scopesCopy := make([]string, len(scopes))
+	copy(scopesCopy, scopes)
+
+	i.lock.Lock()
+	if current, ok := i.Values.Message(ln, scopes...); ok {
+		i.lock.Unlock()
+		return Change{Scopes: scopesCopy, Language: ln, OldValue: current}, false
+	}
+	events := i.pushChanges([]Change{{Scopes: scopesCopy, Language: ln, NewValue: message}})
+	i.unlockAndNotify(events...)
+	if len(events) == 0 {
+		return Change{}, false
+	}
+	return events[0].Change, true
 }
 
 // Message return the MessageValue of specify language and scopes. If value not found, return empty and false.
@@ -91,7 +153,27 @@ func (i *I18n) Message(ln LanguageKey, scopes ...string) (string, bool) {
 
 // MessageByString like Message, but it receives string as language key.
 func (i *I18n) MessageByString(ln string, scopes ...string) (string, bool) {
-	return i.Values.Message(ln, scopes...)
+	if value, ok := i.Lookup(ln, scopes...); ok {
+		return value, true
+	}
+	if handler := i.MissingHandler(); handler != nil {
+		return handler(ln, scopes...)
+	}
+	return "", false
+}
+
+// Lookup like MessageByString, but the MissingHandler will not be invoked. The LookupHook is invoked with the result.
+func (i *I18n) Lookup(ln string, scopes ...string) (string, bool) {
+	i.lock.RLock()
+	value, ok := i.Values.Message(ln, scopes...)
+	hook := i.lookupHook
+	i.lock.RUnlock()
+
+	// the hook is invoked without the lock, so it can read the instance.
+	if hook != nil {
+		hook(ln, ok, scopes...)
+	}
+	return value, ok
 }
 
 // Pusher help to quick build I18n MessageValue. It returns a func to add different language MessageValue to specify
@@ -141,16 +223,33 @@ func (i *I18n) MessageStringBuilder(scopes ...string) *messageStringBuilder {
 	}
 }
 
-// WalkRecord will for-each all MessageValue language-value.
+// WalkRecord will for-each all MessageValue language-value. The f should not change current instance.
 func (i *I18n) WalkRecord(f func(languageValue, messageValue string, flags ...string)) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
 	i.Values.WalkRecord(f)
 }
 
-// WalkMessage will for-each all MessageValue value.
+// WalkMessage will for-each all MessageValue value. The f should not change current instance.
 func (i *I18n) WalkMessage(f func(message map[string]string, flags ...string)) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
 	i.Values.WalkMessage(f)
 }
 
+// WalkMessageUnder like WalkMessage, but only for-each the MessageValue under specify scopes, and the flags are relative
+// to the scopes. It returns false if the scopes not found.
+func (i *I18n) WalkMessageUnder(scopes []string, f func(message map[string]string, flags ...string)) bool {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	namespace, ok := i.Values.Child(scopes...)
+	if !ok {
+		return false
+	}
+	namespace.WalkMessage(f)
+	return true
+}
+
 // IsMessageEquals return true when i.message == b.message. And if a == b == nil, return true
 // Else if (i == b && b != nil) || (a != nil || b == nil) return false.
 func (i *I18n) IsMessageEquals(b *I18n) bool {
@@ -175,7 +274,7 @@ func (i *I18n) IsSub(b *I18n) bool {
 	res := true
 	i.WalkRecord(func(languageValue, messageValue string, flags ...string) {
 		if res {
-			if currentValue, ok := b.MessageByString(languageValue, flags...); !ok || currentValue != messageValue {
+			if currentValue, ok := b.Lookup(languageValue, flags...); !ok || currentValue != messageValue {
 				res = false
 			}
 		}
@@ -185,11 +284,48 @@ func (i *I18n) IsSub(b *I18n) bool {
 
 // CoveredMessage will use b to cover current value.
 func (i *I18n) CoveredMessage(b *I18n) {
+	if i == b {
+		return
+	}
 	b.WalkRecord(func(languageValue, messageValue string, flags ...string) {
 		i.PushMessageByString(languageValue, messageValue, flags...)
 	})
 }
 
+// Languages return all language string values present in current instance, the result is sorted.
+func (i *I18n) Languages() []string {
+	found := map[string]bool{}
+	i.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		found[languageValue] = true
+	})
+
+	res := make([]string, 0, len(found))
+	for language := range found {
+		res = append(res, language)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Clone return a deep copy of current instance, the change of the copy will not affect current instance.
+func (i *I18n) Clone() *I18n {
+	res := NewI18n(i.Standard)
+	res.CoveredMessage(i)
+	return res
+}
+
+// CloneRevision like Clone, and return the Revision of the copy. They are read in one lock, so a concurrent change is
+// either in both of them or in none.
+func (i *I18n) CloneRevision() (*I18n, uint64) {
+	res := NewI18n(i.Standard)
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	i.Values.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		res.PushMessageByString(languageValue, messageValue, flags...)
+	})
+	return res, i.revision
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
 
 const scopeHeaderPrefix = "_"
@@ -231,6 +367,18 @@ func (namespace *Namespace) walkMessage(f func(message map[string]string, flags
 	}
 }
 
+// Child return the child Namespace of specify scopes, if not found return nil and false. The namespace itself is
+// returned when levelCodes is empty.
+func (namespace *Namespace) Child(levelCodes ...string) (*Namespace, bool) {
+	if len(levelCodes) == 0 {
+		return namespace, true
+	}
+	if value, ok := namespace.Children[levelCodes[0]]; ok {
+		return value.Child(levelCodes[1:]...)
+	}
+	return nil, false
+}
+
 // Pusher is a specify iterator implements. It used to register value.
 func (namespace *Namespace) Pusher(scopes ...string) func(string, string) {
 	return func(ln, messageValue string) {
@@ -11,6 +11,9 @@ const (
 	ISO6393  = "ISO 639-3"
 )
 
+// Standards is all the standards of the language keys.
+var Standards = []string{Custom, ISO6391, ISO6392B, ISO6392T, ISO6393}
+
 // NewLanguageKey return a *LanguageKey.
 func NewLanguageKey() *LanguageKey {
 	return &LanguageKey{}
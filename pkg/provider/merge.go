@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergePolicy decides how Merge resolves a value which both ours and theirs have changed in different ways.
+type MergePolicy string
+
+const (
+	MergeOurs            MergePolicy = "ours"    // Keep the value of ours.
+	MergeTheirs          MergePolicy = "theirs"  // Keep the value of theirs.
+	MergeFail            MergePolicy = "fail"    // Stop the merge and return a *MergeConflictError.
+	MergeConflictMarkers MergePolicy = "markers" // Keep both values in one value with git-style conflict markers.
+)
+
+const (
+	ConflictMarkerOurs   = "<<<<<<< ours"
+	ConflictMarkerSplit  = "======="
+	ConflictMarkerTheirs = ">>>>>>> theirs"
+)
+
+// MergeConflict is a value which both ours and theirs changed from the base in different ways. The XxxExists is false
+// when the value is not in the instance.
+type MergeConflict struct {
+	Scopes       []string `yaml:"scopes" json:"scopes"`
+	Language     string   `yaml:"language" json:"language"`
+	Base         string   `yaml:"base,omitempty" json:"base,omitempty"`
+	BaseExists   bool     `yaml:"base_exists" json:"base_exists"`
+	Ours         string   `yaml:"ours,omitempty" json:"ours,omitempty"`
+	OursExists   bool     `yaml:"ours_exists" json:"ours_exists"`
+	Theirs       string   `yaml:"theirs,omitempty" json:"theirs,omitempty"`
+	TheirsExists bool     `yaml:"theirs_exists" json:"theirs_exists"`
+}
+
+// MergeConflictError is returned by Merge with MergeFail policy when any conflict found.
+type MergeConflictError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	scopes := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		scopes = append(scopes, fmt.Sprintf("%s[%s]", strings.Join(conflict.Scopes, "."), conflict.Language))
+	}
+	return fmt.Sprintf("merge conflict at %d value(s): %s", len(e.Conflicts), strings.Join(scopes, ", "))
+}
+
+// Merge does a three-way merge of ours and theirs, both of them are changed from base. It returns a new instance, the
+// inputs will not be changed. The Standard of the result is from ours. A nil input will be treated as an empty
+// instance.
+//
+// For every value: if only one side changed it (or both changed it to the same value), the change is kept. Else the
+// value is a conflict, and it will be resolved by the policy. The conflicts are always returned in order of scopes and
+// language, even though they were resolved.
+//
+// With MergeFail policy, a *MergeConflictError will be returned when any conflict found, and the result is nil.
+func Merge(base, ours, theirs *I18n, policy MergePolicy) (*I18n, []MergeConflict, error) {
+	switch policy {
+	case MergeOurs, MergeTheirs, MergeFail, MergeConflictMarkers:
+	default:
+		return nil, nil, fmt.Errorf("unknown merge policy: %s", policy)
+	}
+
+	baseRecords, oursRecords, theirsRecords := base.records(), ours.records(), theirs.records()
+
+	standard := Custom
+	if ours != nil {
+		standard = ours.Standard
+	}
+	res := NewI18n(standard)
+
+	keys := map[string]record{}
+	for _, records := range []map[string]record{baseRecords, oursRecords, theirsRecords} {
+		for key, item := range records {
+			keys[key] = item
+		}
+	}
+
+	var conflicts []MergeConflict
+	for key, item := range keys {
+		baseRecord, baseOk := baseRecords[key]
+		oursRecord, oursOk := oursRecords[key]
+		theirsRecord, theirsOk := theirsRecords[key]
+
+		value, conflict := mergeValue(
+			baseRecord.value, baseOk,
+			oursRecord.value, oursOk,
+			theirsRecord.value, theirsOk,
+		)
+		if conflict {
+			conflicts = append(conflicts, MergeConflict{
+				Scopes:       item.scopes,
+				Language:     item.language,
+				Base:         baseRecord.value,
+				BaseExists:   baseOk,
+				Ours:         oursRecord.value,
+				OursExists:   oursOk,
+				Theirs:       theirsRecord.value,
+				TheirsExists: theirsOk,
+			})
+
+			switch policy {
+			case MergeOurs:
+				value = oursRecord.value
+			case MergeTheirs:
+				value = theirsRecord.value
+			case MergeConflictMarkers:
+				value = ConflictMarkerValue(oursRecord.value, theirsRecord.value)
+			}
+		}
+
+		res.PushMessageByString(item.language, value, item.scopes...)
+	}
+
+	sortMergeConflicts(conflicts)
+	if policy == MergeFail && len(conflicts) != 0 {
+		return nil, conflicts, &MergeConflictError{Conflicts: conflicts}
+	}
+
+	return res, conflicts, nil
+}
+
+// ConflictMarkerValue return a value which contains ours and theirs value with git-style conflict markers.
+func ConflictMarkerValue(ours, theirs string) string {
+	return strings.Join([]string{ConflictMarkerOurs, ours, ConflictMarkerSplit, theirs, ConflictMarkerTheirs}, "\n")
+}
+
+// HasConflictMarker return true if the value is built by ConflictMarkerValue.
+func HasConflictMarker(value string) bool {
+	return strings.HasPrefix(value, ConflictMarkerOurs+"\n") && strings.HasSuffix(value, "\n"+ConflictMarkerTheirs)
+}
+
+// mergeValue return the merged value. An empty value means the value should not exist. If ours and theirs changed
+// the value in different ways, return true.
+func mergeValue(base string, baseOk bool, ours string, oursOk bool, theirs string, theirsOk bool) (string, bool) {
+	switch {
+	case oursOk == theirsOk && ours == theirs:
+		return ours, false
+	case oursOk == baseOk && ours == base:
+		return theirs, false
+	case theirsOk == baseOk && theirs == base:
+		return ours, false
+	}
+	return "", true
+}
+
+func sortMergeConflicts(conflicts []MergeConflict) {
+	sort.SliceStable(conflicts, func(a, b int) bool {
+		return lessRecord(conflicts[a].Scopes, conflicts[a].Language, conflicts[b].Scopes, conflicts[b].Language)
+	})
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	Init()
+	ours := BaseI18nValue.Clone()
+	ours.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	ours.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+	theirs := BaseI18nValue.Clone()
+	theirs.PushMessage(ChineseLn, "", "system", "error", "unknown")
+	theirs.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+
+	res, conflicts, err := Merge(BaseI18nValue, ours, theirs, MergeFail)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("Get conflicts: %v, err: %v, want nothing", conflicts, err)
+	}
+
+	want := BaseI18nValue.Clone()
+	want.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	want.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+	want.PushMessage(ChineseLn, "", "system", "error", "unknown")
+	if !res.IsMessageEquals(want) {
+		t.Errorf("Merged result is not expected, diff: %v", res.Diff(want).Changes)
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	Init()
+	ours := BaseI18nValue.Clone()
+	ours.PushMessage(EnglishLn, "an error occur", "system", "text", "error")
+	theirs := BaseI18nValue.Clone()
+	theirs.PushMessage(EnglishLn, "something wrong", "system", "text", "error")
+
+	cases := map[MergePolicy]string{
+		MergeOurs:            "an error occur",
+		MergeTheirs:          "something wrong",
+		MergeConflictMarkers: ConflictMarkerValue("an error occur", "something wrong"),
+	}
+	for policy, want := range cases {
+		res, conflicts, err := Merge(BaseI18nValue, ours, theirs, policy)
+		if err != nil || len(conflicts) != 1 {
+			t.Fatalf("Policy %s get conflicts: %v, err: %v, want one conflict", policy, conflicts, err)
+		}
+		if value, _ := res.Message(EnglishLn, "system", "text", "error"); value != want {
+			t.Errorf("Policy %s get: [%s], want: [%s]", policy, value, want)
+		}
+	}
+
+	if !HasConflictMarker(cases[MergeConflictMarkers]) {
+		t.Error("Conflict marker value should be detected. But not.")
+	}
+
+	_, _, err := Merge(BaseI18nValue, ours, theirs, MergeFail)
+	conflictErr := &MergeConflictError{}
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 {
+		t.Errorf("Get error: [%v], want one conflict", err)
+	}
+}
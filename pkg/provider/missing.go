@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MissingHandler is invoked when I18n can't find the MessageValue of specify language and scopes. The returned value
+// and bool will be returned by I18n.Message directly, so a MissingHandler can return a fallback value with true, or
+// only record the miss and return empty value with false.
+//
+// A MissingHandler can log, count or record the miss, but it should not change the I18n instance which invoked it.
+type MissingHandler func(ln string, scopes ...string) (string, bool)
+
+// SetMissingHandler will set the MissingHandler of current instance, a nil handler will disable the MissingHandler. If
+// you need more than one handler, see ChainMissingHandlers.
+func (i *I18n) SetMissingHandler(handler MissingHandler) *I18n {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.missingHandler = handler
+	return i
+}
+
+// MissingHandler return the MissingHandler of current instance, it may be nil.
+func (i *I18n) MissingHandler() MissingHandler {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.missingHandler
+}
+
+// ChainMissingHandlers return a MissingHandler which invokes all handlers in order. All handlers will be invoked, and
+// the first value with true will be returned.
+func ChainMissingHandlers(handlers ...MissingHandler) MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		res, found := "", false
+		for _, handler := range handlers {
+			if handler == nil {
+				continue
+			}
+			if value, ok := handler(ln, scopes...); ok && !found {
+				res, found = value, true
+			}
+		}
+		return res, found
+	}
+}
+
+// FallbackLanguageHandler return a MissingHandler which tries to find the value of the same scopes in languages by
+// order. The languages are the language string value of i.Standard, like 'en'.
+func FallbackLanguageHandler(i *I18n, languages ...string) MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		for _, language := range languages {
+			if language == ln {
+				continue
+			}
+			if value, ok := i.Lookup(language, scopes...); ok {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
+
+// PlaceholderHandler return a MissingHandler which always returns the value of placeholder with true, so the callers
+// get a visible placeholder like 'en_user.text.login' instead of an empty value. It should be the last handler of a
+// chain, the handlers before it can still return a better value.
+func PlaceholderHandler(placeholder func(ln string, scopes ...string) string) MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		return placeholder(ln, scopes...), true
+	}
+}
+
+// LogHandler return a MissingHandler which writes every miss to the logger, it always returns empty value and false.
+func LogHandler(logger *log.Logger) MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		logger.Printf("missing message: %s[%s]", strings.Join(scopes, "."), ln)
+		return "", false
+	}
+}
+
+// CountHandler return a MissingHandler which increases the counter by every miss atomically, it always returns empty
+// value and false. Read the counter by atomic.LoadUint64.
+func CountHandler(counter *uint64) MissingHandler {
+	return func(ln string, scopes ...string) (string, bool) {
+		atomic.AddUint64(counter, 1)
+		return "", false
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+
+// MissingRecord is the aggregated info of the miss of one language and scopes.
+type MissingRecord struct {
+	Scopes    []string  `yaml:"scopes" json:"scopes"`
+	Language  string    `yaml:"language" json:"language"`
+	Count     uint64    `yaml:"count" json:"count"`
+	FirstSeen time.Time `yaml:"first_seen" json:"first_seen"`
+	LastSeen  time.Time `yaml:"last_seen" json:"last_seen"`
+}
+
+// MissingCollector aggregates the misses of I18n. Use the MissingCollector.Collect as the MissingHandler:
+//
+//	collector := NewMissingCollector(0)
+//	i.SetMissingHandler(collector.Collect)
+//
+// The MissingCollector is thread-safe.
+type MissingCollector struct {
+	lock    sync.Mutex
+	limit   int
+	records map[string]*MissingRecord
+}
+
+// NewMissingCollector return a *MissingCollector. The limit is the max count of different records, the new miss will
+// be dropped when limit reached. If limit <= 0, no limit.
+func NewMissingCollector(limit int) *MissingCollector {
+	return &MissingCollector{
+		limit:   limit,
+		records: map[string]*MissingRecord{},
+	}
+}
+
+// Collect record the miss of specify language and scopes, it always returns empty value and false. Collect is a
+// MissingHandler.
+func (c *MissingCollector) Collect(ln string, scopes ...string) (string, bool) {
+	now := time.Now()
+	key := recordKey(ln, scopes...)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if item, ok := c.records[key]; ok {
+		item.Count++
+		item.LastSeen = now
+		return "", false
+	}
+	if c.limit > 0 && len(c.records) >= c.limit {
+		return "", false
+	}
+
+	scopesCopy := make([]string, len(scopes))
+	copy(scopesCopy, scopes)
+	c.records[key] = &MissingRecord{
+		Scopes:    scopesCopy,
+		Language:  ln,
+		Count:     1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	return "", false
+}
+
+// Records return all MissingRecord, the records are sorted by scopes and language.
+func (c *MissingCollector) Records() []MissingRecord {
+	c.lock.Lock()
+	res := make([]MissingRecord, 0, len(c.records))
+	for _, item := range c.records {
+		res = append(res, *item)
+	}
+	c.lock.Unlock()
+
+	sort.Slice(res, func(a, b int) bool {
+		return lessRecord(res[a].Scopes, res[a].Language, res[b].Scopes, res[b].Language)
+	})
+	return res
+}
+
+// Reset drop all records.
+func (c *MissingCollector) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.records = map[string]*MissingRecord{}
+}
+
+// Skeleton return an I18n instance which contains all missed values, it can be sent to translators. Because an empty
+// value means delete, every value is filled by placeholder. If placeholder is nil, the value is the scopes joined by
+// '.'.
+func (c *MissingCollector) Skeleton(standard string, placeholder func(record MissingRecord) string) *I18n {
+	if placeholder == nil {
+		placeholder = func(record MissingRecord) string {
+			return strings.Join(record.Scopes, ".")
+		}
+	}
+
+	res := NewI18n(standard)
+	for _, item := range c.Records() {
+		res.PushMessageByString(item.Language, placeholder(item), item.Scopes...)
+	}
+	return res
+}
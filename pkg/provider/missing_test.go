@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMissingHandler(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	collector := NewMissingCollector(0)
+	instance.SetMissingHandler(ChainMissingHandlers(
+		collector.Collect,
+		FallbackLanguageHandler(instance, EnglishLn.Lower(instance.Standard)),
+	))
+
+	if value, ok := instance.Message(JapaneseLn, "user", "text", "test"); !ok || value != "test" {
+		t.Errorf("Get: [%s], [%v], want the english fallback value", value, ok)
+	}
+	if _, ok := instance.Message(JapaneseLn, "user", "text", "none"); ok {
+		t.Error("Value without any fallback should not be found. But found.")
+	}
+	instance.Message(JapaneseLn, "user", "text", "none")
+
+	records := collector.Records()
+	if len(records) != 2 {
+		t.Fatalf("Get %d records, want 2: %v", len(records), records)
+	}
+	if records[0].Count != 2 || records[1].Count != 1 {
+		t.Errorf("Get records: %v, want count 2 and 1", records)
+	}
+
+	skeleton := collector.Skeleton(instance.Standard, nil)
+	if value, ok := skeleton.Message(JapaneseLn, "user", "text", "none"); !ok || value != "user.text.none" {
+		t.Errorf("Get skeleton value: [%s], [%v], want: [user.text.none]", value, ok)
+	}
+}
+
+func TestMissingHandlers(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	buffer := &bytes.Buffer{}
+	var count uint64
+	instance.SetMissingHandler(ChainMissingHandlers(
+		LogHandler(log.New(buffer, "", 0)),
+		CountHandler(&count),
+		PlaceholderHandler(func(ln string, scopes ...string) string {
+			return ln + "_" + strings.Join(scopes, ".")
+		}),
+	))
+
+	if value, ok := instance.Message(JapaneseLn, "user", "text", "none"); !ok || value != "ja_user.text.none" {
+		t.Errorf("Get: [%s], [%v], want the placeholder", value, ok)
+	}
+	if value, ok := instance.Message(EnglishLn, "user", "text", "test"); !ok || value != "test" {
+		t.Errorf("Get: [%s], [%v], want the value, the handlers should not be invoked", value, ok)
+	}
+	if atomic.LoadUint64(&count) != 1 {
+		t.Errorf("Get count: %d, want: 1", count)
+	}
+	if buffer.String() != "missing message: user.text.none[ja]\n" {
+		t.Errorf("Get log: [%s]", buffer.String())
+	}
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Revision return the count of effective changes of current instance. It's increased by every change of the methods
+// of I18n, so two equal Revision of one instance means nothing changed.
+func (i *I18n) Revision() uint64 {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.revision
+}
+
+// ModifiedAt return the time of the last change, return zero time if current instance never changed.
+func (i *I18n) ModifiedAt() time.Time {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return i.modifiedAt
+}
+
+// Checksum return the hex sha256 of all MessageValue of current instance. Different from Revision, the Checksum only
+// depends on the content, two instances with same MessageValue always have same Checksum. The Checksum is cached until
+// next change.
+func (i *I18n) Checksum() string {
+	i.lock.RLock()
+	if i.checksumValid {
+		defer i.lock.RUnlock()
+		return i.checksum
+	}
+	i.lock.RUnlock()
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.checksumValid {
+		return i.checksum
+	}
+
+	var lines []string
+	i.Values.WalkRecord(func(ln, messageValue string, flags ...string) {
+		value, _ := json.Marshal([]string{strings.Join(flags, "\x00"), ln, messageValue})
+		lines = append(lines, string(value))
+	})
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+		hash.Write([]byte("\n"))
+	}
+	i.checksum = hex.EncodeToString(hash.Sum(nil))
+	i.checksumValid = true
+	return i.checksum
+}
+
+// MarshalJSON marshal current instance with the read lock.
+func (i *I18n) MarshalJSON() ([]byte, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+	return json.Marshal(struct {
+		Values   *Namespace `json:"values"`
+		Standard string     `json:"standard"`
+	}{
+		Values:   i.Values,
+		Standard: i.Standard,
+	})
+}
+
+// modified should be invoked with the write lock after every change.
+func (i *I18n) modified() {
+	i.revision++
+	i.modifiedAt = time.Now()
+	i.checksumValid = false
+}
@@ -0,0 +1,30 @@
+package provider
+
+import "testing"
+
+func TestRevision(t *testing.T) {
+	Init()
+	instance := BaseI18nValue.Clone()
+	revision, checksum := instance.Revision(), instance.Checksum()
+	if revision == 0 || instance.ModifiedAt().IsZero() {
+		t.Errorf("Built instance should have revision and modified time, get: %d, %v", revision, instance.ModifiedAt())
+	}
+	if checksum != BaseI18nValue.Checksum() {
+		t.Error("Instances with same values should have same checksum. But not.")
+	}
+
+	instance.PushMessage(EnglishLn, "test", "user", "text", "test")
+	instance.PushMessage(JapaneseLn, "", "user", "text", "test")
+	if instance.Revision() != revision {
+		t.Errorf("Not changed push should not increase revision, get: %d, want: %d", instance.Revision(), revision)
+	}
+
+	instance.PushMessage(JapaneseLn, "テスト", "user", "text", "test")
+	if instance.Revision() != revision+1 || instance.Checksum() == checksum {
+		t.Errorf("Change should increase revision and change checksum, get: %d, %s", instance.Revision(), instance.Checksum())
+	}
+	instance.PushMessage(JapaneseLn, "", "user", "text", "test")
+	if instance.Revision() != revision+2 || instance.Checksum() != checksum {
+		t.Errorf("Delete should increase revision and restore checksum, get: %d, %s", instance.Revision(), instance.Checksum())
+	}
+}
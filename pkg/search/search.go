@@ -0,0 +1,425 @@
+// Package search finds the values of an I18n instance by their text or scope path. The Index keeps a copy of the values
+// sorted by the scopes and another sorted by the language and value, and they are kept up to date by the changes of the
+// instance.
+package search
+
+import (
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field is the field of a value which the text of a Query matches.
+type Field string
+
+const (
+	FieldValue Field = "value" // The value of the language.
+	FieldScope Field = "scope" // The scope path joined by '.', like 'user.text.login'.
+)
+
+// Query selects the values of an Index, the zero value of a field means no filter.
+type Query struct {
+	// Text is the substring, or the regular expression if Regex. Empty matches all values.
+	Text          string
+	Regex         bool
+	CaseSensitive bool
+	// Field is the field which the Text matches, FieldValue if it is empty.
+	Field    Field
+	Language string
+	// Scopes is the scope prefix.
+	Scopes []string
+	// Offset is the count of the matched values which are skipped, and Limit is the max count of the Hits, 0 means no
+	// limit.
+	Offset int
+	Limit  int
+}
+
+// Hit is a matched value.
+type Hit struct {
+	Scopes   []string `json:"scopes" yaml:"scopes"`
+	Language string   `json:"language" yaml:"language"`
+	Value    string   `json:"value" yaml:"value"`
+}
+
+// Result is the page of the matched values. The Total is the count of all matched values, and the Hits are sorted by
+// the scopes and the language. The Revision is the revision of the instance which the Index has.
+type Result struct {
+	Total    int    `json:"total" yaml:"total"`
+	Revision uint64 `json:"revision" yaml:"revision"`
+	Hits     []Hit  `json:"hits" yaml:"hits"`
+}
+
+// Key is a key and the values of its languages.
+type Key struct {
+	Scopes []string          `json:"scopes" yaml:"scopes"`
+	Values map[string]string `json:"values" yaml:"values"`
+}
+
+// KeyQuery selects a page of the keys of an Index.
+type KeyQuery struct {
+	// Scopes is the scope prefix.
+	Scopes []string
+	// Language selects the keys which have a value of the language, and only the value is in the Key. It is required
+	// by ByValue.
+	Language string
+	// ByValue sorts the keys by the value of the Language then the scopes, else they are sorted by the scopes.
+	ByValue    bool
+	Descending bool
+	// After and AfterValue are the scopes and the value of the last key of the previous page, the keys are after it
+	// in the order. A nil After means the first page.
+	After      []string
+	AfterValue string
+	// Limit is the max count of the Keys, 0 means no limit.
+	Limit int
+}
+
+// KeyResult is a page of the keys. More is true if there are more keys after the page.
+type KeyResult struct {
+	Keys     []Key
+	More     bool
+	Revision uint64
+}
+
+type entry struct {
+	Hit
+	// path, lowerPath and lowerValue are kept for the case-insensitive matching, and lowerLanguage for the order of
+	// the values.
+	path          string
+	lowerPath     string
+	lowerValue    string
+	lowerLanguage string
+}
+
+func newEntry(scopes []string, language, value string) *entry {
+	path := strings.Join(scopes, ".")
+	return &entry{
+		Hit:           Hit{Scopes: scopes, Language: language, Value: value},
+		path:          path,
+		lowerPath:     strings.ToLower(path),
+		lowerValue:    strings.ToLower(value),
+		lowerLanguage: strings.ToLower(language),
+	}
+}
+
+// Index is the search index of an I18n instance, it is safe for concurrent use.
+type Index struct {
+	lock    sync.RWMutex
+	entries []*entry
+	// values is the entries sorted by the language and the value, see valueLess.
+	values   []*entry
+	revision uint64
+	cancel   func()
+}
+
+// NewIndex return the *Index of the instance, it follows the changes of the instance until Close.
+func NewIndex(instance *provider.I18n) *Index {
+	index := &Index{}
+
+	// the changes after the subscription wait for the lock, they are applied after the walk in the order of revision,
+	// so the index is the same as the instance once they are applied.
+	index.lock.Lock()
+	defer index.lock.Unlock()
+	index.cancel = instance.Subscribe(index.apply)
+	index.revision = instance.Revision()
+	instance.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		scopes := make([]string, len(flags))
+		copy(scopes, flags)
+		index.entries = append(index.entries, newEntry(scopes, languageValue, messageValue))
+	})
+	sort.Slice(index.entries, func(a, b int) bool {
+		return less(index.entries[a].Scopes, index.entries[a].Language, index.entries[b].Scopes, index.entries[b].Language)
+	})
+	index.values = append([]*entry{}, index.entries...)
+	sort.Slice(index.values, func(a, b int) bool {
+		return valueLess(index.values[a], index.values[b].lowerLanguage, index.values[b].Value, index.values[b].Scopes)
+	})
+	return index
+}
+
+// Close stop following the changes of the instance.
+func (index *Index) Close() {
+	index.cancel()
+}
+
+// Len return the count of the values in the Index.
+func (index *Index) Len() int {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+	return len(index.entries)
+}
+
+// Search return the values which match the query. It returns an error if the regular expression of the query is
+// invalid.
+func (index *Index) Search(query Query) (Result, error) {
+	match, err := query.matcher()
+	if err != nil {
+		return Result{}, err
+	}
+
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+
+	res := Result{Revision: index.revision, Hits: []Hit{}}
+	// the values under the scope prefix are adjacent in the sorted entries.
+	for position := index.search(query.Scopes, ""); position < len(index.entries); position++ {
+		item := index.entries[position]
+		if !HasPrefix(item.Scopes, query.Scopes) {
+			break
+		}
+		if len(query.Language) != 0 && !strings.EqualFold(query.Language, item.Language) {
+			continue
+		}
+		if !match(item) {
+			continue
+		}
+		if res.Total >= query.Offset && (query.Limit <= 0 || len(res.Hits) < query.Limit) {
+			res.Hits = append(res.Hits, item.Hit)
+		}
+		res.Total++
+	}
+	return res, nil
+}
+
+// Keys return a page of the keys under the scope prefix of the query. A page costs the entries it passes over instead
+// of all keys, so the pages of a large catalog are cheap.
+func (index *Index) Keys(query KeyQuery) KeyResult {
+	index.lock.RLock()
+	defer index.lock.RUnlock()
+
+	res := KeyResult{Keys: []Key{}, Revision: index.revision}
+	// add the entry to the last key or a new key, and return false once the page is full.
+	add := func(item *entry) bool {
+		if last := len(res.Keys) - 1; last >= 0 && compareScopes(res.Keys[last].Scopes, item.Scopes) == 0 {
+			res.Keys[last].Values[item.Language] = item.Value
+			return true
+		}
+		if query.Limit > 0 && len(res.Keys) == query.Limit {
+			res.More = true
+			return false
+		}
+		res.Keys = append(res.Keys, Key{Scopes: append([]string{}, item.Scopes...), Values: map[string]string{item.Language: item.Value}})
+		return true
+	}
+
+	if query.ByValue {
+		// the values of the language are adjacent in the values, and the cursor is a position in them.
+		language := strings.ToLower(query.Language)
+		after := &entry{Hit: Hit{Scopes: query.After, Value: query.AfterValue}, lowerLanguage: language}
+		if query.Descending {
+			// the end is the first value after the language, or the first value of the cursor.
+			end := sort.Search(len(index.values), func(position int) bool {
+				item := index.values[position]
+				if query.After != nil {
+					return !valueLess(item, after.lowerLanguage, after.Value, after.Scopes)
+				}
+				return item.lowerLanguage > language
+			})
+			for position := end - 1; position >= 0 && index.values[position].lowerLanguage == language; position-- {
+				if item := index.values[position]; HasPrefix(item.Scopes, query.Scopes) && !add(item) {
+					break
+				}
+			}
+			return res
+		}
+		start := sort.Search(len(index.values), func(position int) bool {
+			item := index.values[position]
+			if query.After != nil {
+				return valueLess(after, item.lowerLanguage, item.Value, item.Scopes)
+			}
+			return item.lowerLanguage >= language
+		})
+		for position := start; position < len(index.values) && index.values[position].lowerLanguage == language; position++ {
+			if item := index.values[position]; HasPrefix(item.Scopes, query.Scopes) && !add(item) {
+				break
+			}
+		}
+		return res
+	}
+
+	match := func(item *entry) bool {
+		return len(query.Language) == 0 || strings.EqualFold(query.Language, item.Language)
+	}
+	if query.Descending {
+		// the end is the first entry after the scope prefix, or the first entry of the cursor.
+		end := sort.Search(len(index.entries), func(position int) bool {
+			item := index.entries[position]
+			if query.After != nil {
+				return compareScopes(item.Scopes, query.After) >= 0
+			}
+			return compareScopes(item.Scopes, query.Scopes) > 0 && !HasPrefix(item.Scopes, query.Scopes)
+		})
+		for position := end - 1; position >= 0 && HasPrefix(index.entries[position].Scopes, query.Scopes); position-- {
+			if item := index.entries[position]; match(item) && !add(item) {
+				break
+			}
+		}
+		return res
+	}
+	start := index.search(query.Scopes, "")
+	if query.After != nil {
+		// the values of the cursor are skipped.
+		if after := sort.Search(len(index.entries), func(position int) bool {
+			return compareScopes(index.entries[position].Scopes, query.After) > 0
+		}); after > start {
+			start = after
+		}
+	}
+	for position := start; position < len(index.entries) && HasPrefix(index.entries[position].Scopes, query.Scopes); position++ {
+		if item := index.entries[position]; match(item) && !add(item) {
+			break
+		}
+	}
+	return res
+}
+
+// matcher return the func which reports whether an entry matches the text of the query.
+func (query Query) matcher() (func(item *entry) bool, error) {
+	field := func(item *entry) string {
+		if query.Field == FieldScope {
+			return item.path
+		}
+		return item.Value
+	}
+	switch {
+	case len(query.Text) == 0:
+		return func(*entry) bool { return true }, nil
+	case query.Regex:
+		expression := query.Text
+		if !query.CaseSensitive {
+			expression = "(?i)" + expression
+		}
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return func(item *entry) bool {
+			return pattern.MatchString(field(item))
+		}, nil
+	case query.CaseSensitive:
+		return func(item *entry) bool {
+			return strings.Contains(field(item), query.Text)
+		}, nil
+	}
+
+	text := strings.ToLower(query.Text)
+	return func(item *entry) bool {
+		if query.Field == FieldScope {
+			return strings.Contains(item.lowerPath, text)
+		}
+		return strings.Contains(item.lowerValue, text)
+	}, nil
+}
+
+// apply the change to the Index, it is the listener of the instance.
+func (index *Index) apply(event provider.ChangeEvent) {
+	index.lock.Lock()
+	defer index.lock.Unlock()
+
+	index.revision = event.Revision
+	position := index.search(event.Scopes, event.Language)
+	found := position < len(index.entries) && equal(index.entries[position], event.Scopes, event.Language)
+	switch {
+	case event.Type == provider.ChangeRemoved:
+		if found {
+			index.removeValue(index.entries[position])
+			index.entries = append(index.entries[:position], index.entries[position+1:]...)
+		}
+	case found:
+		index.removeValue(index.entries[position])
+		index.entries[position] = newEntry(index.entries[position].Scopes, event.Language, event.NewValue)
+		index.insertValue(index.entries[position])
+	default:
+		scopes := make([]string, len(event.Scopes))
+		copy(scopes, event.Scopes)
+		index.entries = append(index.entries, nil)
+		copy(index.entries[position+1:], index.entries[position:])
+		index.entries[position] = newEntry(scopes, event.Language, event.NewValue)
+		index.insertValue(index.entries[position])
+	}
+}
+
+// searchValue return the position of the first value which is not less than the item, it should be invoked with the
+// lock.
+func (index *Index) searchValue(item *entry) int {
+	return sort.Search(len(index.values), func(position int) bool {
+		return !valueLess(index.values[position], item.lowerLanguage, item.Value, item.Scopes)
+	})
+}
+
+func (index *Index) insertValue(item *entry) {
+	position := index.searchValue(item)
+	index.values = append(index.values, nil)
+	copy(index.values[position+1:], index.values[position:])
+	index.values[position] = item
+}
+
+func (index *Index) removeValue(item *entry) {
+	// the values of the languages which differ in the case only are equal in the order.
+	for position := index.searchValue(item); position < len(index.values); position++ {
+		if index.values[position] == item {
+			index.values = append(index.values[:position], index.values[position+1:]...)
+			return
+		}
+		if valueLess(item, index.values[position].lowerLanguage, index.values[position].Value, index.values[position].Scopes) {
+			return
+		}
+	}
+}
+
+// search return the position of the first entry which is not less than the scopes and language, it should be invoked
+// with the lock.
+func (index *Index) search(scopes []string, language string) int {
+	return sort.Search(len(index.entries), func(position int) bool {
+		item := index.entries[position]
+		return !less(item.Scopes, item.Language, scopes, language)
+	})
+}
+
+func equal(item *entry, scopes []string, language string) bool {
+	return item.Language == language && len(item.Scopes) == len(scopes) && HasPrefix(item.Scopes, scopes)
+}
+
+// HasPrefix return true if the prefix is the first scopes of the scopes, an empty prefix is the prefix of all scopes.
+func HasPrefix(scopes, prefix []string) bool {
+	if len(scopes) < len(prefix) {
+		return false
+	}
+	for position, scope := range prefix {
+		if scopes[position] != scope {
+			return false
+		}
+	}
+	return true
+}
+
+// less compare the scopes then the language, so the values under a scope prefix are adjacent.
+func less(aScopes []string, aLanguage string, bScopes []string, bLanguage string) bool {
+	if res := compareScopes(aScopes, bScopes); res != 0 {
+		return res < 0
+	}
+	return aLanguage < bLanguage
+}
+
+// valueLess compare the lower language, the value then the scopes, so the values of a language are adjacent and sorted.
+func valueLess(item *entry, lowerLanguage, value string, scopes []string) bool {
+	if item.lowerLanguage != lowerLanguage {
+		return item.lowerLanguage < lowerLanguage
+	}
+	if item.Value != value {
+		return item.Value < value
+	}
+	return compareScopes(item.Scopes, scopes) < 0
+}
+
+// compareScopes compare the scopes element by element.
+func compareScopes(a, b []string) int {
+	for position := 0; position < len(a) && position < len(b); position++ {
+		if res := strings.Compare(a[position], b[position]); res != 0 {
+			return res
+		}
+	}
+	return len(a) - len(b)
+}
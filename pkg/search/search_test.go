@@ -0,0 +1,136 @@
+package search
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func paths(result Result) []string {
+	res := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		res = append(res, strings.Join(hit.Scopes, ".")+":"+hit.Language)
+	}
+	return res
+}
+
+func TestSearch(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.PushMessageByString("en", "Login", "user", "text", "login")
+	instance.PushMessageByString("ja", "ログイン", "user", "text", "login")
+	instance.PushMessageByString("en", "Log out", "user", "text", "logout")
+	instance.PushMessageByString("en", "Cart", "shop", "cart")
+	instance.PushMessageByString("en", "Please login first", "shop", "error")
+
+	index := NewIndex(instance)
+	defer index.Close()
+
+	cases := []struct {
+		name  string
+		query Query
+		total int
+		want  []string
+	}{
+		{"case-insensitive", Query{Text: "LOGIN"}, 2, []string{"shop.error:en", "user.text.login:en"}},
+		{"case-sensitive", Query{Text: "Login", CaseSensitive: true}, 1, []string{"user.text.login:en"}},
+		{"regex", Query{Text: "^log", Regex: true}, 2, []string{"user.text.login:en", "user.text.logout:en"}},
+		{"scope", Query{Text: "TEXT.LOG", Field: FieldScope, Language: "ja"}, 1, []string{"user.text.login:ja"}},
+		{"scope prefix", Query{Scopes: []string{"user", "text"}}, 3,
+			[]string{"user.text.login:en", "user.text.login:ja", "user.text.logout:en"}},
+		{"page", Query{Scopes: []string{"user"}, Offset: 1, Limit: 1}, 3, []string{"user.text.login:ja"}},
+		{"no prefix", Query{Scopes: []string{"user", "te"}}, 0, []string{}},
+	}
+	for _, c := range cases {
+		result, err := index.Search(c.query)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if result.Total != c.total || !reflect.DeepEqual(paths(result), c.want) {
+			t.Errorf("%s get: %d %v, want: %d %v", c.name, result.Total, paths(result), c.total, c.want)
+		}
+	}
+
+	if _, err := index.Search(Query{Text: "(", Regex: true}); err == nil {
+		t.Error("Search of an invalid regex should fail")
+	}
+}
+
+func TestIndexChanges(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.PushMessageByString("en", "Login", "user", "login")
+	index := NewIndex(instance)
+	defer index.Close()
+
+	instance.PushMessageByString("en", "Sign in", "user", "login")
+	instance.PushMessageByString("en", "Sign out", "user", "logout")
+	instance.PushMessageByString("en", "Account", "account")
+	instance.PushMessageByString("en", "", "account")
+
+	result, _ := index.Search(Query{Text: "sign"})
+	if want := []string{"user.login:en", "user.logout:en"}; !reflect.DeepEqual(paths(result), want) {
+		t.Errorf("Search after the changes get: %v, want: %v", paths(result), want)
+	}
+	if result.Revision != instance.Revision() || index.Len() != 2 {
+		t.Errorf("the index get revision: %d, len: %d, want: %d, 2", result.Revision, index.Len(), instance.Revision())
+	}
+
+	index.Close()
+	instance.PushMessageByString("en", "Sign up", "user", "signup")
+	if index.Len() != 2 {
+		t.Error("the closed index should not follow the changes")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.PushMessageByString("en", "Login", "user", "login")
+	instance.PushMessageByString("ja", "ログイン", "user", "login")
+	instance.PushMessageByString("en", "Log out", "user", "logout")
+	instance.PushMessageByString("ja", "登録", "user", "signup")
+	instance.PushMessageByString("en", "Cart", "shop", "cart")
+	index := NewIndex(instance)
+	defer index.Close()
+
+	keys := func(result KeyResult) string {
+		var res []string
+		for _, key := range result.Keys {
+			res = append(res, strings.Join(key.Scopes, ".")+":"+key.Values["en"])
+		}
+		return strings.Join(res, ",")
+	}
+
+	cases := []struct {
+		name  string
+		query KeyQuery
+		want  string
+		more  bool
+	}{
+		{"first page", KeyQuery{Limit: 2}, "shop.cart:Cart,user.login:Login", true},
+		{"after", KeyQuery{After: []string{"user", "login"}, Limit: 2}, "user.logout:Log out,user.signup:", false},
+		{"prefix", KeyQuery{Scopes: []string{"user"}, Language: "EN"}, "user.login:Login,user.logout:Log out", false},
+		{"descending", KeyQuery{Scopes: []string{"user"}, Descending: true, After: []string{"user", "signup"}},
+			"user.logout:Log out,user.login:Login", false},
+		{"by value", KeyQuery{Language: "en", ByValue: true, Limit: 2}, "shop.cart:Cart,user.logout:Log out", true},
+		{"by value after", KeyQuery{Language: "en", ByValue: true, After: []string{"user", "logout"}, AfterValue: "Log out"},
+			"user.login:Login", false},
+		{"by value descending", KeyQuery{Scopes: []string{"user"}, Language: "en", ByValue: true, Descending: true},
+			"user.login:Login,user.logout:Log out", false},
+	}
+	for _, c := range cases {
+		result := index.Keys(c.query)
+		if got := keys(result); got != c.want || result.More != c.more {
+			t.Errorf("%s get: %s %v, want: %s %v", c.name, got, result.More, c.want, c.more)
+		}
+	}
+	if result := index.Keys(KeyQuery{Scopes: []string{"user", "login"}}); len(result.Keys) != 1 || len(result.Keys[0].Values) != 2 {
+		t.Errorf("the key get: %+v, want the values of all languages", result.Keys)
+	}
+
+	// the values follow the changes of the instance.
+	instance.PushMessageByString("en", "Basket", "shop", "cart")
+	instance.PushMessageByString("en", "", "user", "logout")
+	if got := keys(index.Keys(KeyQuery{Language: "en", ByValue: true})); got != "shop.cart:Basket,user.login:Login" {
+		t.Errorf("the keys by value after the changes get: %s", got)
+	}
+}
@@ -0,0 +1,300 @@
+// Package snapshot is the named, immutable snapshots of an I18n instance. Every snapshot is a directory in the store
+// directory, which contains the catalog file written by pkg/files and the metadata 'snapshot.json'.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/uberate/i18n/pkg/files"
+	"github.com/uberate/i18n/pkg/provider"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Live is the reserved snapshot name of the live catalog, it can be used in diff but can't be created.
+const Live = "live"
+
+const metadataFile = "snapshot.json"
+
+var (
+	ErrNotFound    = errors.New("snapshot not found")
+	ErrExists      = errors.New("snapshot already exists")
+	ErrInvalidName = errors.New("invalid snapshot name, it should match " + namePattern.String() + " and not be 'live'")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Snapshot is the metadata of a snapshot. The Revision and Checksum are from the I18n instance when it is created, and
+// the Messages is the count of the language values.
+type Snapshot struct {
+	Name        string    `json:"name" yaml:"name"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Auto        bool      `json:"auto" yaml:"auto"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	Revision    uint64    `json:"revision" yaml:"revision"`
+	Checksum    string    `json:"checksum" yaml:"checksum"`
+	Messages    int       `json:"messages" yaml:"messages"`
+	File        string    `json:"file" yaml:"file"`
+}
+
+// Store saves the snapshots in a directory. The Store is thread-safe.
+type Store struct {
+	lock sync.Mutex
+	dir  string
+	ext  string
+}
+
+// Open return a *Store of the directory, the directory will be created if not exists. The ext is the file extension
+// of the catalog file like '.json', it should be supported by pkg/files. An empty ext means '.json'.
+func Open(dir, ext string) (*Store, error) {
+	if len(ext) == 0 {
+		ext = ".json"
+	}
+	if _, ok := files.Writer(ext); !ok {
+		return nil, fmt.Errorf("unsupported snapshot format: %s", ext)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, ext: ext}, nil
+}
+
+// ValidName return true if the name can be used as a snapshot name.
+func ValidName(name string) bool {
+	return name != Live && namePattern.MatchString(name)
+}
+
+// Create save the instance as a new snapshot. If the name exists, return ErrExists, the snapshot is never overwritten.
+func (s *Store) Create(name, description string, auto bool, instance *provider.I18n) (Snapshot, error) {
+	if !ValidName(name) {
+		return Snapshot{}, ErrInvalidName
+	}
+	clone, revision := instance.CloneRevision()
+	snapshot := Snapshot{
+		Name:        name,
+		Description: description,
+		Auto:        auto,
+		CreatedAt:   time.Now(),
+		Revision:    revision,
+		Checksum:    clone.Checksum(),
+		File:        "catalog" + s.ext,
+	}
+	clone.WalkRecord(func(languageValue, messageValue string, flags ...string) {
+		snapshot.Messages++
+	})
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	dir := filepath.Join(s.dir, name)
+	if _, err := os.Stat(dir); err == nil {
+		return Snapshot{}, ErrExists
+	}
+
+	// write to a temporary directory first, a half-written snapshot is never seen.
+	temp, err := os.MkdirTemp(s.dir, ".tmp-"+name+"-")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer os.RemoveAll(temp)
+
+	if err = files.WriteFile(filepath.Join(temp, snapshot.File), clone); err != nil {
+		return Snapshot{}, err
+	}
+	metadata, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if err = os.WriteFile(filepath.Join(temp, metadataFile), metadata, 0644); err != nil {
+		return Snapshot{}, err
+	}
+	if err = os.Rename(temp, dir); err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// Get return the metadata of the snapshot, or ErrNotFound.
+func (s *Store) Get(name string) (Snapshot, error) {
+	if !ValidName(name) {
+		return Snapshot{}, ErrNotFound
+	}
+	value, err := os.ReadFile(filepath.Join(s.dir, name, metadataFile))
+	if os.IsNotExist(err) {
+		return Snapshot{}, ErrNotFound
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{}
+	if err = json.Unmarshal(value, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("snapshot %s: %v", name, err)
+	}
+	return snapshot, nil
+}
+
+// List return all snapshots, the oldest first.
+func (s *Store) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Snapshot{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !ValidName(entry.Name()) {
+			continue
+		}
+		snapshot, err := s.Get(entry.Name())
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, snapshot)
+	}
+	sort.SliceStable(res, func(a, b int) bool {
+		return res[a].CreatedAt.Before(res[b].CreatedAt)
+	})
+	return res, nil
+}
+
+// Load return the I18n instance of the snapshot, or ErrNotFound.
+func (s *Store) Load(name string) (*provider.I18n, error) {
+	snapshot, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return files.ReadFile(filepath.Join(s.dir, name, snapshot.File))
+}
+
+// Diff return the Patch which changes the snapshot from to the snapshot to. The name Live means the live instance.
+func (s *Store) Diff(from, to string, live *provider.I18n) (*provider.Patch, error) {
+	fromInstance, err := s.instance(from, live)
+	if err != nil {
+		return nil, err
+	}
+	toInstance, err := s.instance(to, live)
+	if err != nil {
+		return nil, err
+	}
+	return fromInstance.Diff(toInstance), nil
+}
+
+// Rollback replace all values of the live instance by the snapshot, and return the Patch which has been applied.
+func (s *Store) Rollback(name string, live *provider.I18n) (*provider.Patch, error) {
+	instance, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return live.Replace(instance), nil
+}
+
+func (s *Store) instance(name string, live *provider.I18n) (*provider.I18n, error) {
+	if name == Live {
+		return live, nil
+	}
+	return s.Load(name)
+}
+
+// AutoName return the name of an automatic snapshot, like 'auto-20221019T073020Z-r42'.
+func AutoName(revision uint64) string {
+	return fmt.Sprintf("auto-%s-r%d", time.Now().UTC().Format("20060102T150405Z"), revision)
+}
+
+// Prune remove the oldest automatic snapshots but the newest keep ones, the snapshots created by the users are never
+// removed. If keep <= 0, all snapshots are kept.
+func (s *Store) Prune(keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	snapshots, err := s.List()
+	if err != nil {
+		return err
+	}
+	var autos []Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Auto {
+			autos = append(autos, snapshot)
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for index := 0; index < len(autos)-keep; index++ {
+		if err = os.RemoveAll(filepath.Join(s.dir, autos[index].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Auto create a snapshot of the instance on every N changes, and return a func to stop it. After every creation, the
+// automatic snapshots but the newest keep ones are removed, see Prune. The errors of the creation are passed to
+// onError, it can be nil.
+//
+// The snapshots are created by a background goroutine, the listener of the instance only signals it, so the changing
+// goroutine is not blocked by the writing. The changes during a creation are in the next snapshot, the signals of them
+// are merged. The cancel waits for the creation in progress.
+func (s *Store) Auto(instance *provider.I18n, every, keep int, onError func(err error)) (cancel func()) {
+	if every <= 0 {
+		return func() {}
+	}
+
+	signal := make(chan struct{}, 1)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		created := uint64(0)
+		for {
+			select {
+			case <-done:
+				return
+			case <-signal:
+			}
+			// the merged signal of the changes which are already in the last snapshot.
+			revision := instance.Revision()
+			if revision == created {
+				continue
+			}
+			description := fmt.Sprintf("automatic snapshot after %d changes", every)
+			_, err := s.Create(AutoName(revision), description, true, instance)
+			if err == nil {
+				err = s.Prune(keep)
+			}
+			if err != nil && onError != nil {
+				onError(err)
+			}
+			created = revision
+		}
+	}()
+
+	// the listeners are invoked one by one, so the count needs no lock.
+	count := 0
+	unsubscribe := instance.Subscribe(func(event provider.ChangeEvent) {
+		count++
+		if count%every != 0 {
+			return
+		}
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			close(done)
+			<-stopped
+		})
+	}
+}
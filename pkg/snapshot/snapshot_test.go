@@ -0,0 +1,133 @@
+package snapshot
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	store, err := Open(t.TempDir(), ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	live := provider.NewI18n(provider.ISO6391)
+	live.PushMessage(provider.EnglishLn, "Login", "user", "text", "login")
+	live.PushMessage(provider.JapaneseLn, "ログイン", "user", "text", "login")
+
+	first, err := store.Create("v1", "first", false, live)
+	if err != nil || first.Messages != 2 || first.Checksum != live.Checksum() {
+		t.Fatalf("Create get: %+v, %v", first, err)
+	}
+	if _, err = store.Create("v1", "", false, live); err != ErrExists {
+		t.Errorf("Create exists snapshot get: %v, want: %v", err, ErrExists)
+	}
+	if _, err = store.Create("../v1", "", false, live); err != ErrInvalidName {
+		t.Errorf("Create invalid name get: %v, want: %v", err, ErrInvalidName)
+	}
+
+	live.PushMessage(provider.EnglishLn, "Sign in", "user", "text", "login")
+	live.PushMessage(provider.JapaneseLn, "", "user", "text", "login")
+	if _, err = store.Create("v2", "", false, live); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := store.List()
+	if err != nil || len(snapshots) != 2 || snapshots[0].Name != "v1" {
+		t.Errorf("List get: %v, %v", snapshots, err)
+	}
+
+	patch, err := store.Diff("v1", "v2", live)
+	if err != nil || len(patch.Changes) != 2 {
+		t.Errorf("Diff get: %v, %v, want 2 changes", patch, err)
+	}
+	if patch, _ = store.Diff("v2", Live, live); !patch.IsEmpty() {
+		t.Errorf("Diff of v2 and live get: %v, want empty", patch)
+	}
+	if _, err = store.Diff("v1", "none", live); err != ErrNotFound {
+		t.Errorf("Diff with unknown snapshot get: %v, want: %v", err, ErrNotFound)
+	}
+
+	if _, err = store.Rollback("v1", live); err != nil {
+		t.Fatal(err)
+	}
+	if live.Checksum() != first.Checksum {
+		t.Error("Rollback should restore the values of the snapshot")
+	}
+}
+
+func TestAuto(t *testing.T) {
+	store, err := Open(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := provider.NewI18n(provider.ISO6391)
+	cancel := store.Auto(live, 2, 0, func(err error) {
+		t.Error(err)
+	})
+	defer cancel()
+
+	for _, value := range []string{"a", "b", "c", "d"} {
+		live.PushMessage(provider.EnglishLn, value, "key", value)
+	}
+	// the snapshots are created in background, the signals during a creation are merged.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snapshots, _ := store.List()
+		if len(snapshots) != 0 && snapshots[len(snapshots)-1].Messages == 4 {
+			if !snapshots[0].Auto || len(snapshots) > 2 {
+				t.Errorf("Get automatic snapshots: %v, want 1 or 2 automatic snapshots", snapshots)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Get automatic snapshots: %v, want the last one has 4 messages", snapshots)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAutoKeep(t *testing.T) {
+	store, err := Open(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := provider.NewI18n(provider.ISO6391)
+	if _, err = store.Create("manual", "", false, live); err != nil {
+		t.Fatal(err)
+	}
+	cancel := store.Auto(live, 1, 1, func(err error) {
+		t.Error(err)
+	})
+	defer cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for count := 1; count <= 3; count++ {
+		live.PushMessage(provider.EnglishLn, "value", "key", strconv.Itoa(count))
+		// wait for the snapshot of the change, so every change has its snapshot.
+		for {
+			snapshots, _ := store.List()
+			if last := snapshots[len(snapshots)-1]; last.Auto && last.Messages == count {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Get snapshots: %v, want the snapshot of %d messages", snapshots, count)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// the older automatic snapshots are removed after the creation.
+	for {
+		snapshots, _ := store.List()
+		if len(snapshots) == 2 && snapshots[0].Name == "manual" && snapshots[1].Messages == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Get snapshots: %v, want the manual one and the newest automatic one", snapshots)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
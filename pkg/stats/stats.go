@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Stat is the coverage statistics of one language. A key is a scopes which has value of any language.
+//
+// The Words and Characters are counted from the translated values of the language.
+type Stat struct {
+	Language   string  `yaml:"language" json:"language"`
+	Scope      string  `yaml:"scope,omitempty" json:"scope,omitempty"`
+	Total      int     `yaml:"total" json:"total"`
+	Translated int     `yaml:"translated" json:"translated"`
+	Missing    int     `yaml:"missing" json:"missing"`
+	Percentage float64 `yaml:"percentage" json:"percentage"`
+	Words      int     `yaml:"words" json:"words"`
+	Characters int     `yaml:"characters" json:"characters"`
+}
+
+// Report is the coverage statistics of an I18n instance.
+//
+// The Languages is sorted. The Total is the Stat of whole instance of every language, and the Scopes is the Stat of
+// every language of every top-level scope. Both of them are sorted by scope and language.
+type Report struct {
+	Languages []string `yaml:"languages" json:"languages"`
+	Total     []Stat   `yaml:"total" json:"total"`
+	Scopes    []Stat   `yaml:"scopes" json:"scopes"`
+}
+
+// Compute return the Report of the instance. If languages is empty, all languages of the instance will be computed.
+func Compute(i *provider.I18n, languages ...string) *Report {
+	if len(languages) == 0 {
+		languages = i.Languages()
+	}
+	languages = append([]string{}, languages...)
+	sort.Strings(languages)
+	// the duplicate languages like '-lang ja,ja' would count every key twice.
+	unique := languages[:0]
+	for index, language := range languages {
+		if index == 0 || language != languages[index-1] {
+			unique = append(unique, language)
+		}
+	}
+	languages = unique
+
+	total := map[string]*Stat{}
+	scopes := map[string]map[string]*Stat{}
+	for _, language := range languages {
+		total[language] = &Stat{Language: language}
+	}
+
+	i.WalkMessage(func(message map[string]string, flags ...string) {
+		if len(message) == 0 {
+			return
+		}
+		scope := ""
+		if len(flags) != 0 {
+			scope = flags[0]
+		}
+		if _, ok := scopes[scope]; !ok {
+			scopes[scope] = map[string]*Stat{}
+			for _, language := range languages {
+				scopes[scope][language] = &Stat{Language: language, Scope: scope}
+			}
+		}
+
+		for _, language := range languages {
+			value, ok := message[language]
+			for _, item := range []*Stat{total[language], scopes[scope][language]} {
+				item.Total++
+				if !ok {
+					item.Missing++
+					continue
+				}
+				item.Translated++
+				item.Words += len(strings.Fields(value))
+				item.Characters += utf8.RuneCountInString(value)
+			}
+		}
+	})
+
+	res := &Report{Languages: languages, Total: []Stat{}, Scopes: []Stat{}}
+	for _, language := range languages {
+		res.Total = append(res.Total, total[language].complete())
+	}
+
+	scopeNames := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		scopeNames = append(scopeNames, scope)
+	}
+	sort.Strings(scopeNames)
+	for _, scope := range scopeNames {
+		for _, language := range languages {
+			res.Scopes = append(res.Scopes, scopes[scope][language].complete())
+		}
+	}
+
+	return res
+}
+
+// Language return the Stat of whole instance of specify language.
+func (r *Report) Language(language string) (Stat, bool) {
+	for _, item := range r.Total {
+		if item.Language == language {
+			return item, true
+		}
+	}
+	return Stat{}, false
+}
+
+// Write the Report to w in specify format, the format is one of FormatTable, FormatJSON and FormatMarkdown.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatTable:
+		return r.WriteTable(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatMarkdown:
+		return r.WriteMarkdown(w)
+	}
+	return fmt.Errorf("unknown format: %s", format)
+}
+
+// WriteJSON write the Report to w as indented json.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteTable write the Report to w as a plain text table.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range r.rows() {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteMarkdown write the Report to w as a markdown table.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	for index, row := range r.rows() {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+		if index == 0 {
+			separators := make([]string, len(row))
+			for column := range separators {
+				separators[column] = "---"
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rows return the table rows of the Report, the first row is the header. The Total rows use '*' as the scope.
+func (r *Report) rows() [][]string {
+	res := [][]string{{"SCOPE", "LANGUAGE", "TOTAL", "TRANSLATED", "MISSING", "PERCENTAGE", "WORDS", "CHARACTERS"}}
+	for _, item := range r.Total {
+		res = append(res, item.row("*"))
+	}
+	for _, item := range r.Scopes {
+		res = append(res, item.row(item.Scope))
+	}
+	return res
+}
+
+func (s Stat) row(scope string) []string {
+	return []string{
+		scope,
+		s.Language,
+		fmt.Sprint(s.Total),
+		fmt.Sprint(s.Translated),
+		fmt.Sprint(s.Missing),
+		fmt.Sprintf("%.2f%%", s.Percentage),
+		fmt.Sprint(s.Words),
+		fmt.Sprint(s.Characters),
+	}
+}
+
+func (s *Stat) complete() Stat {
+	s.Percentage = 100
+	if s.Total != 0 {
+		s.Percentage = float64(s.Translated) * 100 / float64(s.Total)
+	}
+	return *s
+}
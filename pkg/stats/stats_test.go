@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"bytes"
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.Pusher("system", "text", "error")(provider.EnglishLn, "error occur")
+	instance.Pusher("system", "text", "error")(provider.ChineseLn, "错误")
+	instance.Pusher("system", "error", "unknown")(provider.EnglishLn, "Unknown error")
+	instance.Pusher("user", "text", "test")(provider.EnglishLn, "test")
+
+	report := Compute(instance)
+	if strings.Join(report.Languages, ",") != "en,zh" {
+		t.Fatalf("Get languages: %v, want: [en zh]", report.Languages)
+	}
+
+	zh, ok := report.Language("zh")
+	if !ok || zh.Total != 3 || zh.Translated != 1 || zh.Missing != 2 || zh.Characters != 2 {
+		t.Errorf("Get zh stat: %+v", zh)
+	}
+	en, _ := report.Language("en")
+	if en.Percentage != 100 || en.Words != 5 {
+		t.Errorf("Get en stat: %+v", en)
+	}
+	if len(report.Scopes) != 4 || report.Scopes[1].Scope != "system" || report.Scopes[1].Translated != 1 {
+		t.Errorf("Get scope stats: %+v", report.Scopes)
+	}
+
+	if duplicate := Compute(instance, "zh", "zh"); len(duplicate.Languages) != 1 || duplicate.Total[0] != zh {
+		t.Errorf("Get the stats of duplicate languages: %+v, want the zh stat once", duplicate)
+	}
+
+	for _, format := range []string{FormatTable, FormatJSON, FormatMarkdown} {
+		buffer := &bytes.Buffer{}
+		if err := report.Write(buffer, format); err != nil || buffer.Len() == 0 {
+			t.Errorf("Write %s format, get err: %v", format, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(`\{[^{}\s]*\}`)
+	verbRegexp        = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*(?:\d+|\*)?(?:\.(?:\d+|\*))?[vTtbcdoOqxXUeEfFgGsp%]`)
+	tagRegexp         = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^<>]*?(/?)>`)
+
+	// fullWidthPunctuation maps the punctuation of CJK languages to the ascii punctuation, so '。' is same as '.'.
+	fullWidthPunctuation = map[rune]rune{
+		'。': '.', '．': '.', '！': '!', '？': '?', '：': ':', '；': ';', '，': ',', '、': ',', '…': '.',
+	}
+)
+
+// DefaultChecks return all built-in checks: placeholder, verb, markup, whitespace and punctuation.
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: "placeholder", Severity: SeverityError, Func: CheckPlaceholders},
+		{Name: "verb", Severity: SeverityError, Func: CheckVerbs},
+		{Name: "markup", Severity: SeverityError, Func: CheckMarkup},
+		{Name: "whitespace", Severity: SeverityWarning, Func: CheckWhitespace},
+		{Name: "punctuation", Severity: SeverityWarning, Func: CheckPunctuation},
+	}
+}
+
+// CheckPlaceholders checks the target has the same set of placeholders like '{name}' as the source.
+func CheckPlaceholders(source, target string) []string {
+	sourcePlaceholders := placeholderRegexp.FindAllString(source, -1)
+	return compareSets("placeholder", sourcePlaceholders, placeholderRegexp.FindAllString(target, -1))
+}
+
+// CheckVerbs checks the target has the same printf verbs like '%s' and '%d' as the source in same order. The indexed
+// verbs like '%[1]s' can be reordered.
+func CheckVerbs(source, target string) []string {
+	sourceVerbs, targetVerbs := verbs(source), verbs(target)
+	if strings.Join(sourceVerbs, " ") == strings.Join(targetVerbs, " ") {
+		return nil
+	}
+	if problems := compareSets("verb", sourceVerbs, targetVerbs); len(problems) != 0 {
+		return problems
+	}
+	for _, verb := range sourceVerbs {
+		if !strings.HasPrefix(verb, "%[") {
+			return []string{fmt.Sprintf("verbs order changed, want: %s, got: %s",
+				strings.Join(sourceVerbs, " "), strings.Join(targetVerbs, " "))}
+		}
+	}
+	return nil
+}
+
+// CheckMarkup checks the tags of target are balanced, and the target has the same tags as the source.
+func CheckMarkup(source, target string) []string {
+	res := balance(target)
+	return append(res, compareSets("tag", tags(source), tags(target))...)
+}
+
+// CheckWhitespace checks the target has the same leading and trailing whitespace as the source.
+func CheckWhitespace(source, target string) []string {
+	var res []string
+	if leading(source) != leading(target) {
+		res = append(res, fmt.Sprintf("leading whitespace %q, want %q", leading(target), leading(source)))
+	}
+	if trailing(source) != trailing(target) {
+		res = append(res, fmt.Sprintf("trailing whitespace %q, want %q", trailing(target), trailing(source)))
+	}
+	return res
+}
+
+// CheckPunctuation checks the target ends with the same punctuation as the source, the full-width punctuation of CJK
+// languages is same as the ascii punctuation.
+func CheckPunctuation(source, target string) []string {
+	sourcePunctuation, targetPunctuation := lastPunctuation(source), lastPunctuation(target)
+	if sourcePunctuation == targetPunctuation {
+		return nil
+	}
+	if sourcePunctuation == 0 {
+		return []string{fmt.Sprintf("unexpected trailing punctuation %q", targetPunctuation)}
+	}
+	if targetPunctuation == 0 {
+		return []string{fmt.Sprintf("missing trailing punctuation %q", sourcePunctuation)}
+	}
+	return []string{fmt.Sprintf("trailing punctuation %q, want %q", targetPunctuation, sourcePunctuation)}
+}
+
+// compareSets return the problems when the source items and the target items are not the same multiset.
+func compareSets(name string, source, target []string) []string {
+	counts := map[string]int{}
+	for _, item := range source {
+		counts[item]++
+	}
+	for _, item := range target {
+		counts[item]--
+	}
+
+	var res []string
+	for item, count := range counts {
+		switch {
+		case count > 0:
+			res = append(res, fmt.Sprintf("missing %s %s", name, item))
+		case count < 0:
+			res = append(res, fmt.Sprintf("unexpected %s %s", name, item))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func verbs(value string) []string {
+	var res []string
+	for _, verb := range verbRegexp.FindAllString(value, -1) {
+		if verb != "%%" {
+			res = append(res, verb)
+		}
+	}
+	return res
+}
+
+// tags return the names of all tags, the closing tags are prefixed with '/', and the self-closing tags are suffixed
+// with '/'.
+func tags(value string) []string {
+	var res []string
+	for _, match := range tagRegexp.FindAllStringSubmatch(value, -1) {
+		res = append(res, "<"+match[1]+strings.ToLower(match[2])+match[3]+">")
+	}
+	return res
+}
+
+// balance return the problems when the tags of the value are not balanced.
+func balance(value string) []string {
+	var res []string
+	var stack []string
+	for _, match := range tagRegexp.FindAllStringSubmatch(value, -1) {
+		name := strings.ToLower(match[2])
+		switch {
+		case len(match[3]) != 0:
+			// self-closing tag
+		case len(match[1]) == 0:
+			stack = append(stack, name)
+		case len(stack) != 0 && stack[len(stack)-1] == name:
+			stack = stack[:len(stack)-1]
+		default:
+			res = append(res, fmt.Sprintf("unexpected closing tag </%s>", name))
+		}
+	}
+	for _, name := range stack {
+		res = append(res, fmt.Sprintf("unclosed tag <%s>", name))
+	}
+	return res
+}
+
+func leading(value string) string {
+	return value[:len(value)-len(strings.TrimLeftFunc(value, unicode.IsSpace))]
+}
+
+func trailing(value string) string {
+	return value[len(strings.TrimRightFunc(value, unicode.IsSpace)):]
+}
+
+// lastPunctuation return the normalized trailing punctuation of the value, return 0 if the value not ends with a
+// punctuation.
+func lastPunctuation(value string) rune {
+	runes := []rune(strings.TrimRightFunc(value, unicode.IsSpace))
+	if len(runes) == 0 {
+		return 0
+	}
+	last := runes[len(runes)-1]
+	if normalized, ok := fullWidthPunctuation[last]; ok {
+		return normalized
+	}
+	if strings.ContainsRune(".!?:;,", last) {
+		return last
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/uberate/i18n/pkg/provider"
+	"sort"
+	"strings"
+)
+
+// Severity is the level of a Finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+var severityLevels = map[Severity]int{
+	SeverityInfo:    0,
+	SeverityWarning: 1,
+	SeverityError:   2,
+}
+
+// Valid return true when current Severity is one of SeverityError, SeverityWarning and SeverityInfo.
+func (severity Severity) Valid() bool {
+	_, ok := severityLevels[severity]
+	return ok
+}
+
+// AtLeast return true when current Severity is not lower than s.
+func (severity Severity) AtLeast(s Severity) bool {
+	return severityLevels[severity] >= severityLevels[s]
+}
+
+// Finding is a problem of one language value.
+type Finding struct {
+	Scopes   []string `yaml:"scopes" json:"scopes"`
+	Language string   `yaml:"language" json:"language"`
+	Check    string   `yaml:"check" json:"check"`
+	Severity Severity `yaml:"severity" json:"severity"`
+	Message  string   `yaml:"message" json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s [%s] %s: %s", f.Severity, strings.Join(f.Scopes, "."), f.Language, f.Check, f.Message)
+}
+
+// Check validates a language value against the value of the source language. It returns the problem messages, an
+// empty result means the value is fine.
+type Check struct {
+	Name     string
+	Severity Severity
+	Func     func(source, target string) []string
+}
+
+// Validator validates every language value of an I18n instance against the value of the Source language. The value
+// without source value will not be checked by Checks.
+//
+// If Duplicates is true, the Validator also reports the same value used by different scopes in one language.
+type Validator struct {
+	Source     string
+	Checks     []Check
+	Duplicates bool
+}
+
+// New return a *Validator with DefaultChecks, and duplicate values will be reported. The source is the language string
+// value, like 'en'.
+func New(source string) *Validator {
+	return &Validator{
+		Source:     source,
+		Checks:     DefaultChecks(),
+		Duplicates: true,
+	}
+}
+
+// Validate return all findings of the instance, sorted by scopes, language and check.
+func Validate(i *provider.I18n, source string) []Finding {
+	return New(source).Validate(i)
+}
+
+// Validate return all findings of the instance, sorted by scopes, language and check.
+func (v *Validator) Validate(i *provider.I18n) []Finding {
+	res := []Finding{}
+	duplicates := map[string]map[string][][]string{}
+
+	i.WalkMessage(func(message map[string]string, flags ...string) {
+		scopes := append([]string{}, flags...)
+		source, hasSource := message[v.Source]
+		for language, value := range message {
+			if v.Duplicates {
+				if _, ok := duplicates[language]; !ok {
+					duplicates[language] = map[string][][]string{}
+				}
+				duplicates[language][value] = append(duplicates[language][value], scopes)
+			}
+
+			if language == v.Source || !hasSource {
+				continue
+			}
+			for _, check := range v.Checks {
+				for _, problem := range check.Func(source, value) {
+					res = append(res, Finding{
+						Scopes:   scopes,
+						Language: language,
+						Check:    check.Name,
+						Severity: check.Severity,
+						Message:  problem,
+					})
+				}
+			}
+		}
+	})
+
+	for language, values := range duplicates {
+		for value, scopesList := range values {
+			if len(scopesList) < 2 {
+				continue
+			}
+			for _, scopes := range scopesList {
+				var others []string
+				for _, other := range scopesList {
+					if strings.Join(other, ".") != strings.Join(scopes, ".") {
+						others = append(others, strings.Join(other, "."))
+					}
+				}
+				sort.Strings(others)
+				res = append(res, Finding{
+					Scopes:   scopes,
+					Language: language,
+					Check:    "duplicate",
+					Severity: SeverityInfo,
+					Message:  fmt.Sprintf("value %q is also used by: %s", value, strings.Join(others, ", ")),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(a, b int) bool {
+		aScopes, bScopes := strings.Join(res[a].Scopes, "\x00"), strings.Join(res[b].Scopes, "\x00")
+		if aScopes != bScopes {
+			return aScopes < bScopes
+		}
+		if res[a].Language != res[b].Language {
+			return res[a].Language < res[b].Language
+		}
+		return res[a].Check < res[b].Check
+	})
+	return res
+}
+
+// HasSeverity return true when any finding is not lower than specify Severity.
+func HasSeverity(findings []Finding, severity Severity) bool {
+	for _, finding := range findings {
+		if finding.Severity.AtLeast(severity) {
+			return true
+		}
+	}
+	return false
+}
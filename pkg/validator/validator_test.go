@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"testing"
+)
+
+func TestChecks(t *testing.T) {
+	cases := []struct {
+		check  func(source, target string) []string
+		source string
+		target string
+		want   int
+	}{
+		{CheckPlaceholders, "Hello {name}", "你好 {name}", 0},
+		{CheckPlaceholders, "Hello {name}", "你好", 1},
+		{CheckPlaceholders, "Hello {name}", "你好 {user}", 2},
+		{CheckVerbs, "%s has %d items, 100%%", "%s 有 %d 个", 0},
+		{CheckVerbs, "%s has %d items", "%d 个 %s", 1},
+		{CheckVerbs, "%[1]s has %[2]d items", "%[2]d 个 %[1]s", 0},
+		{CheckVerbs, "%s has %d items", "%s 有", 1},
+		{CheckMarkup, "<b>Hello</b> world<br/>", "<b>你好</b>世界<br/>", 0},
+		{CheckMarkup, "<b>Hello</b>", "<b>你好", 2},
+		{CheckMarkup, "<b>Hello</b>", "<i>你好</i>", 4},
+		{CheckWhitespace, " Hello ", " 你好 ", 0},
+		{CheckWhitespace, "Hello", "你好 ", 1},
+		{CheckPunctuation, "Hello.", "你好。", 0},
+		{CheckPunctuation, "Hello?", "你好", 1},
+		{CheckPunctuation, "Hello", "你好!", 1},
+	}
+	for index, item := range cases {
+		if problems := item.check(item.source, item.target); len(problems) != item.want {
+			t.Errorf("Case %d get problems: %v, want %d problems", index, problems, item.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	instance.Pusher("user", "welcome")(provider.EnglishLn, "Welcome {name}.")
+	instance.Pusher("user", "welcome")(provider.ChineseLn, "欢迎")
+	instance.Pusher("user", "title")(provider.EnglishLn, "Welcome")
+	instance.Pusher("user", "title")(provider.ChineseLn, "欢迎")
+	instance.Pusher("user", "login")(provider.ChineseLn, "<b>登录")
+
+	findings := Validate(instance, "en")
+	if len(findings) != 4 {
+		t.Fatalf("Get findings: %v, want 4 findings", findings)
+	}
+	if !HasSeverity(findings, SeverityError) {
+		t.Error("Missing placeholder should be an error. But not.")
+	}
+	want := []string{"duplicate", "duplicate", "placeholder", "punctuation"}
+	for index, finding := range findings {
+		if finding.Check != want[index] {
+			t.Errorf("Get finding: %s, want check: %s", finding, want[index])
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	if !SeverityError.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) {
+		t.Error("Get wrong order of the severities")
+	}
+	for _, severity := range []Severity{"errors", "warn", ""} {
+		if severity.Valid() {
+			t.Errorf("Severity [%s] should be invalid. But valid.", severity)
+		}
+	}
+	if !SeverityInfo.Valid() {
+		t.Error("SeverityInfo should be valid. But not.")
+	}
+}
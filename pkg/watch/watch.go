@@ -0,0 +1,224 @@
+// Package watch fans out the changes of an I18n instance to many subscribers. The Hub keeps the recent events, so a
+// subscriber can resume from the last revision it has seen after a reconnect.
+//
+// The revisions are only comparable in one epoch of the Hub: the revision of the instance restarts when the process
+// restarts, so a subscriber resumes by the epoch and the revision, see Hub.Subscribe.
+package watch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/uberate/i18n/pkg/provider"
+	"strings"
+	"sync"
+)
+
+// EventType is the type of Event.
+type EventType string
+
+const (
+	EventUpsert EventType = "upsert" // The value is created or updated.
+	EventDelete EventType = "delete" // The value is deleted.
+)
+
+// ErrResumeTooOld is returned by Hub.Subscribe when the events after the revision are not kept anymore, or the
+// revision is from another epoch, the subscriber should reload the whole catalog and subscribe again from the current
+// revision.
+var ErrResumeTooOld = errors.New("the events after the revision are not available")
+
+// Event is a change of a value. The Value is empty for EventDelete.
+type Event struct {
+	Type     EventType `json:"type" yaml:"type"`
+	Scopes   []string  `json:"scopes" yaml:"scopes"`
+	Language string    `json:"language" yaml:"language"`
+	Value    string    `json:"value,omitempty" yaml:"value,omitempty"`
+	Revision uint64    `json:"revision" yaml:"revision"`
+}
+
+// FromChange return the Event of a provider.ChangeEvent.
+func FromChange(change provider.ChangeEvent) Event {
+	res := Event{
+		Type:     EventUpsert,
+		Scopes:   change.Scopes,
+		Language: change.Language,
+		Value:    change.NewValue,
+		Revision: change.Revision,
+	}
+	if change.Type == provider.ChangeRemoved {
+		res.Type = EventDelete
+	}
+	return res
+}
+
+// Filter selects the events of a subscriber, the zero value of a field means no filter.
+type Filter struct {
+	// Scopes is the scope prefix.
+	Scopes   []string
+	Language string
+}
+
+// Match return true if the event matches the Filter.
+func (f Filter) Match(event Event) bool {
+	if len(f.Language) != 0 && !strings.EqualFold(f.Language, event.Language) {
+		return false
+	}
+	if len(event.Scopes) < len(f.Scopes) {
+		return false
+	}
+	for index, scope := range f.Scopes {
+		if event.Scopes[index] != scope {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscription receives the events of a Hub. The Events channel is closed when the Subscription is canceled, or the
+// subscriber is too slow to receive the events, in the later case Overflowed return true and the subscriber should
+// resume from the last revision it has seen.
+type Subscription struct {
+	Events <-chan Event
+
+	hub        *Hub
+	events     chan Event
+	filter     Filter
+	overflowed bool
+}
+
+// Overflowed return true if the Subscription is closed because the subscriber is too slow.
+func (s *Subscription) Overflowed() bool {
+	s.hub.lock.Lock()
+	defer s.hub.lock.Unlock()
+	return s.overflowed
+}
+
+// Cancel stop the Subscription and close the Events channel.
+func (s *Subscription) Cancel() {
+	s.hub.lock.Lock()
+	defer s.hub.lock.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub subscribes the changes of an I18n instance, and fans them out to the subscriptions. The Hub is thread-safe.
+type Hub struct {
+	epoch         string
+	lock          sync.Mutex
+	revision      uint64
+	history       []Event
+	size          int
+	bufferSize    int
+	subscriptions map[*Subscription]struct{}
+	cancel        func()
+}
+
+// NewHub return a *Hub of the instance with a new random epoch. The size is the count of recent events kept for
+// resuming, and every subscription can hold bufferSize events which are not received.
+func NewHub(instance *provider.I18n, size, bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	h := &Hub{
+		epoch:         newEpoch(),
+		revision:      instance.Revision(),
+		size:          size,
+		bufferSize:    bufferSize,
+		subscriptions: map[*Subscription]struct{}{},
+	}
+	h.cancel = instance.Subscribe(h.publish)
+	return h
+}
+
+// Subscribe return a Subscription of the events which match the filter. If since is not 0, the kept events after the
+// revision since are returned as the replay, so the subscriber will not miss any change between the replay and the
+// Events. The epoch is the Epoch of the Hub which the since is from. If the epoch is not the Epoch of the Hub (like the
+// server restarted), the events after since are not kept, or since is newer than the Hub, it returns ErrResumeTooOld.
+func (h *Hub) Subscribe(filter Filter, epoch string, since uint64) (*Subscription, []Event, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var replay []Event
+	if since != 0 {
+		if epoch != h.epoch || since > h.revision {
+			return nil, nil, ErrResumeTooOld
+		}
+		if since < h.revision && (len(h.history) == 0 || h.history[0].Revision > since+1) {
+			return nil, nil, ErrResumeTooOld
+		}
+		for _, event := range h.history {
+			if event.Revision > since && filter.Match(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	events := make(chan Event, h.bufferSize)
+	subscription := &Subscription{Events: events, hub: h, events: events, filter: filter}
+	h.subscriptions[subscription] = struct{}{}
+	return subscription, replay, nil
+}
+
+// Epoch return the random id of the Hub, the revisions of the Hub are only comparable in the same epoch.
+func (h *Hub) Epoch() string {
+	return h.epoch
+}
+
+// Revision return the revision of the last event.
+func (h *Hub) Revision() uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.revision
+}
+
+// Close stop the Hub and cancel all subscriptions.
+func (h *Hub) Close() {
+	h.cancel()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for subscription := range h.subscriptions {
+		h.remove(subscription)
+	}
+}
+
+func (h *Hub) publish(change provider.ChangeEvent) {
+	event := FromChange(change)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.revision = event.Revision
+	if h.size > 0 {
+		h.history = append(h.history, event)
+		if len(h.history) > h.size {
+			h.history = append(h.history[:0:0], h.history[len(h.history)-h.size:]...)
+		}
+	}
+
+	for subscription := range h.subscriptions {
+		if !subscription.filter.Match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.overflowed = true
+			h.remove(subscription)
+		}
+	}
+}
+
+// remove should be invoked with the lock.
+func (h *Hub) remove(subscription *Subscription) {
+	if _, ok := h.subscriptions[subscription]; !ok {
+		return
+	}
+	delete(h.subscriptions, subscription)
+	close(subscription.events)
+}
+
+func newEpoch() string {
+	buffer := make([]byte, 8)
+	_, _ = rand.Read(buffer)
+	return hex.EncodeToString(buffer)
+}
@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"github.com/uberate/i18n/pkg/provider"
+	"testing"
+)
+
+func TestHub(t *testing.T) {
+	instance := provider.NewI18n(provider.ISO6391)
+	hub := NewHub(instance, 2, 1)
+	defer hub.Close()
+
+	subscription, replay, err := hub.Subscribe(Filter{Scopes: []string{"user"}, Language: "ja"}, hub.Epoch(), 0)
+	if err != nil || len(replay) != 0 {
+		t.Fatalf("Subscribe get: %v, %v", replay, err)
+	}
+
+	instance.PushMessage(provider.EnglishLn, "Login", "user", "login")
+	instance.PushMessage(provider.JapaneseLn, "ログイン", "user", "login")
+	event := <-subscription.Events
+	if event.Type != EventUpsert || event.Value != "ログイン" || event.Revision != 2 {
+		t.Errorf("Get event: %+v, want the japanese upsert", event)
+	}
+
+	// the buffer is 1, the second event overflows the subscription.
+	instance.PushMessage(provider.JapaneseLn, "サインイン", "user", "login")
+	instance.PushMessage(provider.JapaneseLn, "", "user", "login")
+	<-subscription.Events
+	if _, ok := <-subscription.Events; ok || !subscription.Overflowed() {
+		t.Error("The slow subscription should be closed as overflowed")
+	}
+
+	// resume from revision 2, the history keeps revision 3 and 4.
+	resumed, replay, err := hub.Subscribe(Filter{}, hub.Epoch(), 2)
+	if err != nil || len(replay) != 2 || replay[1].Type != EventDelete || replay[1].Revision != 4 {
+		t.Errorf("Resume get: %v, %v", replay, err)
+	}
+	resumed.Cancel()
+
+	if _, _, err = hub.Subscribe(Filter{}, hub.Epoch(), 1); err != ErrResumeTooOld {
+		t.Errorf("Resume from lost revision get: %v, want: %v", err, ErrResumeTooOld)
+	}
+	if _, _, err = hub.Subscribe(Filter{}, hub.Epoch(), 100); err != ErrResumeTooOld {
+		t.Errorf("Resume from future revision get: %v, want: %v", err, ErrResumeTooOld)
+	}
+
+	// the same revision of another hub, like the server before a restart.
+	restarted := NewHub(instance, 2, 1)
+	defer restarted.Close()
+	if restarted.Epoch() == hub.Epoch() {
+		t.Error("The hubs should have different epochs")
+	}
+	if _, _, err = restarted.Subscribe(Filter{}, hub.Epoch(), 3); err != ErrResumeTooOld {
+		t.Errorf("Resume from another epoch get: %v, want: %v", err, ErrResumeTooOld)
+	}
+}